@@ -0,0 +1,226 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// An AggregateFunc summarizes the fields of a column within a group.
+type AggregateFunc int
+
+const (
+	AggCount AggregateFunc = iota // number of non-empty fields, or of records without a column
+	AggSum                        // sum of the fields
+	AggMin                        // smallest field
+	AggMax                        // largest field
+	AggAvg                        // mean of the fields
+)
+
+var aggregateNames = []string{"count", "sum", "min", "max", "avg"}
+
+// String returns the name of the function.
+func (f AggregateFunc) String() string {
+	if f >= 0 && int(f) < len(aggregateNames) {
+		return aggregateNames[f]
+	}
+	return fmt.Sprintf("AggregateFunc(%d)", int(f))
+}
+
+// An Aggregation is a summarized column of the output of an Aggregator.
+type Aggregation struct {
+	Func   AggregateFunc
+	Column string // column summarized; empty to count records with AggCount
+	Name   string // header of the output column; "func(column)" if empty
+}
+
+// name returns the output header of the aggregation.
+func (a Aggregation) name() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	if a.Column == "" {
+		return a.Func.String()
+	}
+	return a.Func.String() + "(" + a.Column + ")"
+}
+
+// An Aggregator groups records by the fields of the GroupBy columns and
+// summarizes each group with the Aggregations.  Only the running totals of
+// each group are held in memory.
+type Aggregator struct {
+	GroupBy      []string      // columns grouping the records; one group if empty
+	Aggregations []Aggregation // output columns following the GroupBy ones
+}
+
+// aggregate is the running total of an aggregation for a group.
+type aggregate struct {
+	count    int
+	sum      float64
+	min, max float64
+}
+
+// group is a group of records.
+type group struct {
+	key        []string
+	aggregates []aggregate
+}
+
+// Aggregate reads the header row and the records of r, which must be at its
+// start, and writes to w a header row and a record for each group, in the
+// order the groups are first seen.  Empty fields are ignored; other fields
+// that are not numbers are a *FieldError wrapping ErrType for every function
+// but AggCount.  Columns missing from the header row are an error wrapping
+// ErrUnknownColumn.  Records with errors are skipped if r has SkipLineOnErr
+// set.
+func (a *Aggregator) Aggregate(r *Reader, w *Writer) error {
+	headers, err := r.readNew()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	index := func(name string) (int, error) {
+		if i := slices.Index(headers, name); i >= 0 {
+			return i, nil
+		}
+		return 0, fmt.Errorf("%w %q", ErrUnknownColumn, name)
+	}
+	groupBy := make([]int, len(a.GroupBy))
+	for i, name := range a.GroupBy {
+		if groupBy[i], err = index(name); err != nil {
+			return err
+		}
+	}
+	columns := make([]int, len(a.Aggregations))
+	for i, agg := range a.Aggregations {
+		columns[i] = -1
+		if agg.Column != "" {
+			if columns[i], err = index(agg.Column); err != nil {
+				return err
+			}
+		}
+	}
+
+	var groups []*group
+	byKey := make(map[string]*group)
+	for {
+		record, err := r.readNew()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = a.add(record, groupBy, columns, &groups, byKey)
+			if ferr, ok := err.(*FieldError); ok {
+				ferr.Line = r.recordLine
+			}
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return err
+		}
+	}
+
+	out := slices.Clone(a.GroupBy)
+	for _, agg := range a.Aggregations {
+		out = append(out, agg.name())
+	}
+	if err := w.writeHeader(out); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		record := slices.Clone(g.key)
+		for i, agg := range a.Aggregations {
+			record = append(record, g.aggregates[i].result(agg.Func))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// add adds record to its group.  Groups are only created for records
+// without errors.
+func (a *Aggregator) add(record []string, groupBy, columns []int, groups *[]*group, byKey map[string]*group) error {
+	field := func(i int) string {
+		if i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	values := make([]float64, len(columns))
+	for i, c := range columns {
+		if c < 0 || a.Aggregations[i].Func == AggCount || field(c) == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(field(c), 64)
+		if err != nil {
+			return &FieldError{Column: c, Name: a.Aggregations[i].Column, Err: fmt.Errorf("%w %s: %q", ErrType, TypeFloat, field(c))}
+		}
+		values[i] = f
+	}
+
+	key := make([]string, len(groupBy))
+	for i, c := range groupBy {
+		key[i] = field(c)
+	}
+	k := dedupeKey(key, nil)
+	g := byKey[k]
+	if g == nil {
+		g = &group{key: key, aggregates: make([]aggregate, len(columns))}
+		byKey[k] = g
+		*groups = append(*groups, g)
+	}
+	for i, c := range columns {
+		if c >= 0 && field(c) == "" {
+			continue
+		}
+		g.aggregates[i].add(values[i])
+	}
+	return nil
+}
+
+// add adds a value to the running total.
+func (t *aggregate) add(f float64) {
+	if t.count == 0 || f < t.min {
+		t.min = f
+	}
+	if t.count == 0 || f > t.max {
+		t.max = f
+	}
+	t.count++
+	t.sum += f
+}
+
+// result returns the field of the running total for fn.  Functions other
+// than AggCount are empty without values.
+func (t *aggregate) result(fn AggregateFunc) string {
+	if fn == AggCount {
+		return strconv.Itoa(t.count)
+	}
+	if t.count == 0 {
+		return ""
+	}
+	var f float64
+	switch fn {
+	case AggSum:
+		f = t.sum
+	case AggMin:
+		f = t.min
+	case AggMax:
+		f = t.max
+	case AggAvg:
+		f = t.sum / float64(t.count)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,77 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const aggregateInput = `country,city,amount
+FR,Paris,10
+US,Austin,2.5
+FR,Lyon,
+FR,Paris,4
+US,Austin,7.5
+`
+
+func TestAggregator(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Aggregator Aggregator
+		Output     string
+	}{
+		{
+			Name: "ByCountry",
+			Aggregator: Aggregator{
+				GroupBy: []string{"country"},
+				Aggregations: []Aggregation{
+					{Func: AggCount},
+					{Func: AggCount, Column: "amount"},
+					{Func: AggSum, Column: "amount", Name: "total"},
+					{Func: AggMin, Column: "amount"},
+					{Func: AggMax, Column: "amount"},
+					{Func: AggAvg, Column: "amount"},
+				},
+			},
+			Output: "country,count,count(amount),total,min(amount),max(amount),avg(amount)\nFR,3,2,14,4,10,7\nUS,2,2,10,2.5,7.5,5\n",
+		},
+		{
+			Name: "ByCountryAndCity",
+			Aggregator: Aggregator{
+				GroupBy:      []string{"country", "city"},
+				Aggregations: []Aggregation{{Func: AggSum, Column: "amount"}},
+			},
+			Output: "country,city,sum(amount)\nFR,Paris,14\nUS,Austin,10\nFR,Lyon,\"\"\n",
+		},
+		{
+			Name:       "Total",
+			Aggregator: Aggregator{Aggregations: []Aggregation{{Func: AggSum, Column: "amount"}}},
+			Output:     "sum(amount)\n24\n",
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := tt.Aggregator.Aggregate(NewReader(strings.NewReader(aggregateInput)), NewWriter(&b)); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.Name, err)
+		}
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+	}
+
+	a := &Aggregator{Aggregations: []Aggregation{{Func: AggSum, Column: "amount"}}}
+	err := a.Aggregate(NewReader(strings.NewReader("amount\n1\nten\n")), NewWriter(&bytes.Buffer{}))
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Line != 3 || !errors.Is(err, ErrType) {
+		t.Errorf("error %v, want ErrType on line 3", err)
+	}
+	a.GroupBy = []string{"region"}
+	if err := a.Aggregate(NewReader(strings.NewReader(aggregateInput)), NewWriter(&bytes.Buffer{})); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("error %v, want ErrUnknownColumn", err)
+	}
+}
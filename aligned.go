@@ -0,0 +1,94 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// alignedTable holds the rows of a Writer returned by NewAlignedWriter until
+// they are flushed.
+type alignedTable struct {
+	maxWidth int        // widest column, in runes; unlimited if 0
+	rows     [][]string // rows since the last flush
+	header   int        // rows of the header, followed by a rule
+	widths   []int      // widths of the columns so far
+}
+
+// alignedEscaper makes the fields of aligned rows fit on a line.
+var alignedEscaper = strings.NewReplacer("\r", `\r`, "\n", `\n`, "\t", `\t`)
+
+// NewAlignedWriter returns a new Writer that writes records as plain text
+// columns aligned with spaces, like the output of column -t, for terminal
+// previews.  Fields wider than maxWidth runes are truncated and end with
+// "…", unless maxWidth is 0, and newlines and tabs are written as \n and \t.
+// Header rows, such as those written by AutoHeader, are underlined.
+//
+// Rows are buffered to measure the columns until Flush, which aligns them
+// with the rows flushed before, unless they are wider.  WriteComment
+// returns ErrNoComment.
+func NewAlignedWriter(w io.Writer, maxWidth int) *Writer {
+	writer := NewWriter(w)
+	writer.aligned = &alignedTable{maxWidth: maxWidth}
+	return writer
+}
+
+// writeAligned buffers record as a row of w.  Header rows are only
+// underlined before the first record.
+func (w *Writer) writeAligned(record []string, header bool) error {
+	if header && !w.started {
+		w.aligned.header++
+	}
+	w.started = true
+	row := make([]string, len(record))
+	for i, field := range record {
+		field = alignedEscaper.Replace(field)
+		if w.aligned.maxWidth > 0 && utf8.RuneCountInString(field) > w.aligned.maxWidth {
+			field = string([]rune(field)[:max(w.aligned.maxWidth-1, 0)]) + "…"
+		}
+		row[i] = field
+	}
+	w.aligned.rows = append(w.aligned.rows, row)
+	return nil
+}
+
+// flushAligned writes the rows buffered by w.
+func (w *Writer) flushAligned() {
+	a := w.aligned
+	for _, row := range a.rows {
+		for i, field := range row {
+			if i == len(a.widths) {
+				a.widths = append(a.widths, 0)
+			}
+			a.widths[i] = max(a.widths[i], utf8.RuneCountInString(field))
+		}
+	}
+	for n, row := range a.rows {
+		w.writeAlignedLine(len(row), func(i int) string { return row[i] })
+		if n == a.header-1 {
+			w.writeAlignedLine(len(row), func(i int) string { return strings.Repeat("-", a.widths[i]) })
+		}
+	}
+	a.rows = a.rows[:0]
+	a.header = 0
+}
+
+// writeAlignedLine writes a line of n fields, padded to the widths of their
+// columns, without trailing spaces.
+func (w *Writer) writeAlignedLine(n int, field func(i int) string) {
+	var line strings.Builder
+	for i := range n {
+		if i > 0 {
+			line.WriteString("  ")
+		}
+		f := field(i)
+		line.WriteString(f)
+		line.WriteString(strings.Repeat(" ", w.aligned.widths[i]-utf8.RuneCountInString(f)))
+	}
+	w.w.WriteString(strings.TrimRight(line.String(), " "))
+	w.writeEOL()
+}
@@ -0,0 +1,79 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"strings"
+	"testing"
+)
+
+var alignedWriterTests = []struct {
+	Name     string
+	MaxWidth int
+	Write    func(w *Writer) error
+	Output   string
+}{
+	{
+		Name: "Records",
+		Write: func(w *Writer) error {
+			return w.WriteAll([][]string{{"a", "bb", "c"}, {"dddd", "é", "f"}, {"g"}})
+		},
+		Output: "a     bb  c\n" +
+			"dddd  é   f\n" +
+			"g\n",
+	},
+	{
+		Name: "Headers",
+		Write: func(w *Writer) error {
+			w.Headers = []string{"name", "note"}
+			w.AutoHeader = true
+			w.WriteMap(map[string]string{"name": "ann", "note": "line\none\ttab"})
+			return w.WriteMap(map[string]string{"name": "bartholomew"})
+		},
+		Output: "name         note\n" +
+			"-----------  --------------\n" +
+			"ann          line\\none\\ttab\n" +
+			"bartholomew\n",
+	},
+	{
+		Name:     "MaxWidth",
+		MaxWidth: 5,
+		Write: func(w *Writer) error {
+			return w.WriteAll([][]string{{"abcdefgh", "x"}, {"abcde", "y"}})
+		},
+		Output: "abcd…  x\n" +
+			"abcde  y\n",
+	},
+	{
+		Name: "Flushes",
+		Write: func(w *Writer) error {
+			w.Write([]string{"abc", "x"})
+			w.Flush()
+			w.Write([]string{"a", "y"})
+			w.Write([]string{"abcdef", "z"})
+			return nil
+		},
+		Output: "abc  x\n" +
+			"a       y\n" +
+			"abcdef  z\n",
+	},
+}
+
+func TestAlignedWriter(t *testing.T) {
+	for _, tt := range alignedWriterTests {
+		var b strings.Builder
+		w := NewAlignedWriter(&b, tt.MaxWidth)
+		if err := tt.Write(w); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		}
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"os"
+)
+
+// NewAppendWriter returns a Writer that appends records to w, the end of the
+// CSV file whose content is read from r.  If the file has a header row, it
+// becomes the Headers of the Writer, so that WriteMap and WriteStruct follow
+// the column order of the file, and AutoHeader does not write it again.  An
+// empty file has no Headers.
+func NewAppendWriter(r io.Reader, w io.Writer) (*Writer, error) {
+	writer := NewWriter(w)
+	headers, err := NewReader(r).Headers()
+	if err == io.EOF {
+		return writer, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	writer.Headers = headers
+	writer.started = true
+	return writer, nil
+}
+
+// OpenAppend opens the named CSV file for appending, creating it if it does
+// not exist, and returns a Writer aligned to its header row as with
+// NewAppendWriter.  A newline is added to a file whose last record is not
+// terminated.  The caller must Flush the Writer and close the file.
+func OpenAppend(name string) (*Writer, *os.File, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err := appendTo(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return w, f, nil
+}
+
+// appendTo terminates the last record of f if needed and returns a Writer
+// appending to f.
+func appendTo(f *os.File) (*Writer, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, size-1); err != nil {
+			return nil, err
+		}
+		if last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return NewAppendWriter(io.NewSectionReader(f, 0, size), f)
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenAppend(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "monthly.csv")
+	batches := [][]map[string]string{
+		{{"id": "1", "name": "ann"}},
+		{{"name": "bob", "id": "2", "extra": "x"}, {"id": "3"}},
+	}
+	for i, batch := range batches {
+		w, f, err := OpenAppend(name)
+		if err != nil {
+			t.Fatalf("%d: unexpected error %v", i, err)
+		}
+		if w.Headers == nil {
+			w.Headers = []string{"id", "name"}
+		}
+		w.AutoHeader = true
+		if err := w.WriteAllMaps(batch); err != nil {
+			t.Fatalf("%d: unexpected error %v", i, err)
+		}
+		f.Close()
+	}
+
+	type person struct {
+		Name string `csv:"name"`
+		ID   int    `csv:"id"`
+	}
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("4,dan")
+	f.Close()
+	w, f, err := OpenAppend(name)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.AutoHeader = true
+	w.WriteStruct(person{Name: "eve", ID: 5})
+	w.Flush()
+	f.Close()
+
+	out, _ := os.ReadFile(name)
+	if want := "id,name\n1,ann\n2,bob\n3,\"\"\n4,dan\n5,eve\n"; string(out) != want {
+		t.Errorf("out=%q want %q", out, want)
+	}
+}
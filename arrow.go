@@ -0,0 +1,198 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// DefaultArrowBatchSize is the number of rows of the batches of an
+// ArrowReader whose BatchSize is 0.
+const DefaultArrowBatchSize = 64 << 10
+
+// ErrArrowTooLarge is returned by ArrowReader.Read for a row whose utf8
+// values do not fit the int32 offsets of a batch on their own.
+var ErrArrowTooLarge = errors.New("utf8 values too large for an Arrow batch")
+
+// maxArrowOffset is the largest offset of the utf8 values of a batch.
+var maxArrowOffset = math.MaxInt32
+
+// An ArrowColumn is a column of an ArrowBatch, whose buffers follow the
+// Apache Arrow columnar format, so that they can be handed to an Arrow
+// library without copying.  The Arrow type of the column depends on its
+// Type:
+//
+//	TypeInt     int64
+//	TypeFloat   float64
+//	TypeBool    bool
+//	TypeDate    timestamp[us, UTC]
+//	otherwise   utf8
+//
+// Validity is the validity bitmap of the column, with the bit of each
+// non-null row set, least significant bit first; it is nil if the column
+// has no nulls.  Offsets holds the little-endian int32 offsets of the
+// values of utf8 columns in Data.  Data holds the little-endian values of
+// fixed-width types, the bitmap of bool columns, and the bytes of the
+// values of utf8 columns.
+type ArrowColumn struct {
+	Name      string     // header of the column
+	Type      ColumnType // schema type of the column
+	NullCount int        // number of null rows
+	Validity  []byte     // validity bitmap; nil if NullCount is 0
+	Offsets   []byte     // int32 offsets of utf8 values
+	Data      []byte     // values
+}
+
+// An ArrowBatch is a record batch of Len rows in the Apache Arrow columnar
+// format, with a column for each column of the Schema it was read with.
+type ArrowBatch struct {
+	Len     int
+	Columns []*ArrowColumn
+}
+
+// An ArrowReader reads the records of a SchemaReader into ArrowBatches of
+// BatchSize rows, converting values once into columnar buffers rather than
+// into a map per record.  Records breaking a rule of the Schema are
+// skipped, and their violations added to Report.  A batch ends early when
+// the values of a utf8 column would not fit its int32 offsets.
+type ArrowReader struct {
+	BatchSize int               // rows of each batch; DefaultArrowBatchSize if 0
+	Report    *ValidationReport // violations of the records skipped
+
+	s       *SchemaReader
+	pending map[string]any // record read but left for the next batch
+}
+
+// NewArrowReader returns a new ArrowReader that reads from s.
+func NewArrowReader(s *SchemaReader) *ArrowReader {
+	return &ArrowReader{
+		Report: new(ValidationReport),
+		s:      s,
+	}
+}
+
+// Read reads the next batch of up to BatchSize rows.  At the end of the
+// input, Read returns io.EOF.
+func (a *ArrowReader) Read() (*ArrowBatch, error) {
+	batchSize := a.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultArrowBatchSize
+	}
+	batch := &ArrowBatch{Columns: make([]*ArrowColumn, len(a.s.Schema.Columns))}
+	for i, column := range a.s.Schema.Columns {
+		batch.Columns[i] = &ArrowColumn{Name: column.Name, Type: column.Type}
+		if batch.Columns[i].utf8() {
+			batch.Columns[i].Offsets = make([]byte, 4, 4*(batchSize+1))
+		}
+	}
+	for batch.Len < batchSize {
+		record, err := a.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !batch.fits(record) {
+			if batch.Len == 0 {
+				return nil, ErrArrowTooLarge
+			}
+			a.pending = record
+			break
+		}
+		for _, c := range batch.Columns {
+			c.append(batch.Len, record[c.Name])
+		}
+		batch.Len++
+	}
+	if batch.Len == 0 {
+		return nil, io.EOF
+	}
+	for _, c := range batch.Columns {
+		if c.NullCount == 0 {
+			c.Validity = nil
+		}
+	}
+	return batch, nil
+}
+
+// next returns the record left for the next batch, if any, or reads the
+// next record without violations.
+func (a *ArrowReader) next() (map[string]any, error) {
+	if record := a.pending; record != nil {
+		a.pending = nil
+		return record, nil
+	}
+	for {
+		record, violations, err := a.s.Read()
+		if err != nil {
+			return nil, err
+		}
+		if violations == nil {
+			return record, nil
+		}
+		a.Report.Add(violations...)
+	}
+}
+
+// fits reports whether the utf8 values of record can be added to b.
+func (b *ArrowBatch) fits(record map[string]any) bool {
+	for _, c := range b.Columns {
+		if s, ok := record[c.Name].(string); ok && c.utf8() && len(s) > maxArrowOffset-len(c.Data) {
+			return false
+		}
+	}
+	return true
+}
+
+// utf8 reports whether c is of the Arrow type utf8.
+func (c *ArrowColumn) utf8() bool {
+	switch c.Type {
+	case TypeInt, TypeFloat, TypeBool, TypeDate:
+		return false
+	}
+	return true
+}
+
+// append appends the converted value v to c as row i.
+func (c *ArrowColumn) append(i int, v any) {
+	if i%8 == 0 {
+		c.Validity = append(c.Validity, 0)
+	}
+	if v == nil {
+		c.NullCount++
+	} else {
+		c.Validity[i/8] |= 1 << (i % 8)
+	}
+	switch c.Type {
+	case TypeInt:
+		n, _ := v.(int64)
+		c.Data = binary.LittleEndian.AppendUint64(c.Data, uint64(n))
+	case TypeFloat:
+		f, _ := v.(float64)
+		c.Data = binary.LittleEndian.AppendUint64(c.Data, math.Float64bits(f))
+	case TypeBool:
+		if i%8 == 0 {
+			c.Data = append(c.Data, 0)
+		}
+		if b, _ := v.(bool); b {
+			c.Data[i/8] |= 1 << (i % 8)
+		}
+	case TypeDate:
+		var us int64
+		if t, ok := v.(time.Time); ok {
+			us = t.UnixMicro()
+		}
+		c.Data = binary.LittleEndian.AppendUint64(c.Data, uint64(us))
+	default:
+		s, _ := v.(string)
+		c.Data = append(c.Data, s...)
+		c.Offsets = binary.LittleEndian.AppendUint32(c.Offsets, uint32(len(c.Data)))
+	}
+}
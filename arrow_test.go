@@ -0,0 +1,102 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// le returns the little-endian bytes of values.
+func le(values ...uint64) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint64(b, v)
+	}
+	return b
+}
+
+func TestArrowReader(t *testing.T) {
+	input := `name,id,price,active,joined,extra
+ann,1,2.5,true,06/01/2014,x
+bob,x,,yes,06/02/2014,y
+cat,2,NULL,false,06/03/2014,z
+dan,3,,true,06/04/2014,w
+`
+	r := NewReader(strings.NewReader(input))
+	r.NullValues = []string{"NULL"}
+	a := NewArrowReader(NewSchemaReader(r, testSchema))
+	a.BatchSize = 2
+
+	day := func(d int) uint64 {
+		return uint64(time.Date(2014, 6, d, 0, 0, 0, 0, time.UTC).UnixMicro())
+	}
+	want := []*ArrowBatch{{
+		Len: 2,
+		Columns: []*ArrowColumn{
+			{Name: "id", Type: TypeInt, Data: le(1, 2)},
+			{Name: "price", Type: TypeFloat, NullCount: 1, Validity: []byte{0b01}, Data: le(math.Float64bits(2.5), 0)},
+			{Name: "active", Type: TypeBool, Data: []byte{0b01}},
+			{Name: "joined", Type: TypeDate, Data: le(day(1), day(3))},
+			{Name: "name", Type: TypeString, Offsets: []byte{0, 0, 0, 0, 3, 0, 0, 0, 6, 0, 0, 0}, Data: []byte("anncat")},
+			{Name: "note", Type: TypeString, NullCount: 2, Validity: []byte{0}, Offsets: make([]byte, 12)},
+		},
+	}, {
+		Len: 1,
+		Columns: []*ArrowColumn{
+			{Name: "id", Type: TypeInt, Data: le(3)},
+			{Name: "price", Type: TypeFloat, NullCount: 1, Validity: []byte{0}, Data: le(0)},
+			{Name: "active", Type: TypeBool, Data: []byte{0b1}},
+			{Name: "joined", Type: TypeDate, Data: le(day(4))},
+			{Name: "name", Type: TypeString, Offsets: []byte{0, 0, 0, 0, 3, 0, 0, 0}, Data: []byte("dan")},
+			{Name: "note", Type: TypeString, NullCount: 1, Validity: []byte{0}, Offsets: make([]byte, 8)},
+		},
+	}}
+	for i, wantBatch := range want {
+		batch, err := a.Read()
+		if err != nil {
+			t.Fatalf("batch %d: unexpected error %v", i, err)
+		}
+		if batch.Len != wantBatch.Len {
+			t.Errorf("batch %d: len=%d want %d", i, batch.Len, wantBatch.Len)
+		}
+		for j, c := range batch.Columns {
+			if !reflect.DeepEqual(c, wantBatch.Columns[j]) {
+				t.Errorf("batch %d: column=%+v want %+v", i, c, wantBatch.Columns[j])
+			}
+		}
+	}
+	if _, err := a.Read(); err != io.EOF {
+		t.Errorf("error %v, want %v", err, io.EOF)
+	}
+	if len(a.Report.Violations) != 2 || a.Report.Violations[0].Line != 3 {
+		t.Errorf("violations=%v", a.Report.Violations)
+	}
+}
+
+func TestArrowReaderOffsets(t *testing.T) {
+	defer func(max int) { maxArrowOffset = max }(maxArrowOffset)
+	maxArrowOffset = 6
+
+	schema := &Schema{Columns: []Column{{Name: "name", Type: TypeString}}}
+	a := NewArrowReader(NewSchemaReader(NewReader(strings.NewReader("name\nann\nbob\ncat\ntoo long\n")), schema))
+	for i, want := range []string{"annbob", "cat"} {
+		batch, err := a.Read()
+		if err != nil {
+			t.Fatalf("batch %d: unexpected error %v", i, err)
+		}
+		if got := string(batch.Columns[0].Data); got != want {
+			t.Errorf("batch %d: data=%q want %q", i, got, want)
+		}
+	}
+	if _, err := a.Read(); err != ErrArrowTooLarge {
+		t.Errorf("error %v, want ErrArrowTooLarge", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// asyncChunks is the number of chunks an asynchronous Writer queues before
+// Write blocks.
+const asyncChunks = 16
+
+// asyncWriter hands chunks of records to a goroutine writing them to w.
+type asyncWriter struct {
+	w      io.Writer
+	rows   int           // records per chunk
+	size   int           // bytes per chunk
+	n      int           // records in chunk
+	chunk  *bytes.Buffer // records not yet handed off
+	chunks chan *bytes.Buffer
+	done   chan struct{}
+	closed bool
+
+	mu  sync.Mutex
+	err error // first error writing to w
+}
+
+// NewAsyncWriter returns a new Writer that writes to w on a background
+// goroutine, so that Write does not block on slow disks or networks.  Records
+// are buffered and handed to the goroutine as soon as rows records or size
+// bytes are buffered, or when Flush is called; a threshold of 0 is ignored.
+// Write only blocks when many chunks are waiting to be written.
+//
+// Errors writing to w are returned by the following calls to Write, Flush and
+// Error.  Close must be called when done: it writes the remaining records,
+// waits for the goroutine and returns the first error.
+func NewAsyncWriter(w io.Writer, rows, size int) *Writer {
+	a := &asyncWriter{
+		w:      w,
+		rows:   rows,
+		size:   size,
+		chunk:  &bytes.Buffer{},
+		chunks: make(chan *bytes.Buffer, asyncChunks),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	writer := NewWriter(nil)
+	writer.w = bufio.NewWriter(a.chunk)
+	writer.async = a
+	return writer
+}
+
+// run writes the chunks to w until the chunks channel is closed.  Chunks
+// following an error are dropped.
+func (a *asyncWriter) run() {
+	defer close(a.done)
+	for chunk := range a.chunks {
+		if a.error() != nil {
+			continue
+		}
+		if _, err := a.w.Write(chunk.Bytes()); err != nil {
+			a.mu.Lock()
+			a.err = err
+			a.mu.Unlock()
+		}
+	}
+}
+
+// error returns the first error writing to w.
+func (a *asyncWriter) error() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// record counts a record written to bw and hands the chunk off once a
+// threshold is reached.
+func (a *asyncWriter) record(bw *bufio.Writer) error {
+	a.n++
+	if a.rows > 0 && a.n >= a.rows || a.size > 0 && a.chunk.Len()+bw.Buffered() >= a.size {
+		a.flush(bw)
+	}
+	return a.error()
+}
+
+// flush hands the records written to bw off to the goroutine.
+func (a *asyncWriter) flush(bw *bufio.Writer) {
+	bw.Flush()
+	if a.chunk.Len() > 0 {
+		a.chunks <- a.chunk
+		a.chunk = &bytes.Buffer{}
+	}
+	bw.Reset(a.chunk)
+	a.n = 0
+}
+
+// close writes the remaining records and waits for the goroutine.
+func (a *asyncWriter) close(bw *bufio.Writer) error {
+	if !a.closed {
+		a.flush(bw)
+		a.closed = true
+		close(a.chunks)
+		<-a.done
+	}
+	return a.error()
+}
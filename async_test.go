@@ -0,0 +1,77 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// chunkWriter records the chunks written to it.
+type chunkWriter struct {
+	chunks []string
+	err    error
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.chunks = append(c.chunks, string(p))
+	return len(p), nil
+}
+
+func TestAsyncWriter(t *testing.T) {
+	tests := []struct {
+		Rows, Size int
+		Chunks     []string
+	}{
+		{2, 0, []string{"a,1\nb,2\n", "c,3\nd,4\n", "e,5\n"}},
+		{0, 6, []string{"a,1\nb,2\n", "c,3\nd,4\n", "e,5\n"}},
+		{0, 0, []string{"a,1\nb,2\nc,3\nd,4\ne,5\n"}},
+	}
+	for _, tt := range tests {
+		c := &chunkWriter{}
+		w := NewAsyncWriter(c, tt.Rows, tt.Size)
+		for _, record := range [][]string{{"a", "1"}, {"b", "2"}, {"c", "3"}, {"d", "4"}, {"e", "5"}} {
+			if err := w.Write(record); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if len(c.chunks) != len(tt.Chunks) {
+			t.Errorf("rows %d, size %d: chunks=%q want %q", tt.Rows, tt.Size, c.chunks, tt.Chunks)
+			continue
+		}
+		for i := range c.chunks {
+			if c.chunks[i] != tt.Chunks[i] {
+				t.Errorf("rows %d, size %d: chunks=%q want %q", tt.Rows, tt.Size, c.chunks, tt.Chunks)
+				break
+			}
+		}
+		if err := w.Write([]string{"f"}); err != ErrClosed {
+			t.Errorf("write after Close: error %v, want ErrClosed", err)
+		}
+	}
+}
+
+func TestAsyncWriterError(t *testing.T) {
+	failure := errors.New("disk full")
+	w := NewAsyncWriter(&chunkWriter{err: failure}, 1, 0)
+	w.Write([]string{"a"})
+	if err := w.Close(); err != failure {
+		t.Errorf("Close: error %v, want %v", err, failure)
+	}
+
+	var b bytes.Buffer
+	w = NewWriter(&b)
+	w.Write([]string{"a"})
+	if err := w.Close(); err != nil || b.String() != "a\n" {
+		t.Errorf("Close: out=%q, error %v", b.String(), err)
+	}
+}
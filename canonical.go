@@ -0,0 +1,48 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import "io"
+
+// Canonicalize reads the comma-separated records of src and writes them to
+// dst in canonical form, so that versions of a file kept in git differ only
+// where their records do: fields are quoted as by a Writer with
+// QuoteMinimal, lines end with "\n", blank lines are dropped, and every
+// record has the number of fields of the first one.  Shorter records are
+// padded with empty fields and trailing empty fields of longer records are
+// dropped; a longer record whose extra fields are not empty returns a
+// ParseError wrapping ErrFieldCount.  Canonicalize is idempotent.
+func Canonicalize(src io.Reader, dst io.Writer) error {
+	r := NewReader(src)
+	r.FieldsPerRecord = -1
+	w := NewWriter(dst)
+	fields := -1
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if fields < 0 {
+			fields = len(record)
+		}
+		if len(record) > fields {
+			if len(trimTrailingEmpty(record)) > fields {
+				return &ParseError{Line: r.recordLine, Err: ErrFieldCount}
+			}
+			record = record[:fields]
+		}
+		for len(record) < fields {
+			record = append(record, "")
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
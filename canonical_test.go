@@ -0,0 +1,78 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var canonicalizeTests = []struct {
+	Name   string
+	Input  string
+	Output string
+	Error  string
+}{
+	{
+		Name:   "Quoting",
+		Input:  "\"a\",\"b c\"\n\"1\",\"x,y\"\n",
+		Output: "a,b c\n1,\"x,y\"\n",
+	},
+	{
+		Name:   "LineEndings",
+		Input:  "a,b\r\n1,2\r\n\"3\r\n4\",5",
+		Output: "a,b\n1,2\n\"3\n4\",5\n",
+	},
+	{
+		Name:   "BlankLines",
+		Input:  "a,b\n\n1,2\n\n\n",
+		Output: "a,b\n1,2\n",
+	},
+	{
+		Name:   "Padding",
+		Input:  "a,b,c\n1\n1,2\n",
+		Output: "a,b,c\n1,\"\",\"\"\n1,2,\"\"\n",
+	},
+	{
+		Name:   "TrailingEmpty",
+		Input:  "a,b\n1,2,,\n",
+		Output: "a,b\n1,2\n",
+	},
+	{
+		Name:  "ExtraFields",
+		Input: "a,b\n1,2\n1,2,3\n",
+		Error: "line 3, column 0: wrong number of fields in line",
+	},
+	{
+		Name:  "BareQuote",
+		Input: "a,b\n1,2\"\n",
+		Error: "line 2, column 3: bare \" in non-quoted-field",
+	},
+}
+
+func TestCanonicalize(t *testing.T) {
+	for _, tt := range canonicalizeTests {
+		b := &bytes.Buffer{}
+		err := Canonicalize(strings.NewReader(tt.Input), b)
+		if tt.Error != "" {
+			if err == nil || err.Error() != tt.Error {
+				t.Errorf("%s: error %v, want %s", tt.Name, err, tt.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.Name, err)
+			continue
+		}
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+		again := &bytes.Buffer{}
+		if err := Canonicalize(bytes.NewReader(b.Bytes()), again); err != nil || again.String() != b.String() {
+			t.Errorf("%s: canonicalized again to %q, %v", tt.Name, again.String(), err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrCharset is returned for input in a character set that is not
+// registered with RegisterCharset.
+var ErrCharset = errors.New("unsupported charset")
+
+var (
+	charsetsMu sync.Mutex
+	charsets   = map[string]func(io.Reader) io.Reader{
+		"utf-8":        decodeUTF8,
+		"us-ascii":     decodeUTF8,
+		"utf-16":       func(r io.Reader) io.Reader { return decodeUTF16(r, nil) },
+		"utf-16le":     func(r io.Reader) io.Reader { return decodeUTF16(r, binary.LittleEndian) },
+		"utf-16be":     func(r io.Reader) io.Reader { return decodeUTF16(r, binary.BigEndian) },
+		"iso-8859-1":   decodeLatin1,
+		"latin1":       decodeLatin1,
+		"windows-1252": decodeWindows1252,
+	}
+)
+
+// RegisterCharset registers the decoder to UTF-8 of the character set name,
+// as named in the charset parameter of Content-Type headers, for
+// NewResponseReader.  UTF-8, UTF-16, ISO-8859-1 and Windows-1252 are
+// registered by default; others can be registered with a third-party
+// package:
+//
+//	bettercsv.RegisterCharset("shift_jis", func(r io.Reader) io.Reader {
+//		return japanese.ShiftJIS.NewDecoder().Reader(r)
+//	})
+func RegisterCharset(name string, decode func(io.Reader) io.Reader) {
+	charsetsMu.Lock()
+	defer charsetsMu.Unlock()
+	charsets[strings.ToLower(name)] = decode
+}
+
+// decodeCharset returns r decoded from the character set name to UTF-8.
+func decodeCharset(r io.Reader, name string) (io.Reader, error) {
+	charsetsMu.Lock()
+	decode := charsets[strings.ToLower(name)]
+	charsetsMu.Unlock()
+	if decode == nil {
+		return nil, fmt.Errorf("%w %q", ErrCharset, name)
+	}
+	return decode(r), nil
+}
+
+// decodeContentType returns r decoded to UTF-8 from the charset of the
+// Content-Type contentType, and its media type.  Input without charset is
+// read as UTF-8, or as UTF-16 if it starts with a UTF-16 byte order mark.
+func decodeContentType(r io.Reader, contentType string) (io.Reader, string, error) {
+	var mediaType, charset string
+	if contentType != "" {
+		var params map[string]string
+		var err error
+		if mediaType, params, err = mime.ParseMediaType(contentType); err != nil {
+			return nil, "", err
+		}
+		charset = params["charset"]
+	}
+	if charset != "" {
+		r, err := decodeCharset(r, charset)
+		return r, mediaType, err
+	}
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(2); string(b) == "\xfe\xff" || string(b) == "\xff\xfe" {
+		return decodeUTF16(br, nil), mediaType, nil
+	}
+	return decodeUTF8(br), mediaType, nil
+}
+
+// A runeDecoder is an io.Reader of the UTF-8 encoding of the runes decoded
+// from its input by decode.
+type runeDecoder struct {
+	r      *bufio.Reader
+	decode func(r *bufio.Reader) (rune, error)
+	err    error
+}
+
+func (d *runeDecoder) Read(p []byte) (n int, err error) {
+	for d.err == nil && len(p)-n >= utf8.UTFMax {
+		var r1 rune
+		r1, d.err = d.decode(d.r)
+		if d.err == nil {
+			n += utf8.EncodeRune(p[n:], r1)
+		}
+	}
+	if n > 0 {
+		return n, nil
+	}
+	if d.err == nil {
+		return 0, io.ErrShortBuffer
+	}
+	return 0, d.err
+}
+
+// decodeUTF8 returns r without its byte order mark.
+func decodeUTF8(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(3); string(b) == "\xef\xbb\xbf" {
+		br.Discard(3)
+	}
+	return br
+}
+
+// decodeUTF16 returns r decoded from UTF-16 in order, or in the order of
+// its byte order mark if order is nil, defaulting to big-endian.
+func decodeUTF16(r io.Reader, order binary.ByteOrder) io.Reader {
+	br := bufio.NewReader(r)
+	b, _ := br.Peek(2)
+	switch {
+	case len(b) < 2:
+	case b[0] == 0xfe && b[1] == 0xff && order != binary.LittleEndian:
+		order = binary.BigEndian
+		br.Discard(2)
+	case b[0] == 0xff && b[1] == 0xfe && order != binary.BigEndian:
+		order = binary.LittleEndian
+		br.Discard(2)
+	}
+	if order == nil {
+		order = binary.BigEndian
+	}
+	unit := func(r *bufio.Reader) (uint16, error) {
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return utf8.RuneError, nil
+			}
+			return 0, err
+		}
+		return order.Uint16(b[:]), nil
+	}
+	return &runeDecoder{r: br, decode: func(r *bufio.Reader) (rune, error) {
+		u, err := unit(r)
+		if err != nil || !utf16.IsSurrogate(rune(u)) {
+			return rune(u), err
+		}
+		if b, _ := r.Peek(2); len(b) == 2 {
+			if r1 := utf16.DecodeRune(rune(u), rune(order.Uint16(b))); r1 != utf8.RuneError {
+				r.Discard(2)
+				return r1, nil
+			}
+		}
+		return utf8.RuneError, nil
+	}}
+}
+
+// decodeLatin1 returns r decoded from ISO-8859-1, whose bytes are the
+// first 256 runes.
+func decodeLatin1(r io.Reader) io.Reader {
+	return &runeDecoder{r: bufio.NewReader(r), decode: func(r *bufio.Reader) (rune, error) {
+		b, err := r.ReadByte()
+		return rune(b), err
+	}}
+}
+
+// windows1252 holds the runes of the bytes 0x80 to 0x9f of Windows-1252,
+// which are otherwise those of ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', utf8.RuneError, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', utf8.RuneError, 'Ž', utf8.RuneError,
+	utf8.RuneError, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', utf8.RuneError, 'ž', 'Ÿ',
+}
+
+// decodeWindows1252 returns r decoded from Windows-1252.
+func decodeWindows1252(r io.Reader) io.Reader {
+	return &runeDecoder{r: bufio.NewReader(r), decode: func(r *bufio.Reader) (rune, error) {
+		b, err := r.ReadByte()
+		if 0x80 <= b && b < 0xa0 {
+			return windows1252[b-0x80], err
+		}
+		return rune(b), err
+	}}
+}
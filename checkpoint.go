@@ -0,0 +1,55 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"slices"
+)
+
+// A Checkpoint is the position of a Reader between two records.  It can be
+// saved, for instance as JSON, and passed to Resume to continue reading
+// after a restart.
+type Checkpoint struct {
+	Offset          int64    `json:"offset"`            // bytes of input read
+	Line            int      `json:"line"`              // lines of input read
+	Records         int      `json:"records"`           // records read, including the header row
+	FieldsPerRecord int      `json:"fields_per_record"` // FieldsPerRecord of the Reader
+	Headers         []string `json:"headers,omitempty"` // headers of the Reader, if read
+}
+
+// Checkpoint returns the position of r after the last record read.  Offsets
+// are counted in the input once decompressed, and are not meaningful for a
+// MultiReader.
+func (r *Reader) Checkpoint() *Checkpoint {
+	return &Checkpoint{
+		Offset:          r.offset,
+		Line:            r.line,
+		Records:         r.returned,
+		FieldsPerRecord: r.FieldsPerRecord,
+		Headers:         slices.Clone(r.headers),
+	}
+}
+
+// Resume returns a new Reader that continues reading rs at the position of
+// cp, taken on a Reader of the same input.  The headers read before the
+// checkpoint are restored, so that the map methods keep working, and line
+// numbers continue from the checkpoint.  Other options, such as Comma, must
+// be set again.  The input must not be compressed.
+func Resume(rs io.ReadSeeker, cp *Checkpoint) (*Reader, error) {
+	if _, err := rs.Seek(cp.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	r := newPlainReader(rs)
+	r.seeker = rs
+	r.offset = cp.Offset
+	r.line = cp.Line
+	r.returned = cp.Records
+	r.records = cp.Records
+	r.FieldsPerRecord = cp.FieldsPerRecord
+	r.headers = slices.Clone(cp.Headers)
+	r.headerRead = cp.Records > 0
+	return r, nil
+}
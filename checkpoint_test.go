@@ -0,0 +1,52 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	input := "id,note\r\n1,\"multi\nline\"\n# skipped\n2,héllo\n3,x\n4,\n"
+	r := NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	r.ReadToMap()
+	r.ReadToMap()
+	r.ReadToMap()
+	data, err := json.Marshal(r.Checkpoint())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cp.Offset != int64(strings.Index(input, "3,x")) || cp.Line != 5 {
+		t.Errorf("checkpoint=%+v", cp)
+	}
+	resumed, err := Resume(strings.NewReader(input), &cp)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	resumed.Comment = '#'
+	records, err := resumed.ReadAllToMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []map[string]string{{"id": "3", "note": "x"}, {"id": "4", "note": ""}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+
+	resumed, _ = Resume(strings.NewReader(input), &cp)
+	resumed.FieldsPerRecord = 3
+	if _, err := resumed.Read(); err == nil || err.Error() != "line 6, column 0: wrong number of fields in line" {
+		t.Errorf("error %v, want wrong number of fields on line 6", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import "strings"
+
+// A Comment is a comment line captured by a Reader with KeepComments.
+type Comment struct {
+	Line   int    // line of the comment
+	Record int    // number of records returned, including the header row, before it
+	Text   string // text following the comment character
+}
+
+// Comments returns the comment lines read so far when KeepComments is true.
+// Comments preceding a record are read along with it.
+func (r *Reader) Comments() []Comment {
+	return r.comments
+}
+
+// readComment reads the rest of a comment line, whose comment character has
+// been read, and captures it.
+func (r *Reader) readComment() error {
+	var text strings.Builder
+	var err error
+	for {
+		var r1 rune
+		r1, err = r.readRune()
+		if err != nil || r1 == '\n' {
+			break
+		}
+		text.WriteRune(r1)
+	}
+	r.comments = append(r.comments, Comment{Line: r.line, Record: r.returned - r.filtered, Text: text.String()})
+	return err
+}
+
+// WriteComments writes the text of each comment as it was read, following
+// Comment.  It returns ErrNoComment if Comment is not set or could start a
+// field.
+func (w *Writer) WriteComments(comments ...Comment) error {
+	lines := make([]string, len(comments))
+	for i, c := range comments {
+		lines[i] = c.Text
+	}
+	return w.writeComments(lines, "")
+}
+
+// WriteAllWithComments writes records with Write, writing each comment
+// before the record at its Record index and the others at the end, and then
+// calls Flush.  Comments captured while reading records with ReadAll are
+// written back in place this way.
+func (w *Writer) WriteAllWithComments(records [][]string, comments []Comment) error {
+	next := 0
+	for i, record := range records {
+		start := next
+		for next < len(comments) && comments[next].Record <= i {
+			next++
+		}
+		if err := w.WriteComments(comments[start:next]...); err != nil {
+			return err
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := w.WriteComments(comments[next:]...); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
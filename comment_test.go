@@ -0,0 +1,161 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommentRoundTrip(t *testing.T) {
+	input := "# settings\nkey,value\n#  timeout in seconds\ntimeout,30\n#\nretries,3\n#end"
+	r := NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	r.KeepComments = true
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	comments := []Comment{
+		{Line: 1, Record: 0, Text: " settings"},
+		{Line: 3, Record: 1, Text: "  timeout in seconds"},
+		{Line: 5, Record: 2, Text: ""},
+		{Line: 7, Record: 3, Text: "end"},
+	}
+	if !reflect.DeepEqual(r.Comments(), comments) {
+		t.Errorf("comments=%+v want %+v", r.Comments(), comments)
+	}
+
+	records[1][1] = "60"
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Comment = '#'
+	if err := w.WriteAllWithComments(records, r.Comments()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := strings.Replace(input, "30", "60", 1) + "\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+
+	w = NewWriter(&b)
+	if err := w.WriteAllWithComments(records, nil); err != nil {
+		t.Errorf("no comments: unexpected error %v", err)
+	}
+	if err := w.WriteAllWithComments(records, comments); err != ErrNoComment {
+		t.Errorf("error %v, want ErrNoComment", err)
+	}
+}
+
+func TestCommentPrefixes(t *testing.T) {
+	r := NewReader(strings.NewReader("-- export\nkey,value\n// timeout\ntimeout,30\n"))
+	r.CommentPrefixes = []string{"--", "//"}
+	r.KeepComments = true
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("records=%q", records)
+	}
+	comments := []Comment{
+		{Line: 1, Record: 0, Text: " export"},
+		{Line: 3, Record: 1, Text: " timeout"},
+	}
+	if !reflect.DeepEqual(r.Comments(), comments) {
+		t.Errorf("comments=%+v want %+v", r.Comments(), comments)
+	}
+}
+
+func TestCommentFilter(t *testing.T) {
+	input := "key,value\nskip,1\n# timeout\ntimeout,30\n"
+	r := NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	r.KeepComments = true
+	r.Filter = func(record []string) bool { return record[0] != "skip" }
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	comments := []Comment{{Line: 3, Record: 1, Text: " timeout"}}
+	if !reflect.DeepEqual(r.Comments(), comments) {
+		t.Errorf("comments=%+v want %+v", r.Comments(), comments)
+	}
+
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Comment = '#'
+	if err := w.WriteAllWithComments(records, r.Comments()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := "key,value\n# timeout\ntimeout,30\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
+
+func TestCommentSkipped(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Input     string
+		Configure func(r *Reader)
+	}{
+		{
+			Name:      "SkipLineOnErr",
+			Input:     "key,value\nbad\n# timeout\ntimeout,30\n",
+			Configure: func(r *Reader) { r.SkipLineOnErr = true },
+		},
+		{
+			Name:  "FilterMap",
+			Input: "key,value\nskip,1\n# timeout\ntimeout,30\n",
+			Configure: func(r *Reader) {
+				r.FilterMap = func(record map[string]string) bool { return record["key"] != "skip" }
+			},
+		},
+	}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(tt.Input))
+		r.Comment = '#'
+		r.KeepComments = true
+		tt.Configure(r)
+		records, err := r.ReadAllToMaps()
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.Name, err)
+		}
+		if len(records) != 2 {
+			t.Errorf("%s: records=%q", tt.Name, records)
+		}
+		comments := []Comment{{Line: 3, Record: 1, Text: " timeout"}}
+		if !reflect.DeepEqual(r.Comments(), comments) {
+			t.Errorf("%s: comments=%+v want %+v", tt.Name, r.Comments(), comments)
+		}
+	}
+}
+
+func TestCommentBeforeHeader(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Comment = '#'
+	w.AutoHeader = true
+	w.Headers = []string{"a", "b"}
+	if err := w.WriteComment("exported"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.WriteMap(map[string]string{"a": "1", "b": "2"})
+	w.Flush()
+
+	for _, input := range []string{b.String(), "\n" + b.String()} {
+		r := NewReader(strings.NewReader(input))
+		r.Comment = '#'
+		records, err := r.ReadAllToMaps()
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		want := []map[string]string{{"a": "a", "b": "b"}, {"a": "1", "b": "2"}}
+		if !reflect.DeepEqual(records, want) {
+			t.Errorf("input %q: records=%q want %q", input, records, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// NewCompressedWriter returns a new Writer that writes to w through the
+// compressor returned by compress, such as gzip.NewWriter.  Flush flushes the
+// compressor too if it has a Flush method, so that the records written so
+// far can be decompressed.  Close must be called when done to end the
+// compressed stream; it does not close w.
+func NewCompressedWriter(w io.Writer, compress func(io.Writer) io.WriteCloser) *Writer {
+	compressor := compress(w)
+	writer := NewWriter(nil)
+	writer.w = bufio.NewWriter(compressor)
+	writer.compressor = compressor
+	return writer
+}
+
+// NewGzipWriter returns a new Writer that writes gzip compressed CSV to w,
+// as NewCompressedWriter.
+func NewGzipWriter(w io.Writer) *Writer {
+	return NewCompressedWriter(w, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+}
+
+// flushCompressor flushes the compressor, if any.
+func (w *Writer) flushCompressor() {
+	if f, ok := w.compressor.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil && w.compressErr == nil {
+			w.compressErr = err
+		}
+	}
+}
+
+// closeCompressor ends the compressed stream, if any.
+func (w *Writer) closeCompressor() {
+	if w.compressor == nil {
+		return
+	}
+	if err := w.compressor.Close(); err != nil && w.compressErr == nil {
+		w.compressErr = err
+	}
+	w.compressor = nil
+}
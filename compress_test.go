@@ -0,0 +1,41 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestGzipWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewGzipWriter(&b)
+	w.Write([]string{"a", "b"})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The records flushed so far can be decompressed before Close.
+	zr, err := gzip.NewReader(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	partial, _ := io.ReadAll(zr)
+	if string(partial) != "a,b\n" {
+		t.Errorf("flushed=%q want %q", partial, "a,b\n")
+	}
+
+	w.Write([]string{"1", "2"})
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	records, err := NewReader(&b).ReadAll()
+	if err != nil || len(records) != 2 || records[1][1] != "2" {
+		t.Errorf("read back %q, %v", records, err)
+	}
+}
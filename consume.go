@@ -0,0 +1,49 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"context"
+	"errors"
+)
+
+// Consume writes each record received from records until the channel is
+// closed or ctx is done, and then calls Flush.  A record that fails to be
+// written does not stop Consume; the errors of all records, and ctx.Err() if
+// ctx is done first, are returned joined with errors.Join.
+func (w *Writer) Consume(ctx context.Context, records <-chan []string) error {
+	return consume(ctx, w, records, w.Write)
+}
+
+// ConsumeMaps writes each record received from records with WriteMap until
+// the channel is closed or ctx is done, like Consume.
+func (w *Writer) ConsumeMaps(ctx context.Context, records <-chan map[string]string) error {
+	return consume(ctx, w, records, w.WriteMap)
+}
+
+// consume drains records into write and flushes w.
+func consume[T any](ctx context.Context, w *Writer, records <-chan T, write func(T) error) error {
+	var errs []error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			errs = append(errs, ctx.Err())
+			break loop
+		case record, ok := <-records:
+			if !ok {
+				break loop
+			}
+			if err := write(record); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
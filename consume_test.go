@@ -0,0 +1,60 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestConsume(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,\"x,y\"\n2,z\n"))
+	records := make(chan []string)
+	go func() {
+		defer close(records)
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			records <- record
+		}
+	}()
+
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.QuoteMode = QuoteNever
+	err := w.Consume(context.Background(), records)
+	if !errors.Is(err, ErrNeedsQuotes) {
+		t.Errorf("error %v, want ErrNeedsQuotes", err)
+	}
+	if want := "a,b\n2,z\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
+
+func TestConsumeMapsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	records := make(chan map[string]string)
+
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Headers = []string{"a"}
+	w.AutoHeader = true
+	done := make(chan error)
+	go func() { done <- w.ConsumeMaps(ctx, records) }()
+	records <- map[string]string{"a": "1"}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v, want context.Canceled", err)
+	}
+	if want := "a\n1\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
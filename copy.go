@@ -0,0 +1,184 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A CopyFormat is a format of the PostgreSQL COPY command.
+type CopyFormat int
+
+const (
+	CopyText CopyFormat = iota + 1 // COPY ... (FORMAT text), the default format
+	CopyCSV                        // COPY ... (FORMAT csv)
+)
+
+// ErrOctalEscape is wrapped by the ParseErrors of the octal escapes of
+// CopyText above \377, which do not fit a byte.
+var ErrOctalEscape = errors.New("octal escape out of range")
+
+// copyEnd is the line marking the end of the data of COPY FROM STDIN.
+const copyEnd = `\.`
+
+// NewCopyReader returns a new Reader that reads data in the format of the
+// PostgreSQL COPY command with its default options, as written by COPY TO
+// STDOUT.
+//
+// In CopyText, fields are separated by tabs and backslash escapes, such as
+// \t, \n, \\, \010 and \x08, are decoded.  The NULL values, written \N, are
+// read as `\N`, while an escaped `\\N` is read as the same text.  In
+// CopyCSV, fields are separated by commas and quoted as usual, and the
+// NULL values are the unquoted empty fields.  In both formats, the nullable
+// map methods and SQLLoader tell the NULL values from text.  An empty line
+// is a record of one empty field, as written for tables of one column:
+// an empty string in CopyText and NULL in CopyCSV.  A line consisting of \.
+// ends the input.  Octal escapes above \377 are a
+// ParseError wrapping ErrOctalEscape.
+func NewCopyReader(r io.Reader, format CopyFormat) *Reader {
+	reader := NewReader(r)
+	reader.copyFormat = format
+	if format == CopyText {
+		reader.Comma = '\t'
+		reader.BackslashEscapes = true
+	}
+	return reader
+}
+
+// NewCopyWriter returns a new Writer that writes data in the format of the
+// PostgreSQL COPY command with its default options, to be read by COPY FROM
+// STDIN.  Nil values, as written by WriteNullableMap, WriteStruct and
+// WriteRows, are written as NULL: \N in CopyText and an unquoted empty
+// field in CopyCSV, where empty strings are quoted.
+func NewCopyWriter(w io.Writer, format CopyFormat) *Writer {
+	writer := NewWriter(w)
+	writer.copyFormat = format
+	if format == CopyText {
+		writer.Comma = '\t'
+		writer.BackslashEscapes = true
+		writer.NullValue = `\N`
+	}
+	return writer
+}
+
+// atCopyEnd reports whether the input of r is at the \. line ending the
+// data of a COPY format.
+func (r *Reader) atCopyEnd() bool {
+	b, _ := r.r.Peek(len(copyEnd) + 2)
+	if !strings.HasPrefix(string(b), copyEnd) {
+		return false
+	}
+	rest := string(b[len(copyEnd):])
+	return rest == "" || rest[0] == '\n' || strings.HasPrefix(rest, "\r\n") || rest == "\r"
+}
+
+// atEmptyLine reports whether the input of r is at the end of a line, with
+// no field before it.
+func (r *Reader) atEmptyLine() bool {
+	b, _ := r.r.Peek(2)
+	return len(b) > 0 && b[0] == '\n' || string(b) == "\r\n"
+}
+
+// emptyLineField reads an empty line as a record of one empty field, which
+// is NULL in CopyCSV.
+func (r *Reader) emptyLineField() []string {
+	r.skip('\n')
+	r.quotes = append(r.quotes, false)
+	r.nulls = append(r.nulls[:0], r.copyFormat == CopyCSV)
+	return []string{""}
+}
+
+// unescapeCopy decodes the escape sequence of CopyText starting with r1,
+// which followed a backslash, reading the digits following it from r.  It
+// returns ErrOctalEscape for octal escapes that do not fit a byte.
+func (r *Reader) unescapeCopy(field *strings.Builder, r1 rune) error {
+	switch r1 {
+	case 'b':
+		field.WriteByte('\b')
+	case 'f':
+		field.WriteByte('\f')
+	case 'n':
+		field.WriteByte('\n')
+	case 'r':
+		field.WriteByte('\r')
+	case 't':
+		field.WriteByte('\t')
+	case 'v':
+		field.WriteByte('\v')
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		n := r.copyNumber(string(r1), 3, 8)
+		if n > 0377 {
+			return ErrOctalEscape
+		}
+		field.WriteByte(byte(n))
+	case 'x':
+		if b, _ := r.r.Peek(1); len(b) == 1 && isHexDigit(b[0]) {
+			field.WriteByte(byte(r.copyNumber("", 2, 16)))
+		} else {
+			field.WriteRune('x')
+		}
+	default:
+		field.WriteRune(r1)
+	}
+	return nil
+}
+
+// copyNumber reads digits of base following digits, up to n in all, and
+// returns their value.
+func (r *Reader) copyNumber(digits string, n, base int) uint64 {
+	for len(digits) < n {
+		b, _ := r.r.Peek(1)
+		if len(b) == 0 || base == 8 && (b[0] < '0' || b[0] > '7') || base == 16 && !isHexDigit(b[0]) {
+			break
+		}
+		r.readRune()
+		digits += string(b[0])
+	}
+	v, _ := strconv.ParseUint(digits, base, 64)
+	return v
+}
+
+// isHexDigit reports whether b is a hexadecimal digit.
+func isHexDigit(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
+
+// fieldIsNull reports whether field, at index in the last record read after
+// the column selection, is null: read as NULL in a COPY format, an unquoted
+// empty field with BareEmptyNull, or one of the NullValues.
+func (r *Reader) fieldIsNull(index int, field string) bool {
+	if r.copyFormat == 0 && (!r.BareEmptyNull || field != "") {
+		return r.isNull(field)
+	}
+	if r.columns != nil {
+		index = r.columns[index]
+	}
+	return index < len(r.nulls) && r.nulls[index]
+}
+
+// escapeCopy returns the escape sequence of r1 in CopyText, or "" if r1 is
+// written as is.
+func escapeCopy(r1 rune) string {
+	switch r1 {
+	case '\\':
+		return `\\`
+	case '\b':
+		return `\b`
+	case '\f':
+		return `\f`
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	case '\v':
+		return `\v`
+	}
+	return ""
+}
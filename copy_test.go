@@ -0,0 +1,191 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// null is a nil *string in the tests of nullable maps.
+var null *string
+
+var copyReaderTests = []struct {
+	Name   string
+	Format CopyFormat
+	Input  string
+	Output []map[string]*string
+}{
+	{
+		Name:   "Text",
+		Format: CopyText,
+		Input:  "a\tb\n1\\t2\t\\N\n\\\\N\tx\\Ny\n",
+		Output: []map[string]*string{
+			{"a": stringPtr("1\t2"), "b": null},
+			{"a": stringPtr(`\N`), "b": stringPtr("xNy")},
+		},
+	},
+	{
+		Name:   "TextEscapes",
+		Format: CopyText,
+		Input:  "a\tb\n\\b\\f\\v\\r\\n\t\\101\\x41\\x\\q\\\t\n",
+		Output: []map[string]*string{
+			{"a": stringPtr("\b\f\v\r\n"), "b": stringPtr("AAxq\t")},
+		},
+	},
+	{
+		Name:   "TextEnd",
+		Format: CopyText,
+		Input:  "a\tb\n1\t2\n\\.\n3\t4\n",
+		Output: []map[string]*string{{"a": stringPtr("1"), "b": stringPtr("2")}},
+	},
+	{
+		Name:   "CSV",
+		Format: CopyCSV,
+		Input:  "a,b,c\n,\"\",\\N\n\"x,y\",\"\\.\",\n",
+		Output: []map[string]*string{
+			{"a": null, "b": stringPtr(""), "c": stringPtr(`\N`)},
+			{"a": stringPtr("x,y"), "b": stringPtr(`\.`), "c": null},
+		},
+	},
+	{
+		Name:   "CSVEnd",
+		Format: CopyCSV,
+		Input:  "a,b\n1,2\r\n\\.\r\n3,4\n",
+		Output: []map[string]*string{{"a": stringPtr("1"), "b": stringPtr("2")}},
+	},
+}
+
+func TestCopyReader(t *testing.T) {
+	for _, tt := range copyReaderTests {
+		r := NewCopyReader(strings.NewReader(tt.Input), tt.Format)
+		out, err := r.ReadAllToNullableMaps()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(out[1:], tt.Output) {
+			t.Errorf("%s: out=%s want %s", tt.Name, formatNullable(out[1:]), formatNullable(tt.Output))
+		}
+	}
+}
+
+func TestCopyReaderOctalEscape(t *testing.T) {
+	input := "a\tb\n1\t\\400\n\\101\t2\n"
+	r := NewCopyReader(strings.NewReader(input), CopyText)
+	r.Read()
+	_, err := r.Read()
+	want := &ParseError{Line: 2, Column: 2, Err: ErrOctalEscape}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("error %v, want %v", err, want)
+	}
+
+	r = NewCopyReader(strings.NewReader(input), CopyText)
+	r.SkipLineOnErr = true
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := [][]string{{"a", "b"}, {"A", "2"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+}
+
+func TestCopyReaderSelect(t *testing.T) {
+	r := NewCopyReader(strings.NewReader("a\tb\n\\N\t1\n2\t\\N\n"), CopyText)
+	r.Select("b")
+	out, err := r.ReadAllToNullableMaps()
+	want := []map[string]*string{{"b": stringPtr("b")}, {"b": stringPtr("1")}, {"b": null}}
+	if err != nil || !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%s err=%v want %s", formatNullable(out), err, formatNullable(want))
+	}
+}
+
+var copyWriterTests = []struct {
+	Name   string
+	Format CopyFormat
+	Output string
+}{
+	{
+		Name:   "Text",
+		Format: CopyText,
+		Output: "a\tb\tc\n\\N\t\tx\\ty\\\\N\n\\b\\v\\n\\r\t\\\\.\t\\N\n",
+	},
+	{
+		Name:   "CSV",
+		Format: CopyCSV,
+		Output: "a,b,c\n,\"\",x\ty\\N\n\"\b\v\n\r\",\"\\.\",\n",
+	},
+}
+
+func TestCopyWriter(t *testing.T) {
+	records := []map[string]*string{
+		{"a": null, "b": stringPtr(""), "c": stringPtr("x\ty\\N")},
+		{"a": stringPtr("\b\v\n\r"), "b": stringPtr(`\.`)},
+	}
+	for _, tt := range copyWriterTests {
+		var b bytes.Buffer
+		w := NewCopyWriter(&b, tt.Format)
+		w.Headers = []string{"a", "b", "c"}
+		w.AutoHeader = true
+		for _, record := range records {
+			if err := w.WriteNullableMap(record); err != nil {
+				t.Fatalf("%s: unexpected error %v", tt.Name, err)
+			}
+		}
+		w.Flush()
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+
+		out, err := NewCopyReader(&b, tt.Format).ReadAllToNullableMaps()
+		want := append([]map[string]*string{{"a": stringPtr("a"), "b": stringPtr("b"), "c": stringPtr("c")}}, records...)
+		want[2]["c"] = nil
+		if err != nil || !reflect.DeepEqual(out, want) {
+			t.Errorf("%s: read back %s, %v, want %s", tt.Name, formatNullable(out), err, formatNullable(want))
+		}
+	}
+}
+
+func TestCopyOneColumn(t *testing.T) {
+	records := []map[string]*string{{"a": stringPtr("")}, {"a": null}, {"a": stringPtr("x")}}
+	for _, format := range []CopyFormat{CopyText, CopyCSV} {
+		var b bytes.Buffer
+		w := NewCopyWriter(&b, format)
+		w.Headers = []string{"a"}
+		w.AutoHeader = true
+		for _, record := range records {
+			if err := w.WriteNullableMap(record); err != nil {
+				t.Fatalf("format %d: unexpected error %v", format, err)
+			}
+		}
+		w.Flush()
+
+		out, err := NewCopyReader(&b, format).ReadAllToNullableMaps()
+		want := append([]map[string]*string{{"a": stringPtr("a")}}, records...)
+		if err != nil || !reflect.DeepEqual(out, want) {
+			t.Errorf("format %d: read back %s, %v, want %s", format, formatNullable(out), err, formatNullable(want))
+		}
+	}
+}
+
+// formatNullable formats records for test failures.
+func formatNullable(records []map[string]*string) string {
+	var b strings.Builder
+	for _, record := range records {
+		b.WriteString("{")
+		for _, key := range sortedKeys(record) {
+			if record[key] == nil {
+				b.WriteString(key + ":nil ")
+			} else {
+				b.WriteString(key + ":" + *record[key] + " ")
+			}
+		}
+		b.WriteString("}")
+	}
+	return b.String()
+}
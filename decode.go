@@ -0,0 +1,379 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedType is returned when a struct field has a type that cannot
+// be decoded.
+var ErrUnsupportedType = errors.New("unsupported field type")
+
+// structField describes a field of a struct decoded from a record.
+type structField struct {
+	name       string // column name, from the csv tag or the field name
+	index      []int  // index sequence for reflect.Value.FieldByIndex
+	defaultVal string // value used when the column is missing
+	hasDefault bool
+}
+
+// structFields caches the fields of the struct types decoded so far.
+var structFields sync.Map // map[reflect.Type][]structField
+
+// fieldsOf returns the decodable fields of the struct type t.
+//
+// The column of a field is named by its csv tag, or by the field name if the
+// field has no tag.  Fields tagged with "-" and unexported fields are
+// ignored.  The fields of nested structs are named by joining the names with
+// a dot, as in "address.city", while the fields of embedded structs without a
+// tag are named as if they were fields of t.
+func fieldsOf(t reflect.Type) []structField {
+	if fields, ok := structFields.Load(t); ok {
+		return fields.([]structField)
+	}
+	var fields []structField
+	appendFields(&fields, t, "", nil, map[reflect.Type]bool{t: true})
+	structFields.Store(t, fields)
+	return fields
+}
+
+// appendFields appends the fields of the struct type t to fields, prefixing
+// their names with prefix and their index with index.  Types in visiting are
+// not descended into again, to stop at recursive types.
+func appendFields(fields *[]structField, t reflect.Type, prefix string, index []int, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("csv")
+		if tag == "-" {
+			continue
+		}
+		tagName, defaultVal, hasDefault := parseTag(tag)
+		name := tagName
+		if name == "" {
+			name = f.Name
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != timeType && !isText(ft) {
+			if visiting[ft] {
+				continue
+			}
+			visiting[ft] = true
+			if f.Anonymous && tagName == "" {
+				appendFields(fields, ft, prefix, fieldIndex, visiting)
+			} else {
+				appendFields(fields, ft, prefix+name+".", fieldIndex, visiting)
+			}
+			delete(visiting, ft)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		*fields = append(*fields, structField{
+			name:       prefix + name,
+			index:      fieldIndex,
+			defaultVal: defaultVal,
+			hasDefault: hasDefault,
+		})
+	}
+}
+
+// parseTag splits a csv tag into the column name and the value of its
+// default option, as in `csv:"country,default=US"`.  The default value runs to
+// the end of the tag, so it may contain commas.
+func parseTag(tag string) (name, defaultVal string, hasDefault bool) {
+	name, opts, _ := strings.Cut(tag, ",")
+	for opts != "" {
+		if value, ok := strings.CutPrefix(opts, "default="); ok {
+			return name, value, true
+		}
+		_, opts, _ = strings.Cut(opts, ",")
+	}
+	return name, "", false
+}
+
+// fieldByIndex returns the nested field of the struct v at index.  Nil
+// pointers to structs on the way are allocated if alloc is true; otherwise ok
+// is false when one is found.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (field reflect.Value, ok bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// A structDecoder decodes records with given headers into structs of a type.
+type structDecoder struct {
+	fields   []*structField // field of each column, or nil
+	headers  []string
+	layouts  [][]string      // time layouts of each column
+	defaults []structDefault // defaults of the fields without a column
+}
+
+// structDefault is the value of a field whose column is missing.
+type structDefault struct {
+	field   *structField
+	value   string
+	layouts []string
+}
+
+// newStructDecoder returns a structDecoder for the struct type t and the
+// output headers of r.
+func (r *Reader) newStructDecoder(t reflect.Type) *structDecoder {
+	fields := fieldsOf(t)
+	d := &structDecoder{headers: r.outputHeaders()}
+	d.fields = make([]*structField, len(d.headers))
+	d.layouts = make([][]string, len(d.headers))
+	for i, header := range d.headers {
+		for j := range fields {
+			if fields[j].name == header {
+				d.fields[i] = &fields[j]
+				break
+			}
+		}
+		d.layouts[i] = r.timeLayouts[header]
+	}
+Fields:
+	for j := range fields {
+		for _, field := range d.fields {
+			if field == &fields[j] {
+				continue Fields
+			}
+		}
+		layouts := r.timeLayouts[fields[j].name]
+		if value, ok := r.Defaults[fields[j].name]; ok {
+			d.defaults = append(d.defaults, structDefault{&fields[j], value, layouts})
+		} else if fields[j].hasDefault {
+			d.defaults = append(d.defaults, structDefault{&fields[j], fields[j].defaultVal, layouts})
+		}
+	}
+	return d
+}
+
+// decode stores record in the struct v.  The returned error is a *FieldError
+// for the first field that cannot be converted.
+func (d *structDecoder) decode(record []string, v reflect.Value, line int) error {
+	for _, def := range d.defaults {
+		fv, _ := fieldByIndex(v, def.field.index, true)
+		if err := setValue(fv, def.value, def.layouts); err != nil {
+			return &FieldError{Line: line, Column: -1, Name: def.field.name, Err: err}
+		}
+	}
+	for i, field := range record {
+		if i >= len(d.fields) || d.fields[i] == nil {
+			continue
+		}
+		fv, _ := fieldByIndex(v, d.fields[i].index, true)
+		if err := setValue(fv, field, d.layouts[i]); err != nil {
+			return &FieldError{Line: line, Column: i, Name: d.headers[i], Err: err}
+		}
+	}
+	return nil
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isText reports whether values of t, or pointers to them, convert themselves
+// to or from text.
+func isText(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return t.Implements(textUnmarshalerType) || p.Implements(textUnmarshalerType) ||
+		t.Implements(textMarshalerType) || p.Implements(textMarshalerType)
+}
+
+// setValue converts s and stores it in v.  Empty strings store the zero
+// value, which is nil for pointers.
+func setValue(v reflect.Value, s string, layouts []string) error {
+	if s == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Type() == timeType {
+		t, err := parseTime(s, layouts)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), s, layouts); err != nil {
+			return err
+		}
+		v.Set(p)
+	default:
+		return fmt.Errorf("%w %s", ErrUnsupportedType, v.Type())
+	}
+	return nil
+}
+
+// ReadAllInto reads all the remaining records from r and decodes them into
+// values of the struct type T, or pointers to it.  The first record is read as
+// the headers and is not decoded.  Each column is stored in the exported
+// field named by its csv tag, or by its field name if the field has no tag:
+//
+//	type Person struct {
+//	    Name  string `csv:"name"`
+//	    Age   int    `csv:"age"`
+//	    Notes string `csv:"-"`
+//	}
+//
+// The fields of nested structs, or pointers to structs, are named by joining
+// the names with a dot, so that the column "address.city" is stored in
+// Address.City; the fields of embedded structs without a tag are named as if
+// they were fields of T.  Strings, integers, floats, bools, time.Time, using
+// the layouts set with TimeLayout, types implementing encoding.TextUnmarshaler,
+// such as *big.Rat for exact decimals, and pointers to them are supported.
+// Empty fields decode to the zero value, which is nil for pointers.
+//
+// Fields whose column is missing from the file are set to the default of the
+// column in Defaults, or else to the default option of their tag, as in
+// `csv:"country,default=US"`.
+//
+// If DisallowUnknownColumns is set on r, columns that are not stored in any
+// field are reported as *FieldErrors wrapping ErrUnknownColumn and no records
+// are read.
+//
+// Like ReadAllToMapsWithErrors, ReadAllInto returns the records that were
+// decoded along with the errors of the others, which are *FieldErrors for
+// fields that could not be converted.  End of file is not treated as an error.
+func ReadAllInto[T any](r *Reader) (records []T, errs []error) {
+	errs = r.readAllInto(reflect.ValueOf(&records).Elem())
+	return records, errs
+}
+
+// ReadAllToStructsWithErrors reads all the remaining records from r into the
+// slice of structs, or of pointers to structs, that v points to, as decoded
+// by ReadAllInto, for callers without a type parameter:
+//
+//	var people []Person
+//	errs := r.ReadAllToStructsWithErrors(&people)
+//
+// Like ReadAllToMapsWithErrors, the slice holds the records that were
+// decoded, and the errors of the others are returned, which are *FieldErrors
+// with the line and the column name of the fields that could not be
+// converted.  End of file is not treated as an error.
+func (r *Reader) ReadAllToStructsWithErrors(v any) (errs []error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return []error{fmt.Errorf("%w %T", ErrUnsupportedType, v)}
+	}
+	rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	return r.readAllInto(rv.Elem())
+}
+
+// readAllInto appends the remaining records of r, decoded as by ReadAllInto,
+// to the slice records, and returns the errors of the records that could not
+// be decoded.
+func (r *Reader) readAllInto(records reflect.Value) (errs []error) {
+	t := records.Type().Elem()
+	structType := t
+	if t.Kind() == reflect.Ptr {
+		structType = t.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return []error{fmt.Errorf("%w %s", ErrUnsupportedType, t)}
+	}
+	if _, err := r.Headers(); err != nil {
+		if err != io.EOF {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+	d := r.newStructDecoder(structType)
+	if r.DisallowUnknownColumns {
+		for i, field := range d.fields {
+			if field == nil {
+				errs = append(errs, &FieldError{Line: 1, Column: i, Name: d.headers[i], Err: ErrUnknownColumn})
+			}
+		}
+		if errs != nil {
+			return errs
+		}
+	}
+	skipLine := r.SkipLineOnErr
+	r.SkipLineOnErr = true
+	defer func() { r.SkipLineOnErr = skipLine }()
+	for {
+		record, _, err := r.read(true)
+		if err == io.EOF {
+			return errs
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		v := reflect.New(structType)
+		if err := d.decode(record, v.Elem(), r.recordLine); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if t.Kind() == reflect.Ptr {
+			records.Set(reflect.Append(records, v))
+		} else {
+			records.Set(reflect.Append(records, v.Elem()))
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type decodePerson struct {
+	Name    string  `csv:"name"`
+	Age     int     `csv:"age"`
+	Score   float64 `csv:"score"`
+	Active  bool
+	Joined  time.Time `csv:"joined"`
+	Manager *string   `csv:"manager"`
+	Visits  *uint16   `csv:"visits"`
+	Ignored string    `csv:"-"`
+	private string
+}
+
+func TestReadAllInto(t *testing.T) {
+	input := `name,age,score,Active,joined,manager,visits,Ignored,extra
+ann,30,1.5,true,06/01/2014,bob,7,x,y
+bob,x,2,false,06/02/2014,,,x,y
+c"at,40,2,false,06/03/2014,,,x,y
+dan,50,,,06/04/2014,ann,70000,x,y
+eve,,,,,,,,
+`
+	r := NewReader(strings.NewReader(input))
+	r.TimeLayout("joined", "01/02/2006")
+	people, errs := ReadAllInto[decodePerson](r)
+
+	manager, visits := "bob", uint16(7)
+	want := []decodePerson{
+		{Name: "ann", Age: 30, Score: 1.5, Active: true, Joined: time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC), Manager: &manager, Visits: &visits},
+		{Name: "eve"},
+	}
+	if !reflect.DeepEqual(people, want) {
+		t.Errorf("people=%+v\nwant %+v", people, want)
+	}
+	wantErrs := []string{
+		`line 3, column 1 (age): strconv.ParseInt: parsing "x": invalid syntax`,
+		`line 4, column 32: bare " in non-quoted-field`,
+		`line 5, column 6 (visits): strconv.ParseUint: parsing "70000": value out of range`,
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if !reflect.DeepEqual(got, wantErrs) {
+		t.Errorf("errors=%q\nwant %q", got, wantErrs)
+	}
+	if r.SkipLineOnErr {
+		t.Errorf("SkipLineOnErr was not restored")
+	}
+}
+
+func TestReadAllIntoPointers(t *testing.T) {
+	r := NewReader(strings.NewReader("name,age\nann,30"))
+	people, errs := ReadAllInto[*decodePerson](r)
+	if errs != nil || len(people) != 1 || people[0].Name != "ann" || people[0].Age != 30 {
+		t.Errorf("people=%v, errs=%v", people, errs)
+	}
+	if _, errs := ReadAllInto[int](NewReader(strings.NewReader("a\n1"))); len(errs) != 1 {
+		t.Errorf("errs=%v, want unsupported type", errs)
+	}
+}
+
+func TestReadAllToStructsWithErrors(t *testing.T) {
+	r := NewReader(strings.NewReader("name,age\nann,30\nbob,x\ncat\ndan,40\n"))
+	people := []decodePerson{{Name: "old"}}
+	errs := r.ReadAllToStructsWithErrors(&people)
+	want := []decodePerson{{Name: "ann", Age: 30}, {Name: "dan", Age: 40}}
+	if !reflect.DeepEqual(people, want) {
+		t.Errorf("people=%+v, want %+v", people, want)
+	}
+	wantErrs := []string{
+		`line 3, column 1 (age): strconv.ParseInt: parsing "x": invalid syntax`,
+		`line 4, column 0: wrong number of fields in line`,
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if !reflect.DeepEqual(got, wantErrs) {
+		t.Errorf("errors=%q\nwant %q", got, wantErrs)
+	}
+
+	var pointers []*decodePerson
+	if errs := NewReader(strings.NewReader("name\nann")).ReadAllToStructsWithErrors(&pointers); errs != nil || len(pointers) != 1 || pointers[0].Name != "ann" {
+		t.Errorf("pointers=%v, errs=%v", pointers, errs)
+	}
+	for _, v := range []any{people, &[]int{}, nil} {
+		if errs := NewReader(strings.NewReader("a\n1")).ReadAllToStructsWithErrors(v); len(errs) != 1 {
+			t.Errorf("%T: errs=%v, want unsupported type", v, errs)
+		}
+	}
+}
+
+type decodeGeo struct {
+	Lat float64 `csv:"lat"`
+	Lng float64 `csv:"lng"`
+}
+
+type decodeAddress struct {
+	City string     `csv:"city"`
+	Geo  *decodeGeo `csv:"geo"`
+}
+
+type decodeBase struct {
+	ID int `csv:"id"`
+}
+
+type decodeCustomer struct {
+	decodeBase
+	Name    string        `csv:"name"`
+	Address decodeAddress `csv:"address"`
+	Next    *decodeCustomer
+}
+
+func TestReadAllIntoNested(t *testing.T) {
+	r := NewReader(strings.NewReader("id,name,address.city,address.geo.lat,address.geo.lng\n1,ann,Paris,48.8,2.3\n2,bob,Lyon,,"))
+	customers, errs := ReadAllInto[decodeCustomer](r)
+	if errs != nil {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	want := []decodeCustomer{
+		{decodeBase: decodeBase{ID: 1}, Name: "ann", Address: decodeAddress{City: "Paris", Geo: &decodeGeo{Lat: 48.8, Lng: 2.3}}},
+		{decodeBase: decodeBase{ID: 2}, Name: "bob", Address: decodeAddress{City: "Lyon", Geo: &decodeGeo{}}},
+	}
+	if !reflect.DeepEqual(customers, want) {
+		t.Errorf("customers=%+v\nwant %+v", customers, want)
+	}
+}
+
+type decodeDefaults struct {
+	Name    string `csv:"name"`
+	Country string `csv:"country,default=US"`
+	Tags    string `csv:",default=a,b"`
+	Limit   *int   `csv:"limit,default=10"`
+	Zone    string `csv:"zone"`
+}
+
+func TestReadAllIntoDefaults(t *testing.T) {
+	r := NewReader(strings.NewReader("name,limit\nann,\nbob,5"))
+	r.Defaults = map[string]string{"zone": "UTC"}
+	records, errs := ReadAllInto[decodeDefaults](r)
+	if errs != nil {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	five := 5
+	want := []decodeDefaults{
+		{Name: "ann", Country: "US", Tags: "a,b", Zone: "UTC"},
+		{Name: "bob", Country: "US", Tags: "a,b", Limit: &five, Zone: "UTC"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%+v\nwant %+v", records, want)
+	}
+
+	r = NewReader(strings.NewReader("name\nann"))
+	r.Defaults = map[string]string{"limit": "many"}
+	if _, errs := ReadAllInto[decodeDefaults](r); len(errs) != 1 ||
+		errs[0].Error() != `line 2, column -1 (limit): strconv.ParseInt: parsing "many": invalid syntax` {
+		t.Errorf("errs=%v", errs)
+	}
+}
+
+func TestReadAllIntoDisallowUnknownColumns(t *testing.T) {
+	r := NewReader(strings.NewReader("name,age,vendor_flag,extra\nann,30,x,y"))
+	r.DisallowUnknownColumns = true
+	records, errs := ReadAllInto[decodePerson](r)
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	want := []string{"line 1, column 2 (vendor_flag): unknown column", "line 1, column 3 (extra): unknown column"}
+	if records != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("records=%v, errs=%q want %q", records, got, want)
+	}
+
+	r = NewReader(strings.NewReader("name,age\nann,30"))
+	r.DisallowUnknownColumns = true
+	if records, errs := ReadAllInto[decodePerson](r); errs != nil || len(records) != 1 {
+		t.Errorf("records=%v, errs=%v", records, errs)
+	}
+}
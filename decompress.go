@@ -0,0 +1,126 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// A decompressor decompresses the streams starting with magic.
+type decompressor struct {
+	name, magic string
+	open        func(io.Reader) (io.Reader, error)
+}
+
+var (
+	decompressorsMu sync.Mutex
+	decompressors   = []decompressor{
+		{"gzip", "\x1f\x8b", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"bzip2", "BZh?\x31\x41\x59\x26\x53\x59", func(r io.Reader) (io.Reader, error) { return bzip2.NewReader(r), nil }},
+	}
+)
+
+// RegisterDecompressor registers a decompressor used by NewReader for the
+// input starting with magic, where "?" matches any byte.  The open function
+// is passed the input from its first byte and returns the decompressed
+// stream.  Gzip and bzip2 are registered by default; others, such as zstd,
+// can be registered with a third-party package:
+//
+//	bettercsv.RegisterDecompressor("zstd", "\x28\xb5\x2f\xfd", func(r io.Reader) (io.Reader, error) {
+//		return zstd.NewReader(r)
+//	})
+func RegisterDecompressor(name, magic string, open func(io.Reader) (io.Reader, error)) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors = append(decompressors, decompressor{name, magic, open})
+}
+
+// detectCompression makes r read the decompressed stream of its input if
+// the input starts with the magic of a registered decompressor.  The
+// compressed input stays buffered in r.compressed, while uncompressed input
+// is read directly.
+func (r *Reader) detectCompression() error {
+	r.detect = false
+	d, name, err := decompress(r.r)
+	if err != nil {
+		r.release()
+		return err
+	}
+	if name == "" {
+		return nil
+	}
+	if r.Logger != nil {
+		r.debug("detected compressed input", "format", name)
+	}
+	r.compressed, r.r = r.r, getBufioReader(d)
+	return nil
+}
+
+// decompressReader decompresses its input according to its magic bytes,
+// which are detected on the first Read.  It is used by Editor, which keeps
+// the decompressed input as it is read.
+type decompressReader struct {
+	r        io.Reader
+	err      error
+	buf      *bufio.Reader // buffered input, from bufioPool
+	reader   *Reader       // Reader logging the detection, if not nil
+	detected bool
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if !d.detected {
+		d.detected = true
+		d.buf = getBufioReader(d.r)
+		var name string
+		d.r, name, d.err = decompress(d.buf)
+		if name != "" && d.reader != nil && d.reader.Logger != nil {
+			d.reader.debug("detected compressed input", "format", name)
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	n, err := d.r.Read(p)
+	if err == io.EOF && d.buf != nil {
+		putBufioReader(d.buf)
+		d.buf = nil
+		d.err = io.EOF
+	}
+	return n, err
+}
+
+// decompress returns the decompressed stream of br and the name of its
+// decompressor, or br itself and "" if it does not start with the magic of a
+// registered decompressor.
+func decompress(br *bufio.Reader) (io.Reader, string, error) {
+	decompressorsMu.Lock()
+	registered := decompressors
+	decompressorsMu.Unlock()
+	for _, d := range registered {
+		b, _ := br.Peek(len(d.magic))
+		if matchMagic(d.magic, b) {
+			r, err := d.open(br)
+			return r, d.name, err
+		}
+	}
+	return br, "", nil
+}
+
+// matchMagic reports whether b matches magic, where "?" matches any byte.
+func matchMagic(magic string, b []byte) bool {
+	if len(magic) != len(b) {
+		return false
+	}
+	for i, c := range b {
+		if magic[i] != c && magic[i] != '?' {
+			return false
+		}
+	}
+	return true
+}
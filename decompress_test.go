@@ -0,0 +1,73 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	RegisterDecompressor("base64", "B64?:", func(r io.Reader) (io.Reader, error) {
+		if _, err := io.ReadFull(r, make([]byte, 5)); err != nil {
+			return nil, err
+		}
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	})
+
+	input := "a,b\n1,2\n"
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(input))
+	zw.Close()
+	want := [][]string{{"a", "b"}, {"1", "2"}}
+
+	tests := []struct {
+		Name  string
+		Input string
+	}{
+		{"Plain", input},
+		{"Gzip", gz.String()},
+		{"Registered", "B64v:" + base64.StdEncoding.EncodeToString([]byte(input))},
+		{"Short", "a,b\n1,2"},
+	}
+	for _, tt := range tests {
+		records, err := NewReader(strings.NewReader(tt.Input)).ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if !reflect.DeepEqual(records, want) {
+			t.Errorf("%s: records=%q want %q", tt.Name, records, want)
+		}
+	}
+
+	if _, err := NewReader(strings.NewReader("\x1f\x8bnot gzip")).Read(); err == nil {
+		t.Errorf("expected error for corrupt gzip")
+	}
+}
+
+func TestDecompressBuffers(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("a\n1\n"))
+	zw.Close()
+
+	// Uncompressed input is buffered once, compressed input once more.
+	r := NewReader(strings.NewReader("a\n1\n"))
+	if _, err := r.Read(); err != nil || r.compressed != nil {
+		t.Errorf("plain input: err=%v, compressed buffer %v", err, r.compressed != nil)
+	}
+	r = NewReader(&gz)
+	if _, err := r.Read(); err != nil || r.compressed == nil {
+		t.Errorf("gzip input: err=%v, compressed buffer %v", err, r.compressed != nil)
+	}
+	if _, err := r.ReadAll(); err != nil || r.r != nil || r.compressed != nil {
+		t.Errorf("buffers not released at the end of the input, err=%v", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A DedupePolicy selects the record kept among duplicates.
+type DedupePolicy int
+
+const (
+	KeepFirst DedupePolicy = iota // keep the first record of each key
+	KeepLast                      // keep the last record of each key
+)
+
+// A Deduper removes duplicate records, which have the same fields in the key
+// Columns, or in every column if Columns is empty.
+//
+// By default the keys are held in memory, as well as the records with
+// KeepLast.  If RunSize is positive, the records are sorted by key with a
+// Sorter spilling runs of RunSize records to TempDir instead, and are written
+// in key order.
+type Deduper struct {
+	Columns []string     // key columns; all columns if empty
+	Policy  DedupePolicy // record kept among duplicates
+	RunSize int          // records sorted in memory to dedupe on disk; 0 to dedupe in memory
+	TempDir string       // directory of the temporary files
+}
+
+// A DedupeReport counts the records of a Dedupe.
+type DedupeReport struct {
+	Records    int // records read, not counting the header row
+	Kept       int // records written
+	Duplicates int // records dropped
+}
+
+// Dedupe reads the header row and the records of r, which must be at its
+// start, and writes the header row and the records that are not duplicates
+// to w.  It returns an error wrapping ErrUnknownColumn for key columns that
+// are not in the header row.  Records with errors are skipped if r has
+// SkipLineOnErr set.
+func (d *Deduper) Dedupe(r *Reader, w *Writer) (*DedupeReport, error) {
+	if d.RunSize > 0 {
+		return d.dedupeSorted(r, w)
+	}
+	headers, err := r.readNew()
+	if err == io.EOF {
+		return &DedupeReport{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns, err := d.keyColumns(headers)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.writeHeader(headers); err != nil {
+		return nil, err
+	}
+
+	report := &DedupeReport{}
+	seen := make(map[string]int) // index in records of the last record by key
+	var records [][]string
+	for {
+		record, err := r.readNew()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return nil, err
+		}
+		report.Records++
+		key := dedupeKey(record, columns)
+		i, dup := seen[key]
+		switch {
+		case d.Policy == KeepLast && dup:
+			records[i] = nil
+			fallthrough
+		case d.Policy == KeepLast:
+			seen[key] = len(records)
+			records = append(records, record)
+		case !dup:
+			seen[key] = 0
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, record := range records {
+		if record != nil {
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+	report.Kept = len(seen)
+	report.Duplicates = report.Records - report.Kept
+	w.Flush()
+	return report, w.Error()
+}
+
+// dedupeSorted sorts the records of r by key in a temporary file and writes
+// one record of each group of duplicates to w.
+func (d *Deduper) dedupeSorted(r *Reader, w *Writer) (*DedupeReport, error) {
+	f, err := os.CreateTemp(d.TempDir, "bettercsv-dedupe-*.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	var keys []SortKey
+	for _, c := range d.Columns {
+		keys = append(keys, SortKey{Column: c})
+	}
+	s := &Sorter{Keys: keys, RunSize: d.RunSize, TempDir: d.TempDir}
+	if err := s.Sort(r, NewWriter(f)); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	sorted := NewReader(f)
+	sorted.FieldsPerRecord = -1
+	headers, err := sorted.readNew()
+	if err == io.EOF {
+		return &DedupeReport{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns, err := d.keyColumns(headers)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.writeHeader(headers); err != nil {
+		return nil, err
+	}
+	report := &DedupeReport{}
+	var last []string
+	var lastKey string
+	for {
+		record, err := sorted.readNew()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Records++
+		key := dedupeKey(record, columns)
+		if last != nil && key != lastKey {
+			if err := w.Write(last); err != nil {
+				return nil, err
+			}
+			report.Kept++
+			last = nil
+		}
+		if last == nil || d.Policy == KeepLast {
+			last, lastKey = record, key
+		}
+	}
+	if last != nil {
+		if err := w.Write(last); err != nil {
+			return nil, err
+		}
+		report.Kept++
+	}
+	report.Duplicates = report.Records - report.Kept
+	w.Flush()
+	return report, w.Error()
+}
+
+// keyColumns returns the indexes of the key columns in headers.
+func (d *Deduper) keyColumns(headers []string) ([]int, error) {
+	if len(d.Columns) == 0 {
+		return nil, nil
+	}
+	var keys []SortKey
+	for _, c := range d.Columns {
+		keys = append(keys, SortKey{Column: c})
+	}
+	sortColumns, err := sortColumns(headers, keys)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]int, len(sortColumns))
+	for i, c := range sortColumns {
+		columns[i] = c.index
+	}
+	return columns, nil
+}
+
+// dedupeKey returns the key of record, made of the fields of columns or of
+// every field if columns is nil.
+func dedupeKey(record []string, columns []int) string {
+	var b strings.Builder
+	add := func(field string) {
+		b.WriteString(strconv.Itoa(len(field)))
+		b.WriteByte(':')
+		b.WriteString(field)
+	}
+	if columns == nil {
+		for _, field := range record {
+			add(field)
+		}
+	}
+	for _, c := range columns {
+		if c < len(record) {
+			add(record[c])
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const dedupeInput = `email,name,day
+b@x.com,bob,1
+a@x.com,ann,1
+b@x.com,bobby,2
+c@x.com,cy,2
+a@x.com,ann,1
+`
+
+func TestDeduper(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Deduper Deduper
+		Output  string
+		Report  DedupeReport
+	}{
+		{
+			Name:    "FirstByColumn",
+			Deduper: Deduper{Columns: []string{"email"}},
+			Output:  "email,name,day\nb@x.com,bob,1\na@x.com,ann,1\nc@x.com,cy,2\n",
+			Report:  DedupeReport{5, 3, 2},
+		},
+		{
+			Name:    "LastByColumn",
+			Deduper: Deduper{Columns: []string{"email"}, Policy: KeepLast},
+			Output:  "email,name,day\nb@x.com,bobby,2\nc@x.com,cy,2\na@x.com,ann,1\n",
+			Report:  DedupeReport{5, 3, 2},
+		},
+		{
+			Name:    "AllColumns",
+			Deduper: Deduper{},
+			Output:  "email,name,day\nb@x.com,bob,1\na@x.com,ann,1\nb@x.com,bobby,2\nc@x.com,cy,2\n",
+			Report:  DedupeReport{5, 4, 1},
+		},
+		{
+			Name:    "SortedFirst",
+			Deduper: Deduper{Columns: []string{"email"}, RunSize: 2},
+			Output:  "email,name,day\na@x.com,ann,1\nb@x.com,bob,1\nc@x.com,cy,2\n",
+			Report:  DedupeReport{5, 3, 2},
+		},
+		{
+			Name:    "SortedLast",
+			Deduper: Deduper{Columns: []string{"email"}, Policy: KeepLast, RunSize: 2},
+			Output:  "email,name,day\na@x.com,ann,1\nb@x.com,bobby,2\nc@x.com,cy,2\n",
+			Report:  DedupeReport{5, 3, 2},
+		},
+		{
+			Name:    "SortedAllColumns",
+			Deduper: Deduper{RunSize: 2},
+			Output:  "email,name,day\na@x.com,ann,1\nb@x.com,bob,1\nb@x.com,bobby,2\nc@x.com,cy,2\n",
+			Report:  DedupeReport{5, 4, 1},
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		tt.Deduper.TempDir = t.TempDir()
+		report, err := tt.Deduper.Dedupe(NewReader(strings.NewReader(dedupeInput)), NewWriter(&b))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.Name, err)
+		}
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+		if *report != tt.Report {
+			t.Errorf("%s: report=%+v want %+v", tt.Name, *report, tt.Report)
+		}
+	}
+
+	d := &Deduper{Columns: []string{"phone"}}
+	if _, err := d.Dedupe(NewReader(strings.NewReader(dedupeInput)), NewWriter(&bytes.Buffer{})); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("error %v, want ErrUnknownColumn", err)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// A ChangeType is the kind of a Change.
+type ChangeType int
+
+const (
+	Added   ChangeType = iota // record only in the new input
+	Removed                   // record only in the old input
+	Changed                   // record in both inputs with different fields
+)
+
+// String returns the name of the change type.
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(t))
+}
+
+// A Change is a difference between two CSV inputs found by Diff.
+type Change struct {
+	Type    ChangeType
+	Key     []string          // fields of the key columns
+	Old     map[string]string // record of the old input; nil if Added
+	New     map[string]string // record of the new input; nil if Removed
+	Columns []string          // columns whose fields differ if Changed
+}
+
+// diffRecord is a record of the old input.
+type diffRecord struct {
+	key    []string
+	record map[string]string
+	seen   bool // the key is in the new input
+}
+
+// Diff compares the records of the old and new inputs, which must be at
+// their start, matching them by the fields of the key columns.  It returns
+// the records added and changed, in the order of the new input, followed by
+// the records removed, in the order of the old input.  Columns missing from
+// one input compare as empty fields.
+//
+// The old input is held in memory.  Key columns missing from the header row
+// of an input are an error wrapping ErrUnknownColumn, and keys repeated in an
+// input are a *FieldError wrapping ErrDuplicate.  Records with errors are
+// skipped if the Reader has SkipLineOnErr set.
+func Diff(old, new *Reader, keys ...string) ([]*Change, error) {
+	oldHeaders, err := diffHeaders(old, keys)
+	if err != nil {
+		return nil, err
+	}
+	newHeaders, err := diffHeaders(new, keys)
+	if err != nil {
+		return nil, err
+	}
+	columns := slices.Clone(newHeaders)
+	for _, h := range oldHeaders {
+		if !slices.Contains(columns, h) {
+			columns = append(columns, h)
+		}
+	}
+
+	var order []*diffRecord
+	byKey := make(map[string]*diffRecord)
+	err = eachDiffRecord(old, keys, func(key []string, record map[string]string) error {
+		k := dedupeKey(key, nil)
+		if byKey[k] != nil {
+			return duplicateKey(old, keys)
+		}
+		d := &diffRecord{key: key, record: record}
+		byKey[k] = d
+		order = append(order, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*Change
+	seen := make(map[string]bool)
+	err = eachDiffRecord(new, keys, func(key []string, record map[string]string) error {
+		k := dedupeKey(key, nil)
+		if seen[k] {
+			return duplicateKey(new, keys)
+		}
+		seen[k] = true
+		d := byKey[k]
+		if d == nil {
+			changes = append(changes, &Change{Type: Added, Key: key, New: record})
+			return nil
+		}
+		d.seen = true
+		var changed []string
+		for _, c := range columns {
+			if d.record[c] != record[c] {
+				changed = append(changed, c)
+			}
+		}
+		if changed != nil {
+			changes = append(changes, &Change{Type: Changed, Key: key, Old: d.record, New: record, Columns: changed})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range order {
+		if !d.seen {
+			changes = append(changes, &Change{Type: Removed, Key: d.key, Old: d.record})
+		}
+	}
+	return changes, nil
+}
+
+// diffHeaders reads the header row of r and checks it has the key columns.
+func diffHeaders(r *Reader, keys []string) ([]string, error) {
+	if _, err := r.Headers(); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	headers := r.outputHeaders()
+	for _, k := range keys {
+		if !slices.Contains(headers, k) {
+			return nil, fmt.Errorf("%w %q", ErrUnknownColumn, k)
+		}
+	}
+	return headers, nil
+}
+
+// eachDiffRecord calls fn with the key and the fields of each record of r.
+func eachDiffRecord(r *Reader, keys []string, fn func(key []string, record map[string]string) error) error {
+	for {
+		record, err := r.ReadToMap()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return err
+		}
+		key := make([]string, len(keys))
+		for i, k := range keys {
+			key[i] = record[k]
+		}
+		if err := fn(key, record); err != nil {
+			return err
+		}
+	}
+}
+
+// duplicateKey returns the error for a repeated key in the last record read
+// by r.
+func duplicateKey(r *Reader, keys []string) error {
+	name := ""
+	if len(keys) > 0 {
+		name = keys[0]
+	}
+	return &FieldError{Line: r.recordLine, Column: slices.Index(r.outputHeaders(), name), Name: name, Err: ErrDuplicate}
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := NewReader(strings.NewReader("id,name,city\n1,ann,Paris\n2,bob,Lyon\n3,cy,Nice\n"))
+	new := NewReader(strings.NewReader("id,name,zip\n3,cy,\n1,anne,75001\n4,dan,\n"))
+	changes, err := Diff(old, new, "id")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	type change struct {
+		Type    ChangeType
+		Key     string
+		Columns []string
+	}
+	var got []change
+	for _, c := range changes {
+		got = append(got, change{c.Type, strings.Join(c.Key, ","), c.Columns})
+	}
+	want := []change{
+		{Changed, "3", []string{"city"}},
+		{Changed, "1", []string{"name", "zip", "city"}},
+		{Added, "4", nil},
+		{Removed, "2", nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes=%v want %v", got, want)
+	}
+	if changes[1].Old["name"] != "ann" || changes[1].New["name"] != "anne" || changes[2].Old != nil || changes[3].New != nil {
+		t.Errorf("changes=%+v", changes)
+	}
+	if Changed.String() != "changed" {
+		t.Errorf("String()=%q", Changed.String())
+	}
+
+	_, err = Diff(NewReader(strings.NewReader("id\n1\n1\n")), NewReader(strings.NewReader("id\n")), "id")
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Line != 3 || !errors.Is(err, ErrDuplicate) {
+		t.Errorf("error %v, want duplicate id on line 3", err)
+	}
+	_, err = Diff(NewReader(strings.NewReader("id\n")), NewReader(strings.NewReader("key\n")), "id")
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("error %v, want ErrUnknownColumn", err)
+	}
+}
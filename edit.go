@@ -0,0 +1,160 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"slices"
+)
+
+// An Editor copies a CSV file to its output byte for byte, except for the
+// records edited by the caller, to change a few cells of a large file
+// without reformatting the rest of it:
+//
+//	e := bettercsv.NewEditor(in, out)
+//	for {
+//		rec, err := e.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		if rec.Get("id") == "42" {
+//			rec.Fields[2] = "shipped"
+//		}
+//	}
+//	err := e.Close()
+//
+// Records whose Fields are unchanged are copied as they were read, with their
+// quoting, line ending and trailing delimiters, as are the header row,
+// comment lines, blank lines and the records skipped by Filter, FilterMap or
+// SkipLineOnErr.  Edited records are written with their original quoting and
+// line ending, as by WriteRecord.  The options of Reader, such as Comma and
+// Comment, can be set before the first call to Next; it must not select,
+// map or transform columns.
+type Editor struct {
+	Reader *Reader // reads the input
+
+	raw     *rawRecorder
+	w       *bufio.Writer
+	buf     bytes.Buffer // encoding of an edited record
+	rec     *Record      // record returned by Next
+	fields  []string     // fields of rec as read
+	start   int64        // offset where rec started
+	end     int64        // offset where rec ended
+	pending bool         // rec is not written yet
+}
+
+// NewEditor returns an Editor copying src to dst.  Compressed input is
+// decompressed, as by NewReader.
+func NewEditor(src io.Reader, dst io.Writer) *Editor {
+	r := &Reader{Comma: ','}
+	raw := &rawRecorder{r: &decompressReader{r: src, reader: r}}
+	r.r = getBufioReader(raw)
+	return &Editor{Reader: r, raw: raw, w: bufio.NewWriter(dst)}
+}
+
+// Next writes the record returned by the previous call, edited or not, and
+// reads the next record, which can be edited through its Fields until the
+// next call to Next or Close.  At the end of the input, Next returns io.EOF.
+func (e *Editor) Next() (*Record, error) {
+	if err := e.writePending(); err != nil {
+		return nil, err
+	}
+	rec, err := e.Reader.ReadRecord()
+	if err != nil {
+		return nil, err
+	}
+	e.rec, e.fields = rec, slices.Clone(rec.Fields)
+	e.start, e.end = e.Reader.recordPos, e.Reader.offset
+	e.pending = true
+	return rec, nil
+}
+
+// Close writes the record returned by the last call to Next and the rest of
+// the input, and flushes the output.
+func (e *Editor) Close() error {
+	if err := e.writePending(); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(e.raw.next(e.raw.base + int64(len(e.raw.buf)))); err != nil {
+		return err
+	}
+	if _, err := io.Copy(e.w, e.raw.r); err != nil {
+		return err
+	}
+	e.Reader.release()
+	return e.w.Flush()
+}
+
+// writePending writes the input preceding the record returned by Next and
+// the record, as read if it was not edited.
+func (e *Editor) writePending() error {
+	if !e.pending {
+		return nil
+	}
+	e.pending = false
+	if _, err := e.w.Write(e.raw.next(e.start)); err != nil {
+		return err
+	}
+	raw := e.raw.next(e.end)
+	if slices.Equal(e.rec.Fields, e.fields) {
+		_, err := e.w.Write(raw)
+		return err
+	}
+	e.buf.Reset()
+	w := NewWriter(&e.buf)
+	w.Comma, w.Comment = e.Reader.Comma, e.Reader.Comment
+	if err := w.WriteRecord(e.rec); err != nil {
+		return err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	encoded := bytes.TrimSuffix(e.buf.Bytes(), []byte("\n"))
+	if _, err := e.w.Write(encoded); err != nil {
+		return err
+	}
+	_, err := e.w.Write(lineEnd(raw))
+	return err
+}
+
+// lineEnd returns the line ending of the record raw, "" if it has none.
+func lineEnd(raw []byte) []byte {
+	switch {
+	case bytes.HasSuffix(raw, []byte("\r\n")):
+		return raw[len(raw)-2:]
+	case bytes.HasSuffix(raw, []byte("\n")):
+		return raw[len(raw)-1:]
+	}
+	return nil
+}
+
+// A rawRecorder keeps the input read through it from offset base, until
+// it is discarded.
+type rawRecorder struct {
+	r    io.Reader
+	buf  []byte
+	base int64
+}
+
+func (t *rawRecorder) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	t.buf = append(t.buf, p[:n]...)
+	return n, err
+}
+
+// next returns the input kept up to offset end and stops keeping it.
+func (t *rawRecorder) next(end int64) []byte {
+	n := end - t.base
+	b := t.buf[:n]
+	t.buf = t.buf[n:]
+	t.base = end
+	return b
+}
@@ -0,0 +1,104 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+var editorTests = []struct {
+	Name    string
+	Input   string
+	Comment rune
+	Limit   int
+	Edit    map[string]string // new status by id
+	Output  string
+}{
+	{
+		Name:   "Untouched",
+		Input:  "id,status\r\n\"1\",open,\r\n\n2,  closed ,\"\"\r\n3,\"multi\nline\"",
+		Output: "id,status\r\n\"1\",open,\r\n\n2,  closed ,\"\"\r\n3,\"multi\nline\"",
+	},
+	{
+		Name:    "Comments",
+		Input:   "id,status\n# export\n1,open\n# note\n2,open\n# end\n",
+		Comment: '#',
+		Edit:    map[string]string{"2": "closed"},
+		Output:  "id,status\n# export\n1,open\n# note\n2,closed\n# end\n",
+	},
+	{
+		Name:   "QuotingAndLineEnds",
+		Input:  "id,status,note\r\n\"1\",\"open\",\r\n2,open,x\r\n3,open,",
+		Edit:   map[string]string{"1": "on hold", "3": "a,b"},
+		Output: "id,status,note\r\n\"1\",\"on hold\",\r\n2,open,x\r\n3,\"a,b\",",
+	},
+	{
+		Name:   "Limit",
+		Input:  "id,status\n1,open\n2,open\n3,open\n",
+		Limit:  1,
+		Edit:   map[string]string{"1": "closed", "2": "closed"},
+		Output: "id,status\n1,closed\n2,open\n3,open\n",
+	},
+}
+
+func TestEditor(t *testing.T) {
+	for _, tt := range editorTests {
+		b := &bytes.Buffer{}
+		e := NewEditor(strings.NewReader(tt.Input), b)
+		e.Reader.Comment = tt.Comment
+		e.Reader.Limit = tt.Limit
+		e.Reader.FieldsPerRecord = -1
+		for {
+			rec, err := e.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("%s: %v", tt.Name, err)
+				break
+			}
+			if status, ok := tt.Edit[rec.Get("id")]; ok {
+				rec.Fields[1] = status
+			}
+		}
+		if err := e.Close(); err != nil {
+			t.Errorf("%s: %v", tt.Name, err)
+		}
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+	}
+}
+
+func TestEditorLarge(t *testing.T) {
+	var in strings.Builder
+	in.WriteString("id,status\n")
+	for i := 0; i < 20000; i++ {
+		in.WriteString("1,\"open\"\r\n")
+	}
+	b := &bytes.Buffer{}
+	e := NewEditor(strings.NewReader(in.String()), b)
+	n := 0
+	for {
+		rec, err := e.Next()
+		if err != nil {
+			break
+		}
+		if n++; n == 10000 {
+			rec.Fields[1] = "closed"
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(in.String(), "1,\"open\"\r\n", "1,\"closed\"\r\n", 10000)
+	want = strings.Replace(want, "1,\"closed\"\r\n", "1,\"open\"\r\n", 9999)
+	if b.String() != want {
+		t.Errorf("output differs")
+	}
+}
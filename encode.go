@@ -0,0 +1,133 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// StructHeaders returns the column names of the struct v, or of the struct v
+// points to, in field order.  The names follow the rules of ReadAllInto, so
+// that nested structs are flattened into names such as "address.city".
+func StructHeaders(v interface{}) ([]string, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w %v", ErrUnsupportedType, t)
+	}
+	fields := fieldsOf(t)
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.name
+	}
+	return headers, nil
+}
+
+// WriteStruct writes the fields of the struct v, or of the struct v points
+// to, as a single record in the order of StructHeaders, or of Headers when
+// they are set.  Headers naming no field are an error wrapping
+// ErrUnknownColumn.  Nil pointers are written as NullValue, times are
+// formatted with time.RFC3339Nano, keeping their fractional seconds, and
+// types implementing encoding.TextMarshaler are formatted with MarshalText.
+func (w *Writer) WriteStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return fmt.Errorf("%w %v", ErrUnsupportedType, v)
+	}
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w %v", ErrUnsupportedType, rv.Type())
+	}
+	if !rv.CanAddr() {
+		// Copy v so that methods with pointer receivers can be called.
+		c := reflect.New(rv.Type()).Elem()
+		c.Set(rv)
+		rv = c
+	}
+	fields := fieldsOf(rv.Type())
+	headers := w.Headers
+	if headers != nil {
+		byName := make(map[string]structField, len(fields))
+		for _, f := range fields {
+			byName[f.name] = f
+		}
+		selected := make([]structField, len(headers))
+		for i, name := range headers {
+			f, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("%w %q", ErrUnknownColumn, name)
+			}
+			selected[i] = f
+		}
+		fields = selected
+	} else {
+		headers = make([]string, len(fields))
+		for i, f := range fields {
+			headers[i] = f.name
+		}
+	}
+	if w.AutoHeader && !w.started {
+		if err := w.writeHeader(headers); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(fields))
+	nulls := make([]bool, len(fields))
+	for i, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index, false)
+		if !ok || fv.Kind() == reflect.Ptr && fv.IsNil() {
+			record[i], nulls[i] = w.NullValue, true
+			continue
+		}
+		s, err := formatValue(fv)
+		if err != nil {
+			return err
+		}
+		record[i] = s
+	}
+	return w.writeRecord(record, headers, nulls)
+}
+
+// formatValue returns v as a field.  Nil pointers are empty.
+func formatValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		text, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	}
+	return "", fmt.Errorf("%w %s", ErrUnsupportedType, v.Type())
+}
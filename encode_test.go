@@ -0,0 +1,158 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteStruct(t *testing.T) {
+	headers, err := StructHeaders(decodeCustomer{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	wantHeaders := []string{"id", "name", "address.city", "address.geo.lat", "address.geo.lng"}
+	if !reflect.DeepEqual(headers, wantHeaders) {
+		t.Errorf("headers=%q want %q", headers, wantHeaders)
+	}
+
+	customers := []decodeCustomer{
+		{decodeBase: decodeBase{ID: 1}, Name: "ann", Address: decodeAddress{City: "Paris", Geo: &decodeGeo{Lat: 48.8, Lng: 2.3}}},
+		{decodeBase: decodeBase{ID: 2}, Name: "bob, jr", Address: decodeAddress{City: "Lyon"}},
+	}
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Write(headers)
+	for i := range customers {
+		if err := w.WriteStruct(&customers[i]); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	w.Flush()
+	want := "id,name,address.city,address.geo.lat,address.geo.lng\n1,ann,Paris,48.8,2.3\n2,\"bob, jr\",Lyon,\"\",\"\"\n"
+	if b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+
+	decoded, errs := ReadAllInto[decodeCustomer](NewReader(strings.NewReader(b.String())))
+	if errs != nil || len(decoded) != 2 || decoded[0].Address.Geo.Lat != 48.8 || decoded[1].Name != "bob, jr" {
+		t.Errorf("round trip=%+v, %v", decoded, errs)
+	}
+}
+
+func TestWriteStructHeaders(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Headers = []string{"address.city", "id"}
+	w.AutoHeader = true
+	customer := decodeCustomer{decodeBase: decodeBase{ID: 1}, Name: "ann", Address: decodeAddress{City: "Paris"}}
+	if err := w.WriteStruct(customer); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.Flush()
+	if want := "address.city,id\nParis,1\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+
+	w.Headers = []string{"id", "email"}
+	if err := w.WriteStruct(customer); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("error %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestWriteStructValues(t *testing.T) {
+	type values struct {
+		S  string
+		I  int8
+		U  uint
+		F  float32
+		B  bool
+		T  time.Time
+		P  *int
+		NP *int
+	}
+	p := 5
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	v := values{"s", -1, 2, 0.5, true, time.Date(2014, 6, 1, 10, 0, 0, 500000000, time.UTC), &p, nil}
+	if err := w.WriteStruct(v); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.Flush()
+	if want := "s,-1,2,0.5,true,2014-06-01T10:00:00.5Z,5,\"\"\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+	if err := w.WriteStruct(1); err == nil {
+		t.Errorf("expected error for non-struct")
+	}
+	for _, nilValue := range []interface{}{nil, (*values)(nil)} {
+		if err := w.WriteStruct(nilValue); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("WriteStruct(%#v): error %v, want ErrUnsupportedType", nilValue, err)
+		}
+	}
+
+	b.Reset()
+	w.NullValue = "NULL"
+	if err := w.WriteStruct(v); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.Flush()
+	if want := "s,-1,2,0.5,true,2014-06-01T10:00:00.5Z,5,NULL\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
+
+// textID is an ID written as "ID-<n>".
+type textID int
+
+func (id textID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("ID-%d", int(id))), nil
+}
+
+func (id *textID) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "ID-%d", (*int)(id))
+	if err != nil {
+		return errors.New("bad ID " + string(text))
+	}
+	return nil
+}
+
+type textRecord struct {
+	ID     textID   `csv:"id"`
+	Amount big.Rat  `csv:"amount"`
+	Parent *textID  `csv:"parent"`
+	Tax    *big.Rat `csv:"tax"`
+}
+
+func TestTextMarshalers(t *testing.T) {
+	r := NewReader(strings.NewReader("id,amount,parent,tax\nID-1,19.99,ID-7,\nID-2,1/3,,0.5\nX,1,,"))
+	records, errs := ReadAllInto[textRecord](r)
+	if len(errs) != 1 || errs[0].Error() != "line 4, column 0 (id): bad ID X" {
+		t.Errorf("errs=%v", errs)
+	}
+	if len(records) != 2 || records[0].ID != 1 || *records[0].Parent != 7 || records[0].Tax != nil ||
+		records[0].Amount.Cmp(big.NewRat(1999, 100)) != 0 || records[1].Tax.Cmp(big.NewRat(1, 2)) != 0 {
+		t.Fatalf("records=%+v", records)
+	}
+
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	for _, record := range records {
+		if err := w.WriteStruct(record); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	w.Flush()
+	if want := "ID-1,1999/100,ID-7,\"\"\nID-2,1/3,\"\",1/2\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
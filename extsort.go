@@ -0,0 +1,297 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"cmp"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A SortKey is a column records are sorted by.  Fields are compared as
+// values of Type: TypeInt and TypeFloat as numbers, TypeBool as booleans with
+// false first, TypeDate as times parsed with Layouts (time.RFC3339 if there
+// are none) and other types as strings.  Fields that do not parse as Type
+// sort after those that do, as strings.
+type SortKey struct {
+	Column     string     // name of the column
+	Type       ColumnType // type the fields are compared as
+	Layouts    []string   // layouts of TypeDate fields
+	Descending bool       // sort in descending order
+}
+
+// DefaultRunSize is the number of records a Sorter sorts in memory when its
+// RunSize is 0.
+const DefaultRunSize = 100000
+
+// A Sorter sorts CSV records by one or more columns.  Records are sorted in
+// memory in runs of RunSize records; when there are more, the sorted runs are
+// spilled to temporary files in TempDir, or os.TempDir if it is empty, and
+// merged, so that files larger than memory can be sorted.  The sort is
+// stable.
+type Sorter struct {
+	Keys    []SortKey // columns to sort by, in order of precedence; every column as strings if empty
+	RunSize int       // records sorted in memory (DefaultRunSize if 0)
+	TempDir string    // directory of the temporary files
+}
+
+// sortColumn is a SortKey resolved to its column index.
+type sortColumn struct {
+	SortKey
+	index int
+}
+
+// Sort reads the header row and the records of r, which must be at its start,
+// and writes them to w sorted by Keys, the header row first.  It returns an
+// error wrapping ErrUnknownColumn for keys naming columns that are not in the
+// header row.  Records with errors are skipped if r has SkipLineOnErr set.
+func (s *Sorter) Sort(r *Reader, w *Writer) error {
+	headers, err := r.readNew()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns, err := sortColumns(headers, s.Keys)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		for i, h := range headers {
+			columns = append(columns, sortColumn{SortKey{Column: h}, i})
+		}
+	}
+	runSize := s.RunSize
+	if runSize <= 0 {
+		runSize = DefaultRunSize
+	}
+
+	var runs []*os.File
+	defer func() {
+		for _, f := range runs {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	var records [][]string
+	for {
+		record, err := r.readNew()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return err
+		}
+		records = append(records, record)
+		if len(records) == runSize {
+			f, err := s.spill(records, columns)
+			if f != nil {
+				runs = append(runs, f)
+			}
+			if err != nil {
+				return err
+			}
+			records = records[:0]
+		}
+	}
+	slices.SortStableFunc(records, func(a, b []string) int { return compareRecords(a, b, columns) })
+
+	if err := w.writeHeader(headers); err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		for _, record := range records {
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+	} else if err := mergeRuns(runs, records, columns, w); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// spill sorts records and writes them to a temporary file, which is returned
+// rewound.
+func (s *Sorter) spill(records [][]string, columns []sortColumn) (*os.File, error) {
+	slices.SortStableFunc(records, func(a, b []string) int { return compareRecords(a, b, columns) })
+	f, err := os.CreateTemp(s.TempDir, "bettercsv-sort-*.csv")
+	if err != nil {
+		return nil, err
+	}
+	w := NewWriter(f)
+	if err := w.WriteAll(records); err != nil {
+		return f, err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	return f, err
+}
+
+// mergeRuns merges the sorted runs and the sorted records in memory, which
+// follow them, into w.
+func mergeRuns(runs []*os.File, records [][]string, columns []sortColumn, w *Writer) error {
+	h := &mergeHeap{columns: columns}
+	next := make([]func() ([]string, error), 0, len(runs)+1)
+	for _, f := range runs {
+		// The runs are read back as written: not decompressed, and with the
+		// blank lines of empty records.
+		r := newPlainReader(f)
+		r.FieldsPerRecord = -1
+		r.BlankLines = EmptyBlankLines
+		next = append(next, r.Read)
+	}
+	next = append(next, func() ([]string, error) {
+		if len(records) == 0 {
+			return nil, io.EOF
+		}
+		record := records[0]
+		records = records[1:]
+		return record, nil
+	})
+	for i, read := range next {
+		record, err := read()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		h.items = append(h.items, mergeItem{record, i})
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		item := h.items[0]
+		if err := w.Write(item.record); err != nil {
+			return err
+		}
+		record, err := next[item.run]()
+		if err == io.EOF {
+			heap.Pop(h)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		h.items[0].record = record
+		heap.Fix(h, 0)
+	}
+	return nil
+}
+
+// mergeItem is the next record of a run.
+type mergeItem struct {
+	record []string
+	run    int
+}
+
+// mergeHeap orders the next records of the runs, breaking ties by run so
+// that the merge is stable.
+type mergeHeap struct {
+	items   []mergeItem
+	columns []sortColumn
+}
+
+func (h *mergeHeap) Len() int      { return len(h.items) }
+func (h *mergeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *mergeHeap) Push(x any)    { h.items = append(h.items, x.(mergeItem)) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	if c := compareRecords(h.items[i].record, h.items[j].record, h.columns); c != 0 {
+		return c < 0
+	}
+	return h.items[i].run < h.items[j].run
+}
+
+func (h *mergeHeap) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}
+
+// sortColumns resolves keys to the columns of headers.
+func sortColumns(headers []string, keys []SortKey) ([]sortColumn, error) {
+	columns := make([]sortColumn, len(keys))
+	for i, key := range keys {
+		index := slices.Index(headers, key.Column)
+		if index < 0 {
+			return nil, fmt.Errorf("%w %q", ErrUnknownColumn, key.Column)
+		}
+		columns[i] = sortColumn{key, index}
+	}
+	return columns, nil
+}
+
+// compareRecords compares records a and b by columns.
+func compareRecords(a, b []string, columns []sortColumn) int {
+	for _, c := range columns {
+		var fa, fb string
+		if c.index < len(a) {
+			fa = a[c.index]
+		}
+		if c.index < len(b) {
+			fb = b[c.index]
+		}
+		n := compareFields(fa, fb, c.SortKey)
+		if c.Descending {
+			n = -n
+		}
+		if n != 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// compareFields compares a and b as values of the type of key.
+func compareFields(a, b string, key SortKey) int {
+	switch key.Type {
+	case TypeInt, TypeFloat:
+		return compareParsed(a, b, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) }, cmp.Compare[float64])
+	case TypeBool:
+		return compareParsed(a, b, strconv.ParseBool, func(x, y bool) int {
+			return cmp.Compare(boolRank(x), boolRank(y))
+		})
+	case TypeDate:
+		return compareParsed(a, b, func(s string) (time.Time, error) {
+			return parseTime(s, key.Layouts)
+		}, time.Time.Compare)
+	}
+	return strings.Compare(a, b)
+}
+
+// compareParsed compares a and b with compare if both parse, and otherwise
+// sorts the one that parses first and compares the others as strings.
+func compareParsed[T any](a, b string, parse func(string) (T, error), compare func(T, T) int) int {
+	va, errA := parse(a)
+	vb, errB := parse(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compare(va, vb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// boolRank orders false before true.
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const sortInput = `name,age,joined
+ann,30,2014-03-01
+bob,9,2013-12-24
+cy,,2014-01-15
+dan,30,2012-07-04
+eve,100,2014-01-15
+`
+
+func TestSorter(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Keys   []SortKey
+		Output string
+	}{
+		{
+			Name:   "Numeric",
+			Keys:   []SortKey{{Column: "age", Type: TypeInt}},
+			Output: "name,age,joined\nbob,9,2013-12-24\nann,30,2014-03-01\ndan,30,2012-07-04\neve,100,2014-01-15\ncy,\"\",2014-01-15\n",
+		},
+		{
+			Name:   "String",
+			Keys:   []SortKey{{Column: "age"}},
+			Output: "name,age,joined\ncy,\"\",2014-01-15\neve,100,2014-01-15\nann,30,2014-03-01\ndan,30,2012-07-04\nbob,9,2013-12-24\n",
+		},
+		{
+			Name:   "DescendingThenDate",
+			Keys:   []SortKey{{Column: "age", Type: TypeInt, Descending: true}, {Column: "joined", Type: TypeDate, Layouts: []string{"2006-01-02"}}},
+			Output: "name,age,joined\ncy,\"\",2014-01-15\neve,100,2014-01-15\ndan,30,2012-07-04\nann,30,2014-03-01\nbob,9,2013-12-24\n",
+		},
+	}
+	for _, tt := range tests {
+		for _, runSize := range []int{0, 1, 2} {
+			dir := t.TempDir()
+			var b bytes.Buffer
+			s := &Sorter{Keys: tt.Keys, RunSize: runSize, TempDir: dir}
+			if err := s.Sort(NewReader(strings.NewReader(sortInput)), NewWriter(&b)); err != nil {
+				t.Fatalf("%s, run size %d: unexpected error %v", tt.Name, runSize, err)
+			}
+			if b.String() != tt.Output {
+				t.Errorf("%s, run size %d: out=%q want %q", tt.Name, runSize, b.String(), tt.Output)
+			}
+			if files, _ := os.ReadDir(dir); len(files) != 0 {
+				t.Errorf("%s, run size %d: %d temporary files left", tt.Name, runSize, len(files))
+			}
+		}
+	}
+
+	s := &Sorter{Keys: []SortKey{{Column: "email"}}}
+	if err := s.Sort(NewReader(strings.NewReader(sortInput)), NewWriter(&bytes.Buffer{})); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("error %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestSorterRuns(t *testing.T) {
+	// The runs keep empty records and records starting with the magic of a
+	// compression format.
+	input := "name\nBZh91AY&SY\n\na\n"
+	for _, runSize := range []int{0, 1} {
+		r := NewReader(strings.NewReader(input))
+		r.BlankLines = EmptyBlankLines
+		var b bytes.Buffer
+		s := &Sorter{RunSize: runSize, TempDir: t.TempDir()}
+		if err := s.Sort(r, NewWriter(&b)); err != nil {
+			t.Fatalf("run size %d: unexpected error %v", runSize, err)
+		}
+		if want := "name\n\nBZh91AY&SY\na\n"; b.String() != want {
+			t.Errorf("run size %d: out=%q want %q", runSize, b.String(), want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// A FieldCountReport describes the number of fields of the records of a
+// file, as collected by FieldCounts, to diagnose ErrFieldCount errors in
+// aggregate.
+type FieldCountReport struct {
+	Expected    int                   // field count of the records
+	Records     int                   // records scanned, including the header row
+	Counts      map[int]int           // number of records of each field count
+	Deviations  []FieldCountDeviation // records of another field count, in order
+	ParseErrors []error               // errors of the lines that could not be parsed
+}
+
+// A FieldCountDeviation is a record whose field count is not the expected
+// one.
+type FieldCountDeviation struct {
+	Line   int // line where the record started
+	Fields int // number of fields of the record
+	Diff   int // Fields minus the expected field count
+}
+
+// FieldCounts reads the remaining records of r and reports their field
+// counts.  The expected count is FieldsPerRecord if it is positive, or the
+// field count of the first record, usually the header row.  Records are
+// counted as they are in the input, before any column selection or
+// validation, and lines that cannot be parsed are skipped and their errors
+// collected.  Other errors stop the scan and are returned.
+func (r *Reader) FieldCounts() (*FieldCountReport, error) {
+	report := &FieldCountReport{Counts: make(map[int]int)}
+	if r.FieldsPerRecord > 0 {
+		report.Expected = r.FieldsPerRecord
+	}
+	skipLine := r.SkipLineOnErr
+	r.SkipLineOnErr = true
+	defer func() { r.SkipLineOnErr = skipLine }()
+	for {
+		record, err := r.parseRecord()
+		if r.sources != nil && r.nextSource(record, err) {
+			continue
+		}
+		if record != nil {
+			if report.Records == 0 && report.Expected == 0 {
+				report.Expected = len(record)
+			}
+			report.Records++
+			report.Counts[len(record)]++
+			if len(record) != report.Expected {
+				report.Deviations = append(report.Deviations, FieldCountDeviation{
+					Line:   r.recordLine,
+					Fields: len(record),
+					Diff:   len(record) - report.Expected,
+				})
+			}
+		}
+		if err == io.EOF {
+			return report, nil
+		}
+		if _, ok := err.(*ParseError); ok {
+			report.ParseErrors = append(report.ParseErrors, err)
+		} else if err != nil {
+			return report, err
+		}
+	}
+}
+
+// Summary returns one line per field count, in increasing order, with its
+// number of records and, for the unexpected counts, the difference with
+// the expected count and the first lines of the records, such as
+//
+//	2 fields: 3 records (-1) at lines 4, 9, 12
+//	3 fields: 120 records
+func (rep *FieldCountReport) Summary() string {
+	const maxLines = 5
+	counts := make([]int, 0, len(rep.Counts))
+	for count := range rep.Counts {
+		counts = append(counts, count)
+	}
+	sort.Ints(counts)
+	var b bytes.Buffer
+	for _, count := range counts {
+		fmt.Fprintf(&b, "%d fields: %d records", count, rep.Counts[count])
+		if count != rep.Expected {
+			fmt.Fprintf(&b, " (%+d) at lines ", count-rep.Expected)
+			n := 0
+			for _, d := range rep.Deviations {
+				if d.Fields != count {
+					continue
+				}
+				if n == maxLines {
+					b.WriteString(", ...")
+					break
+				}
+				if n > 0 {
+					b.WriteString(", ")
+				}
+				fmt.Fprint(&b, d.Line)
+				n++
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
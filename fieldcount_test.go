@@ -0,0 +1,53 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldCounts(t *testing.T) {
+	input := "a,b,c\n1,2,3\n4,5\n\"6\n\",7,8\n9,10,11,12\n13,\"x\"y,14\n15,16\n17,18,19\n"
+	r := NewReader(strings.NewReader(input))
+	report, err := r.FieldCounts()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := &FieldCountReport{
+		Expected: 3,
+		Records:  7,
+		Counts:   map[int]int{2: 2, 3: 4, 4: 1},
+		Deviations: []FieldCountDeviation{
+			{Line: 3, Fields: 2, Diff: -1},
+			{Line: 6, Fields: 4, Diff: 1},
+			{Line: 8, Fields: 2, Diff: -1},
+		},
+		ParseErrors: []error{&ParseError{Line: 7, Column: 9, Err: ErrQuote}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report=%+v want %+v", report, want)
+	}
+	wantSummary := "2 fields: 2 records (-1) at lines 3, 8\n3 fields: 4 records\n4 fields: 1 records (+1) at lines 6\n"
+	if summary := report.Summary(); summary != wantSummary {
+		t.Errorf("summary=%q want %q", summary, wantSummary)
+	}
+	if r.SkipLineOnErr {
+		t.Error("SkipLineOnErr left set")
+	}
+}
+
+func TestFieldCountsFieldsPerRecord(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,2\n3\n"))
+	r.FieldsPerRecord = 1
+	report, err := r.FieldCounts()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if report.Expected != 1 || len(report.Deviations) != 2 {
+		t.Errorf("report=%+v", report)
+	}
+}
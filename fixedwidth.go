@@ -0,0 +1,132 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrFixedWidth is returned for fields that do not fit their fixed-width
+// column.
+var ErrFixedWidth = errors.New("field does not fit fixed width")
+
+// An Alignment places a field within its fixed-width column.
+type Alignment int
+
+const (
+	AlignLeft  Alignment = iota // pad on the right
+	AlignRight                  // pad on the left
+)
+
+// A FixedColumn is a column of a fixed-width file.  Widths and offsets are
+// counted in runes.
+type FixedColumn struct {
+	Start int       // offset of the column in the line; 0 to follow the previous column
+	Width int       // width of the column
+	Align Alignment // alignment of the field within the column
+}
+
+// NewFixedWidthReader returns a new Reader that reads fixed-width records
+// from r, cutting each line into the given columns.  Fields are stripped of
+// the spaces padding them, on the right for AlignLeft columns and on the left
+// for AlignRight ones, and fields past the end of a short line are empty.
+// The records are returned like those of a CSV file, so that the header row,
+// maps, selection, transforms and validation work the same way.
+func NewFixedWidthReader(r io.Reader, columns ...FixedColumn) *Reader {
+	reader := NewReader(r)
+	reader.FixedColumns = columns
+	return reader
+}
+
+// parseFixed reads a line and cuts it into the FixedColumns.  Empty lines are
+// skipped.
+func (r *Reader) parseFixed() (fields []string, err error) {
+	var line []rune
+	for {
+		var r1 rune
+		r1, err = r.readRune()
+		if err != nil || r1 == '\n' {
+			break
+		}
+		line = append(line, r1)
+	}
+	if err != nil && err != io.EOF {
+		return nil, r.error(err)
+	}
+	if len(line) == 0 {
+		return nil, err
+	}
+	fields = make([]string, len(r.FixedColumns))
+	pos := 0
+	for i, c := range r.FixedColumns {
+		if c.Start > 0 {
+			pos = c.Start
+		}
+		start, end := min(pos, len(line)), min(pos+c.Width, len(line))
+		field := string(line[start:end])
+		if c.Align == AlignRight {
+			field = strings.TrimLeftFunc(field, unicode.IsSpace)
+		} else {
+			field = strings.TrimRightFunc(field, unicode.IsSpace)
+		}
+		fields[i] = field
+		pos += c.Width
+	}
+	return fields, err
+}
+
+// writeFixed writes record as a fixed-width line.
+func (w *Writer) writeFixed(record []string) error {
+	if len(record) > len(w.FixedColumns) {
+		return ErrFieldCount
+	}
+	pad := w.FixedPad
+	if pad == 0 {
+		pad = ' '
+	}
+	var line strings.Builder
+	pos := 0
+	for i, c := range w.FixedColumns {
+		if c.Start > pos {
+			line.WriteString(strings.Repeat(string(pad), c.Start-pos))
+			pos = c.Start
+		}
+		var field string
+		if i < len(record) {
+			field = record[i]
+		}
+		if strings.ContainsAny(field, "\r\n") {
+			return fmt.Errorf("column %d: %w: line break", i, ErrFixedWidth)
+		}
+		n := utf8.RuneCountInString(field)
+		if n > c.Width {
+			if !w.TruncateFields {
+				return fmt.Errorf("column %d: %w: %d runes wide, want at most %d", i, ErrFixedWidth, n, c.Width)
+			}
+			if w.Logger != nil {
+				w.Logger.Debug("truncated field", "column", i, "width", c.Width, "runes", n)
+			}
+			field = string([]rune(field)[:c.Width])
+			n = c.Width
+		}
+		padding := strings.Repeat(string(pad), c.Width-n)
+		if c.Align == AlignRight {
+			line.WriteString(padding + field)
+		} else {
+			line.WriteString(field + padding)
+		}
+		pos += c.Width
+	}
+	w.started = true
+	if _, err := w.w.WriteString(line.String()); err != nil {
+		return err
+	}
+	return w.endRecord()
+}
@@ -0,0 +1,95 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var fixedColumns = []FixedColumn{
+	{Width: 6},
+	{Width: 4, Align: AlignRight},
+	{Start: 12, Width: 5},
+}
+
+func TestWriteFixedWidth(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Pad      rune
+		Truncate bool
+		Input    [][]string
+		Output   string
+		Error    error
+	}{
+		{
+			Name:   "Padding",
+			Input:  [][]string{{"id", "qty", "name"}, {"A1", "7", "Zoë"}, {"B22"}},
+			Output: "id     qty  name \nA1       7  Zoë  \nB22              \n",
+		},
+		{
+			Name:   "PadRune",
+			Pad:    '0',
+			Input:  [][]string{{"A1", "7", "x"}},
+			Output: "A10000000700x0000\n",
+		},
+		{
+			Name:     "Truncate",
+			Truncate: true,
+			Input:    [][]string{{"ABCDEFGH", "12345", "x"}},
+			Output:   "ABCDEF1234  x    \n",
+		},
+		{
+			Name:  "TooLong",
+			Input: [][]string{{"ABCDEFGH"}},
+			Error: ErrFixedWidth,
+		},
+		{
+			Name:  "LineBreak",
+			Input: [][]string{{"a\nb"}},
+			Error: ErrFixedWidth,
+		},
+		{
+			Name:  "TooManyFields",
+			Input: [][]string{{"a", "b", "c", "d"}},
+			Error: ErrFieldCount,
+		},
+	}
+	for _, tt := range tests {
+		b := &bytes.Buffer{}
+		f := NewWriter(b)
+		f.FixedColumns = fixedColumns
+		f.FixedPad = tt.Pad
+		f.TruncateFields = tt.Truncate
+		err := f.WriteAll(tt.Input)
+		if !errors.Is(err, tt.Error) {
+			t.Errorf("%s: error %v, want %v", tt.Name, err, tt.Error)
+		}
+		if err == nil && b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+	}
+}
+
+func TestFixedWidthReader(t *testing.T) {
+	input := "id     qty  name \r\nA1       7  Zoë  \n\nB22\n  C3    12  Al"
+	r := NewFixedWidthReader(strings.NewReader(input), fixedColumns...)
+	r.Validate("qty", Required())
+	records, errs := r.ReadAllToMapsWithErrors()
+	want := []map[string]string{
+		{"id": "id", "qty": "qty", "name": "name"},
+		{"id": "A1", "qty": "7", "name": "Zoë"},
+		{"id": "  C3", "qty": "12", "name": "Al"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrRequired) {
+		t.Errorf("errs=%v, want a required qty on line 4", errs)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// DefaultPollInterval is the interval at which a Reader returned by
+// NewFollowReader checks for new input when poll is 0.
+const DefaultPollInterval = 250 * time.Millisecond
+
+// NewFollowReader returns a new Reader that follows r like tail -f: at the
+// end of the input, it waits for more to be appended, checking every poll,
+// instead of returning io.EOF.  A record that is only partly written is
+// returned once it is complete.  Once ctx is done, the reading methods
+// return ctx.Err().  The input is not decompressed.
+func NewFollowReader(ctx context.Context, r io.Reader, poll time.Duration) *Reader {
+	if poll <= 0 {
+		poll = DefaultPollInterval
+	}
+	return newPlainReader(&followReader{ctx: ctx, r: r, poll: poll})
+}
+
+// followReader reads r, waiting for more input at its end.
+type followReader struct {
+	ctx  context.Context
+	r    io.Reader
+	poll time.Duration
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 || err != nil && err != io.EOF {
+			return n, err
+		}
+		if err := f.ctx.Err(); err != nil {
+			return 0, err
+		}
+		timer := time.NewTimer(f.poll)
+		select {
+		case <-f.ctx.Done():
+			timer.Stop()
+			return 0, f.ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFollowReader(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "live.csv")
+	log, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	log.WriteString("time,event\n1,start\n2,\"multi")
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := NewFollowReader(ctx, f, time.Millisecond)
+
+	records := make(chan []string)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			record, err := r.Read()
+			if err != nil {
+				errs <- err
+				return
+			}
+			records <- record
+		}
+	}()
+	for _, want := range [][]string{{"time", "event"}, {"1", "start"}} {
+		if record := <-records; !reflect.DeepEqual(record, want) {
+			t.Errorf("record=%q want %q", record, want)
+		}
+	}
+	select {
+	case record := <-records:
+		t.Fatalf("unexpected record %q before it is complete", record)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	log.WriteString("\nline\"\n3,stop\n")
+	for _, want := range [][]string{{"2", "multi\nline"}, {"3", "stop"}} {
+		if record := <-records; !reflect.DeepEqual(record, want) {
+			t.Errorf("record=%q want %q", record, want)
+		}
+	}
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("error %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+)
+
+// ContentHash reads the records of r, which must be at its start, and
+// returns the hex encoded SHA-256 hash of their canonical form, so that
+// datasets which differ only in formatting hash to the same value.  The hash
+// covers the fields of the records as they are read, after Select,
+// Transform and Filter, so that quoting, line endings and the options of r
+// do not change it; trailing empty fields are ignored, as are records that
+// are empty without them, such as lines of commas.  Set FieldsPerRecord to -1
+// for inputs whose records differ in trailing empty fields.  Records with
+// errors are skipped if r has SkipLineOnErr set.
+//
+// The canonical form of a record is the number of its fields, then the
+// length and bytes of each field, with lengths encoded as uvarints, so that
+// no two different records share it.
+func (r *Reader) ContentHash() (string, error) {
+	h := sha256.New()
+	var buf []byte
+	for {
+		record, err := r.readNew()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return "", err
+		}
+		record = trimTrailingEmpty(record)
+		if len(record) == 0 {
+			continue
+		}
+		buf = binary.AppendUvarint(buf[:0], uint64(len(record)))
+		for _, field := range record {
+			buf = binary.AppendUvarint(buf, uint64(len(field)))
+			buf = append(buf, field...)
+		}
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// trimTrailingEmpty returns record without its trailing empty fields.
+func trimTrailingEmpty(record []string) []string {
+	n := len(record)
+	for n > 0 && record[n-1] == "" {
+		n--
+	}
+	return record[:n]
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"strings"
+	"testing"
+)
+
+var contentHashTests = []struct {
+	Name  string
+	A, B  string
+	Equal bool
+}{
+	{
+		Name:  "Quoting",
+		A:     "a,b\n1,x\n",
+		B:     "\"a\",b\n\"1\",\"x\"\n",
+		Equal: true,
+	},
+	{
+		Name:  "LineEndings",
+		A:     "a,b\n1,x\n",
+		B:     "a,b\r\n1,x",
+		Equal: true,
+	},
+	{
+		Name:  "TrailingBlanks",
+		A:     "a,b\n1,x\n",
+		B:     "a,b,,\n1,x,\n\n,,\n",
+		Equal: true,
+	},
+	{
+		Name:  "InnerEmpty",
+		A:     "a,b\n1,,x\n",
+		B:     "a,b\n1,x\n",
+		Equal: false,
+	},
+	{
+		Name:  "FieldBoundaries",
+		A:     "ab,c\n",
+		B:     "a,bc\n",
+		Equal: false,
+	},
+	{
+		Name:  "Order",
+		A:     "a\n1\n2\n",
+		B:     "a\n2\n1\n",
+		Equal: false,
+	},
+	{
+		Name:  "QuotedLineEndings",
+		A:     "a\n\"1\n2\"\n",
+		B:     "a\n\"1\r\n2\"\n",
+		Equal: true,
+	},
+}
+
+func TestContentHash(t *testing.T) {
+	for _, tt := range contentHashTests {
+		ra, rb := NewReader(strings.NewReader(tt.A)), NewReader(strings.NewReader(tt.B))
+		ra.FieldsPerRecord, rb.FieldsPerRecord = -1, -1
+		a, err := ra.ContentHash()
+		if err != nil {
+			t.Errorf("%s: %v", tt.Name, err)
+			continue
+		}
+		b, err := rb.ContentHash()
+		if err != nil {
+			t.Errorf("%s: %v", tt.Name, err)
+			continue
+		}
+		if (a == b) != tt.Equal {
+			t.Errorf("%s: hashes %s, %s; equal=%v want %v", tt.Name, a, b, a == b, tt.Equal)
+		}
+	}
+}
+
+func TestContentHashOptions(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1, x \n2,y\"\n"))
+	r.FieldsPerRecord = -1
+	if _, err := r.ContentHash(); err == nil {
+		t.Errorf("no error for bare quote")
+	}
+
+	r = NewReader(strings.NewReader("a,b\n1, x \n2,y\"\n"))
+	r.FieldsPerRecord = -1
+	r.SkipLineOnErr = true
+	r.Transform("b", strings.TrimSpace)
+	a, err := r.ContentHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewReader(strings.NewReader("a,b\n1,x\n")).ContentHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("hashes %s, %s differ", a, b)
+	}
+}
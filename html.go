@@ -0,0 +1,86 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"html"
+	"io"
+)
+
+// htmlTable is the table written by a Writer returned by NewHTMLWriter.
+type htmlTable struct {
+	section string // open section of the table, "thead" or "tbody"
+	closed  bool   // the table was ended by Close
+}
+
+// NewHTMLWriter returns a new Writer that writes records as the rows of an
+// HTML table, for web previews of CSV files.  Fields are escaped and the
+// quoting options do not apply.  The rows are streamed as they are written:
+// header rows, such as those written by AutoHeader, are written in the
+// thead of the table, as are the Headers before the first record written by
+// Write if AutoHeader is set, and the other records in its tbody.  Close
+// ends the table, and WriteComment returns ErrNoComment.
+func NewHTMLWriter(w io.Writer) *Writer {
+	writer := NewWriter(w)
+	writer.html = new(htmlTable)
+	writer.w.WriteString("<table>\n")
+	return writer
+}
+
+// writeHTMLRow writes record as a row of the table of w, in its thead if
+// header is true and no record was written yet.
+func (w *Writer) writeHTMLRow(record []string, header bool) error {
+	if w.html.closed {
+		return ErrClosed
+	}
+	if !header && !w.started && w.AutoHeader && w.Headers != nil {
+		if err := w.writeHTMLRow(w.Headers, true); err != nil {
+			return err
+		}
+	}
+	w.started = true
+	if header && (w.html.section == "" || w.html.section == "thead") {
+		w.htmlSection("thead")
+	} else {
+		w.htmlSection("tbody")
+	}
+	cell := "td"
+	if header {
+		cell = "th"
+	}
+	w.w.WriteString("<tr>")
+	for _, field := range record {
+		w.w.WriteString("<" + cell + ">" + html.EscapeString(field) + "</" + cell + ">")
+	}
+	if _, err := w.w.WriteString("</tr>"); err != nil {
+		return err
+	}
+	return w.endRecord()
+}
+
+// htmlSection opens the section name of the table of w, closing the
+// previous one.
+func (w *Writer) htmlSection(name string) {
+	if w.html.section == name {
+		return
+	}
+	if w.html.section != "" {
+		w.w.WriteString("</" + w.html.section + ">\n")
+	}
+	w.w.WriteString("<" + name + ">\n")
+	w.html.section = name
+}
+
+// endHTML ends the table of w, if it is an HTML writer.
+func (w *Writer) endHTML() {
+	if w.html == nil || w.html.closed {
+		return
+	}
+	if w.html.section != "" {
+		w.w.WriteString("</" + w.html.section + ">\n")
+	}
+	w.w.WriteString("</table>\n")
+	w.html.closed = true
+}
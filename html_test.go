@@ -0,0 +1,75 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"strings"
+	"testing"
+)
+
+var htmlWriterTests = []struct {
+	Name   string
+	Write  func(w *Writer) error
+	Output string
+}{
+	{
+		Name: "Records",
+		Write: func(w *Writer) error {
+			return w.WriteAll([][]string{{"a", "<b>"}, {"1 & 2", `"x"`}})
+		},
+		Output: "<table>\n<tbody>\n" +
+			"<tr><td>a</td><td>&lt;b&gt;</td></tr>\n" +
+			"<tr><td>1 &amp; 2</td><td>&#34;x&#34;</td></tr>\n" +
+			"</tbody>\n</table>\n",
+	},
+	{
+		Name: "Headers",
+		Write: func(w *Writer) error {
+			w.Headers = []string{"name", "note"}
+			w.AutoHeader = true
+			return w.Write([]string{"ann", "it's"})
+		},
+		Output: "<table>\n<thead>\n<tr><th>name</th><th>note</th></tr>\n</thead>\n<tbody>\n" +
+			"<tr><td>ann</td><td>it&#39;s</td></tr>\n" +
+			"</tbody>\n</table>\n",
+	},
+	{
+		Name: "Maps",
+		Write: func(w *Writer) error {
+			w.Headers = []string{"a", "b"}
+			w.AutoHeader = true
+			w.NullValue = "-"
+			return w.WriteNullableMap(map[string]*string{"a": stringPtr("1")})
+		},
+		Output: "<table>\n<thead>\n<tr><th>a</th><th>b</th></tr>\n</thead>\n<tbody>\n" +
+			"<tr><td>1</td><td>-</td></tr>\n" +
+			"</tbody>\n</table>\n",
+	},
+	{
+		Name:   "Empty",
+		Write:  func(w *Writer) error { return nil },
+		Output: "<table>\n</table>\n",
+	},
+}
+
+func TestHTMLWriter(t *testing.T) {
+	for _, tt := range htmlWriterTests {
+		var b strings.Builder
+		w := NewHTMLWriter(&b)
+		if err := tt.Write(w); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+			continue
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		}
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+		if err := w.Write([]string{"x"}); err != ErrClosed {
+			t.Errorf("%s: write after Close: error %v, want %v", tt.Name, err, ErrClosed)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"context"
+	"mime"
+	"net/http"
+)
+
+// These are the flush thresholds of an HTTPExport whose FlushRows and
+// FlushBytes are 0.
+const (
+	DefaultFlushRows  = 1000
+	DefaultFlushBytes = 32 << 10
+)
+
+// An HTTPExport writes CSV responses incrementally, for export endpoints
+// whose responses are too large to be built in memory.  The response is
+// flushed to the client every FlushRows records, and whenever FlushBytes
+// bytes were written since the last flush, so that the download starts
+// early and its connection is kept busy.
+//
+// If Configure is not nil, it is called with the Writer of each response
+// before the first record is written, to set options such as Comma.
+type HTTPExport struct {
+	Filename   string        // name of the downloaded file; no Content-Disposition if ""
+	FlushRows  int           // records between flushes; DefaultFlushRows if 0
+	FlushBytes int           // bytes between flushes; DefaultFlushBytes if 0
+	Configure  func(*Writer) // configures the Writer of each response
+}
+
+// httpWriter is the output of a Writer of an HTTPExport, flushing the
+// response to the client.
+type httpWriter struct {
+	w          http.ResponseWriter
+	rc         *http.ResponseController
+	flushRows  int
+	flushBytes int
+	rows       int  // records since the last flush
+	bytes      int  // bytes since the last flush
+	wrote      bool // the response was started
+}
+
+func (h *httpWriter) Write(p []byte) (int, error) {
+	h.wrote = true
+	n, err := h.w.Write(p)
+	h.bytes += n
+	if err == nil && h.bytes >= h.flushBytes {
+		h.flush()
+	}
+	return n, err
+}
+
+// flush sends the response written since the last flush to the client.
+// Responses that cannot be flushed are sent when the handler returns.
+func (h *httpWriter) flush() {
+	if h.bytes > 0 {
+		h.rc.Flush()
+	}
+	h.rows, h.bytes = 0, 0
+}
+
+// record counts a record written by w, flushing w every flushRows records.
+func (h *httpWriter) record(w *Writer) error {
+	h.rows++
+	if h.rows < h.flushRows {
+		return nil
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	h.flush()
+	return nil
+}
+
+// NewWriter sets the headers of a CSV response on w, with Content-Type
+// text/csv and, if Filename is set, a Content-Disposition attachment, and
+// returns a Writer of the response.
+func (e *HTTPExport) NewWriter(w http.ResponseWriter) *Writer {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	if e.Filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.Filename}))
+	}
+	h := &httpWriter{
+		w:          w,
+		rc:         http.NewResponseController(w),
+		flushRows:  e.FlushRows,
+		flushBytes: e.FlushBytes,
+	}
+	if h.flushRows <= 0 {
+		h.flushRows = DefaultFlushRows
+	}
+	if h.flushBytes <= 0 {
+		h.flushBytes = DefaultFlushBytes
+	}
+	writer := NewWriter(h)
+	writer.http = h
+	if e.Configure != nil {
+		e.Configure(writer)
+	}
+	return writer
+}
+
+// Serve writes a CSV response to w with the records written by produce to
+// the Writer it is passed, and flushes it.  If produce returns an error
+// before any of the response was sent, the response is discarded and
+// replaced by a 500 Internal Server Error; otherwise the client gets a
+// truncated response.  The error of produce, or of writing the response, is
+// returned for logging.
+func (e *HTTPExport) Serve(w http.ResponseWriter, produce func(w *Writer) error) error {
+	writer := e.NewWriter(w)
+	if err := produce(writer); err != nil {
+		if !writer.http.wrote {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Disposition")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		} else {
+			writer.Flush()
+		}
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ServeChannel writes a CSV response to w with the records received from
+// records until the channel is closed or ctx is done, as with the Consume
+// method of a Writer.
+func (e *HTTPExport) ServeChannel(ctx context.Context, w http.ResponseWriter, records <-chan []string) error {
+	return e.Serve(w, func(writer *Writer) error {
+		return writer.Consume(ctx, records)
+	})
+}
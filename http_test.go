@@ -0,0 +1,123 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// flushRecorder is a ResponseRecorder recording the size of the body at
+// each flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []int
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushes = append(f.flushes, f.Body.Len())
+	f.ResponseRecorder.Flush()
+}
+
+var httpExportTests = []struct {
+	Name    string
+	Export  HTTPExport
+	Records int
+	Flushes []int
+}{
+	{
+		Name:    "Rows",
+		Export:  HTTPExport{FlushRows: 2},
+		Records: 5,
+		Flushes: []int{8, 16, 20},
+	},
+	{
+		Name:    "Bytes",
+		Export:  HTTPExport{FlushRows: 1000, FlushBytes: 1},
+		Records: 3,
+		Flushes: []int{12},
+	},
+	{
+		Name:    "Default",
+		Records: 3,
+		Flushes: []int{12},
+	},
+}
+
+func TestHTTPExport(t *testing.T) {
+	for _, tt := range httpExportTests {
+		w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		tt.Export.Filename = "report 1.csv"
+		err := tt.Export.Serve(w, func(writer *Writer) error {
+			for i := range tt.Records {
+				if err := writer.Write([]string{strconv.Itoa(i), "x"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		}
+		if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+			t.Errorf("%s: Content-Type %q", tt.Name, got)
+		}
+		if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="report 1.csv"` {
+			t.Errorf("%s: Content-Disposition %q", tt.Name, got)
+		}
+		if w.Body.Len() != 4*tt.Records {
+			t.Errorf("%s: body %q", tt.Name, w.Body.String())
+		}
+		if !equalInts(w.flushes, tt.Flushes) {
+			t.Errorf("%s: flushes at %v want %v", tt.Name, w.flushes, tt.Flushes)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHTTPExportError(t *testing.T) {
+	failed := errors.New("query failed")
+	w := httptest.NewRecorder()
+	e := &HTTPExport{Filename: "x.csv"}
+	err := e.Serve(w, func(writer *Writer) error {
+		writer.Write([]string{"a"})
+		return failed
+	})
+	if err != failed {
+		t.Errorf("error %v, want %v", err, failed)
+	}
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Disposition") != "" {
+		t.Errorf("response %d %v, want an Internal Server Error", w.Code, w.Header())
+	}
+}
+
+func TestHTTPExportChannel(t *testing.T) {
+	records := make(chan []string, 2)
+	records <- []string{"a", "b"}
+	records <- []string{"1", "2"}
+	close(records)
+	w := httptest.NewRecorder()
+	if err := new(HTTPExport).ServeChannel(context.Background(), w, records); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if w.Body.String() != "a,b\n1,2\n" || !w.Flushed {
+		t.Errorf("body %q, flushed %v", w.Body.String(), w.Flushed)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// These are the errors of NewResponseReader.
+var (
+	ErrHTTPStatus      = errors.New("unexpected HTTP status")
+	ErrContentEncoding = errors.New("unsupported content encoding")
+)
+
+// NewResponseReader returns a Reader of the body of resp, such as the
+// response of a CSV API:
+//
+//	resp, err := http.Get(url)
+//	if err != nil {
+//		return err
+//	}
+//	defer resp.Body.Close()
+//	r, err := bettercsv.NewResponseReader(resp)
+//
+// The body is decompressed according to its Content-Encoding, gzip or the
+// name of a decompressor registered with RegisterDecompressor, and decoded
+// to UTF-8 from the charset of its Content-Type, one registered with
+// RegisterCharset; a body without charset is read as UTF-8, or as UTF-16 if
+// it starts with a UTF-16 byte order mark.  Responses of Content-Type
+// text/tab-separated-values are read with Comma set to '\t'.  A response
+// whose status is not 2xx returns an error wrapping ErrHTTPStatus.  The
+// caller closes the body.
+func NewResponseReader(resp *http.Response) (*Reader, error) {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w %s", ErrHTTPStatus, resp.Status)
+	}
+	body, err := decodeContentEncoding(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, err
+	}
+	body, mediaType, err := decodeContentType(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	r := NewReader(body)
+	if mediaType == "text/tab-separated-values" {
+		r.Comma = '\t'
+	}
+	return r, nil
+}
+
+// decodeContentEncoding returns body decoded from the Content-Encoding
+// encoding.
+func decodeContentEncoding(body io.Reader, encoding string) (io.Reader, error) {
+	switch encoding = strings.ToLower(strings.TrimSpace(encoding)); encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	}
+	decompressorsMu.Lock()
+	registered := decompressors
+	decompressorsMu.Unlock()
+	for _, d := range registered {
+		if d.name == encoding {
+			return d.open(body)
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrContentEncoding, encoding)
+}
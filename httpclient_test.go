@@ -0,0 +1,157 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// gzipString returns s compressed with gzip.
+func gzipString(s string) string {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Write([]byte(s))
+	zw.Close()
+	return b.String()
+}
+
+// utf16String returns s encoded in UTF-16 with a little-endian byte order
+// mark.
+func utf16String(s string) string {
+	b := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return string(b)
+}
+
+var responseReaderTests = []struct {
+	Name   string
+	Status int
+	Header map[string]string
+	Body   string
+	Output [][]string
+	Error  error
+}{
+	{
+		Name:   "Plain",
+		Body:   "a,b\n1,2\n",
+		Output: [][]string{{"a", "b"}, {"1", "2"}},
+	},
+	{
+		Name:   "UTF8BOM",
+		Header: map[string]string{"Content-Type": "text/csv; charset=UTF-8"},
+		Body:   "\xef\xbb\xbfa,b\n1,2\n",
+		Output: [][]string{{"a", "b"}, {"1", "2"}},
+	},
+	{
+		Name:   "Latin1",
+		Header: map[string]string{"Content-Type": "text/csv; charset=ISO-8859-1"},
+		Body:   "name\nJos\xe9\n",
+		Output: [][]string{{"name"}, {"José"}},
+	},
+	{
+		Name:   "Windows1252",
+		Header: map[string]string{"Content-Type": "text/csv; charset=windows-1252"},
+		Body:   "price\n\x8010\n\x93q\x94\n",
+		Output: [][]string{{"price"}, {"€10"}, {"“q”"}},
+	},
+	{
+		Name:   "UTF16",
+		Header: map[string]string{"Content-Type": "text/csv; charset=utf-16"},
+		Body:   utf16String("a,b\n𝄞,é\n"),
+		Output: [][]string{{"a", "b"}, {"𝄞", "é"}},
+	},
+	{
+		Name:   "Gzip",
+		Header: map[string]string{"Content-Type": "text/csv; charset=iso-8859-1", "Content-Encoding": "gzip"},
+		Body:   gzipString("a\n\xe9\n"),
+		Output: [][]string{{"a"}, {"é"}},
+	},
+	{
+		Name:   "TSV",
+		Header: map[string]string{"Content-Type": "text/tab-separated-values"},
+		Body:   "a\tb\n1,2\t3\n",
+		Output: [][]string{{"a", "b"}, {"1,2", "3"}},
+	},
+	{
+		Name:   "Status",
+		Status: http.StatusNotFound,
+		Body:   "not found",
+		Error:  ErrHTTPStatus,
+	},
+	{
+		Name:   "Charset",
+		Header: map[string]string{"Content-Type": "text/csv; charset=ebcdic"},
+		Error:  ErrCharset,
+	},
+	{
+		Name:   "ContentEncoding",
+		Header: map[string]string{"Content-Encoding": "br"},
+		Error:  ErrContentEncoding,
+	},
+}
+
+func TestNewResponseReader(t *testing.T) {
+	for _, tt := range responseReaderTests {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(tt.Body)),
+		}
+		if tt.Status != 0 {
+			resp.StatusCode = tt.Status
+			resp.Status = http.StatusText(tt.Status)
+		}
+		for k, v := range tt.Header {
+			resp.Header.Set(k, v)
+		}
+		r, err := NewResponseReader(resp)
+		if tt.Error != nil {
+			if !errors.Is(err, tt.Error) {
+				t.Errorf("%s: error %v, want %v", tt.Name, err, tt.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+			continue
+		}
+		out, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, tt.Output)
+		}
+	}
+}
+
+func TestRegisterCharset(t *testing.T) {
+	RegisterCharset("X-Upper", func(r io.Reader) io.Reader {
+		b, _ := io.ReadAll(r)
+		return bytes.NewReader(bytes.ToUpper(b))
+	})
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/csv; charset=x-upper"}},
+		Body:       io.NopCloser(strings.NewReader("a\nb\n")),
+	}
+	r, err := NewResponseReader(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.ReadAll()
+	if want := [][]string{{"A"}, {"B"}}; err != nil || !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%q err=%v want %q", out, err, want)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNoIndex is returned by ReadAt when the Reader has no Index.
+var ErrNoIndex = errors.New("reader has no index")
+
+// DefaultIndexInterval is the number of records between the offsets of an
+// Index when BuildIndex is passed 0.
+const DefaultIndexInterval = 1000
+
+// An Index holds the position of every Every-th record after the header row
+// of an input, so that ReadAt can move to any record without reading the
+// records before it.  An Index can be saved, for instance as JSON, and set
+// as the Index of a new Reader of the same input.
+type Index struct {
+	Every   int     `json:"every"`   // records between offsets
+	Records int     `json:"records"` // records after the header row
+	Offsets []int64 `json:"offsets"` // Offsets[i] is the byte offset of record i*Every
+	Lines   []int   `json:"lines"`   // Lines[i] is the line before record i*Every
+}
+
+// BuildIndex reads the whole input of r, which must be an uncompressed
+// io.ReadSeeker, and sets r.Index to an Index of the position of every
+// every-th record after the header row.  It then moves r to the first record
+// after the header row.
+func (r *Reader) BuildIndex(every int) (*Index, error) {
+	if every <= 0 {
+		every = DefaultIndexInterval
+	}
+	if err := r.checkSeekable(); err != nil {
+		return nil, err
+	}
+	if err := r.seekHeaders(); err != nil {
+		return nil, err
+	}
+
+	keepComments := r.KeepComments
+	r.KeepComments = false
+	defer func() { r.KeepComments = keepComments }()
+
+	index := &Index{Every: every}
+	for {
+		start, line := r.offset, r.line
+		record, err := r.parseRecord()
+		if record != nil {
+			if index.Records%every == 0 {
+				index.Offsets = append(index.Offsets, start)
+				index.Lines = append(index.Lines, line)
+			}
+			index.Records++
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	r.Index = index
+	return index, r.reset(r.dataStart)
+}
+
+// ReadAt reads record i after the header row, using r.Index to move to it.
+// Later reads continue from the record after it.  ReadAt returns io.EOF if
+// the index holds fewer than i+1 records.
+func (r *Reader) ReadAt(i int) (record []string, err error) {
+	if r.Index == nil {
+		return nil, ErrNoIndex
+	}
+	if i < 0 || i >= r.Index.Records {
+		return nil, io.EOF
+	}
+	if err := r.checkSeekable(); err != nil {
+		return nil, err
+	}
+	if r.headers == nil {
+		if err := r.seekHeaders(); err != nil {
+			return nil, err
+		}
+	}
+
+	n := i / r.Index.Every
+	if err := r.reset(r.Index.Offsets[n]); err != nil {
+		return nil, err
+	}
+	r.line = r.Index.Lines[n]
+	for skip := i % r.Index.Every; skip > 0; {
+		record, err := r.parseRecord()
+		if record != nil {
+			skip--
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	record, _, err = r.nextRecord(false)
+	return record, err
+}
@@ -0,0 +1,73 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,note\n")
+	for i := 0; i < 10; i++ {
+		if i == 4 {
+			b.WriteString("# comment\n")
+		}
+		fmt.Fprintf(&b, "%d,\"line\n%d\"\n", i, i)
+	}
+	input := b.String()
+
+	r := NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	index, err := r.BuildIndex(3)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if index.Records != 10 || len(index.Offsets) != 4 {
+		t.Errorf("index=%+v", index)
+	}
+	record, err := r.Read()
+	if err != nil || !reflect.DeepEqual(record, []string{"0", "line\n0"}) {
+		t.Errorf("first record=%q, err=%v", record, err)
+	}
+
+	data, _ := json.Marshal(index)
+	r = NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	r.Index = new(Index)
+	json.Unmarshal(data, r.Index)
+	for _, i := range []int{7, 2, 9, 0, 4, 5} {
+		record, err := r.ReadAt(i)
+		want := []string{fmt.Sprint(i), fmt.Sprintf("line\n%d", i)}
+		if err != nil || !reflect.DeepEqual(record, want) {
+			t.Errorf("ReadAt(%d)=%q, err=%v, want %q", i, record, err, want)
+		}
+	}
+	recordMap, err := r.ReadToMap()
+	if want := map[string]string{"id": "6", "note": "line\n6"}; err != nil || !reflect.DeepEqual(recordMap, want) {
+		t.Errorf("next record=%q, err=%v, want %q", recordMap, err, want)
+	}
+	if _, err := r.ReadAt(10); err != io.EOF {
+		t.Errorf("ReadAt(10) error %v, want io.EOF", err)
+	}
+
+	r = NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	r.Index = index
+	r.FieldsPerRecord = 3
+	_, err = r.ReadAt(5)
+	if perr, ok := err.(*ParseError); !ok || perr.Line != 14 || perr.Err != ErrFieldCount {
+		t.Errorf("error %v, want wrong number of fields on line 14", err)
+	}
+
+	if _, err := NewReader(strings.NewReader(input)).ReadAt(0); err != ErrNoIndex {
+		t.Errorf("error %v, want %v", err, ErrNoIndex)
+	}
+}
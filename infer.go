@@ -0,0 +1,143 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// A ColumnType is the type of a column inferred by InferTypes.
+type ColumnType int
+
+const (
+	TypeUnknown ColumnType = iota // no non-null values were seen
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeDate
+	TypeString
+)
+
+var columnTypeNames = []string{
+	TypeUnknown: "unknown",
+	TypeInt:     "int",
+	TypeFloat:   "float",
+	TypeBool:    "bool",
+	TypeDate:    "date",
+	TypeString:  "string",
+}
+
+func (t ColumnType) String() string {
+	if t < 0 || int(t) >= len(columnTypeNames) {
+		return "ColumnType(" + strconv.Itoa(int(t)) + ")"
+	}
+	return columnTypeNames[t]
+}
+
+// DateLayouts are the layouts InferTypes tries, after any layouts set with
+// TimeLayout, to recognize dates.
+var DateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+// A ColumnInfo describes a column inferred by InferTypes.
+type ColumnInfo struct {
+	Name     string     // header of the column
+	Type     ColumnType // narrowest type holding every non-null value
+	Layout   string     // layout of the dates if Type is TypeDate
+	Nullable bool       // true if an empty or null value was seen
+}
+
+// InferTypes reads the headers, if they have not been read, and up to n
+// records, and infers the type of each column from them.  Empty fields and
+// fields matching one of the NullValues make a column nullable and do not
+// affect its type.  Because the sampled records are consumed from r, they are
+// returned so that they can still be processed.
+func (r *Reader) InferTypes(n int) (columns []ColumnInfo, sample [][]string, err error) {
+	if _, err = r.Headers(); err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	for _, name := range r.outputHeaders() {
+		columns = append(columns, ColumnInfo{Name: name})
+	}
+	for len(sample) < n {
+		record, _, err := r.read(true)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		sample = append(sample, record)
+		for i := range columns {
+			if i >= len(record) || record[i] == "" || r.isNull(record[i]) {
+				columns[i].Nullable = true
+				continue
+			}
+			columns[i].infer(record[i], r.timeLayouts[columns[i].Name])
+		}
+	}
+	return columns, sample, nil
+}
+
+// infer widens the type of c so that it holds value.
+func (c *ColumnInfo) infer(value string, layouts []string) {
+	switch c.Type {
+	case TypeUnknown:
+		c.Type, c.Layout = inferType(value, layouts)
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return
+		}
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			c.Type = TypeFloat
+			return
+		}
+		c.Type = TypeString
+	case TypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			c.Type = TypeString
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			c.Type = TypeString
+		}
+	case TypeDate:
+		if _, err := time.Parse(c.Layout, value); err != nil {
+			c.Type = TypeString
+			c.Layout = ""
+		}
+	}
+}
+
+// inferType returns the narrowest type of value and, for dates, the layout
+// that parsed it.
+func inferType(value string, layouts []string) (ColumnType, string) {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return TypeInt, ""
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return TypeFloat, ""
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return TypeBool, ""
+	}
+	for _, list := range [][]string{layouts, DateLayouts} {
+		for _, layout := range list {
+			if _, err := time.Parse(layout, value); err == nil {
+				return TypeDate, layout
+			}
+		}
+	}
+	return TypeString, ""
+}
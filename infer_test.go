@@ -0,0 +1,61 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferTypes(t *testing.T) {
+	input := `id,price,active,joined,note,ratio,empty,day
+1,2,true,2014-06-01,a,1,,06/01/2014
+2,2.5,false,2014-06-02,7,NULL,,06/02/2014
+3,3,TRUE,,b,2,,06/03/2014
+4,x,false,2014-06-04,c,3,,bad
+`
+	r := NewReader(strings.NewReader(input))
+	r.NullValues = []string{"NULL"}
+	r.TimeLayout("day", "01/02/2006")
+	columns, sample, err := r.InferTypes(3)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []ColumnInfo{
+		{Name: "id", Type: TypeInt},
+		{Name: "price", Type: TypeFloat},
+		{Name: "active", Type: TypeBool},
+		{Name: "joined", Type: TypeDate, Layout: "2006-01-02", Nullable: true},
+		{Name: "note", Type: TypeString},
+		{Name: "ratio", Type: TypeInt, Nullable: true},
+		{Name: "empty", Type: TypeUnknown, Nullable: true},
+		{Name: "day", Type: TypeDate, Layout: "01/02/2006"},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns=%+v\nwant %+v", columns, want)
+	}
+	if len(sample) != 3 || sample[0][0] != "1" {
+		t.Errorf("sample=%q", sample)
+	}
+	record, err := r.Read()
+	if err != nil || record[0] != "4" {
+		t.Errorf("next record=%q, %v", record, err)
+	}
+
+	r = NewReader(strings.NewReader(input))
+	columns, _, err = r.InferTypes(10)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, c := range columns {
+		if c.Name == "price" && c.Type != TypeString {
+			t.Errorf("price: type %v, want string", c.Type)
+		}
+		if c.Name == "day" && c.Type != TypeString {
+			t.Errorf("day: type %v, want string", c.Type)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// An NDJSONEncoder writes records as newline-delimited JSON objects, with the
+// headers as keys, in header order.
+//
+// If Types is nil, every value is written as a JSON string.  Otherwise, values
+// of TypeInt and TypeFloat columns are written as JSON numbers and values of
+// TypeBool columns as JSON booleans, while empty values and values matching
+// one of the NullValues are written as null.  Values that cannot be converted
+// are written as strings.
+type NDJSONEncoder struct {
+	Types      []ColumnType // types of the columns
+	NullValues []string     // values written as null when Types is set
+	headers    []string
+	w          *bufio.Writer
+	buf        bytes.Buffer
+}
+
+// NewNDJSONEncoder returns a new NDJSONEncoder that writes to w.
+func NewNDJSONEncoder(w io.Writer, headers []string) *NDJSONEncoder {
+	return &NDJSONEncoder{
+		headers: headers,
+		w:       bufio.NewWriter(w),
+	}
+}
+
+// Encode writes record as a JSON object followed by a newline.  Fields beyond
+// the headers are ignored and missing fields are written as null.
+func (e *NDJSONEncoder) Encode(record []string) error {
+	e.buf.Reset()
+	e.encodeObject(&e.buf, record)
+	e.buf.WriteByte('\n')
+	_, err := e.w.Write(e.buf.Bytes())
+	return err
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (e *NDJSONEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+// encodeObject writes record to b as a JSON object.
+func (e *NDJSONEncoder) encodeObject(b *bytes.Buffer, record []string) {
+	b.WriteByte('{')
+	for i, header := range e.headers {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeJSONString(b, header)
+		b.WriteByte(':')
+		if i >= len(record) {
+			b.WriteString("null")
+			continue
+		}
+		e.encodeValue(b, i, record[i])
+	}
+	b.WriteByte('}')
+}
+
+// encodeValue writes the value of column i to b.
+func (e *NDJSONEncoder) encodeValue(b *bytes.Buffer, i int, value string) {
+	if i >= len(e.Types) {
+		writeJSONString(b, value)
+		return
+	}
+	if value == "" || e.isNull(value) {
+		b.WriteString("null")
+		return
+	}
+	switch e.Types[i] {
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			b.WriteString(value)
+			return
+		}
+	case TypeFloat:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			if data, err := json.Marshal(f); err == nil {
+				b.Write(data)
+				return
+			}
+		}
+	case TypeBool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			b.WriteString(strconv.FormatBool(v))
+			return
+		}
+	}
+	writeJSONString(b, value)
+}
+
+// isNull reports whether value is one of the NullValues.
+func (e *NDJSONEncoder) isNull(value string) bool {
+	for _, null := range e.NullValues {
+		if value == null {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONString writes s to b as a JSON string.
+func writeJSONString(b *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	b.Write(data)
+}
+
+// WriteNDJSON writes all the remaining records of r to w as newline-delimited
+// JSON objects keyed by the headers.  The header row is not written.  If
+// JSONInferRows is positive, the types of the values are inferred from that
+// many records as with InferTypes.
+func (r *Reader) WriteNDJSON(w io.Writer) error {
+	return r.writeJSON(w, false)
+}
+
+// ReadAllToJSON reads all the remaining records from r and returns them as a
+// JSON array of objects keyed by the headers.  The header row is not included.
+// If JSONInferRows is positive, the types of the values are inferred from that
+// many records as with InferTypes.
+func (r *Reader) ReadAllToJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := r.writeJSON(&b, true); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeJSON writes the remaining records of r to w, as a JSON array if array
+// is true and as newline-delimited JSON otherwise.
+func (r *Reader) writeJSON(w io.Writer, array bool) error {
+	if _, err := r.Headers(); err != nil && err != io.EOF {
+		return err
+	}
+	enc := NewNDJSONEncoder(w, r.outputHeaders())
+	var sample [][]string
+	if r.JSONInferRows > 0 {
+		columns, records, err := r.InferTypes(r.JSONInferRows)
+		if err != nil {
+			return err
+		}
+		sample = records
+		enc.NullValues = r.NullValues
+		for _, column := range columns {
+			enc.Types = append(enc.Types, column.Type)
+		}
+	}
+
+	n := 0
+	encode := func(record []string) error {
+		if !array {
+			return enc.Encode(record)
+		}
+		enc.buf.Reset()
+		if n == 0 {
+			enc.buf.WriteByte('[')
+		} else {
+			enc.buf.WriteByte(',')
+		}
+		enc.encodeObject(&enc.buf, record)
+		n++
+		_, err := enc.w.Write(enc.buf.Bytes())
+		return err
+	}
+	for _, record := range sample {
+		if err := encode(record); err != nil {
+			return err
+		}
+	}
+	for {
+		record, _, err := r.read(true)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return err
+		}
+		if err = encode(record); err != nil {
+			return err
+		}
+	}
+	if array {
+		if n == 0 {
+			enc.w.WriteByte('[')
+		}
+		enc.w.WriteByte(']')
+	}
+	return enc.Flush()
+}
+
+// WriteNDJSON writes the remaining records that follow the Schema to w as
+// newline-delimited JSON objects of converted values, in schema order, and
+// returns a report of the violations of the other records.
+func (s *SchemaReader) WriteNDJSON(w io.Writer) (report *ValidationReport, err error) {
+	report = new(ValidationReport)
+	bw := bufio.NewWriter(w)
+	var b bytes.Buffer
+	for {
+		record, violations, err := s.Read()
+		if err == io.EOF {
+			return report, bw.Flush()
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Add(violations...)
+		if record == nil {
+			continue
+		}
+		b.Reset()
+		b.WriteByte('{')
+		for i, column := range s.Schema.Columns {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(&b, column.Name)
+			b.WriteByte(':')
+			data, err := json.Marshal(record[column.Name])
+			if err != nil {
+				return report, err
+			}
+			b.Write(data)
+		}
+		b.WriteString("}\n")
+		if _, err = bw.Write(b.Bytes()); err != nil {
+			return report, err
+		}
+	}
+}
+
+// A JSONConverter converts JSON objects to CSV records.
+//
+// Nested objects are flattened, joining their keys with Separator, so that
+// {"address": {"city": "Paris"}} becomes the column "address.city".  Arrays
+// are written as JSON text, null as an empty field and other values as their
+// text.
+//
+// If Headers is nil, the columns are the keys of every object, in the order
+// they are first seen, and the objects are buffered until the input has been
+// read.  Otherwise the objects are converted as they are read and keys that
+// are not in Headers are ignored.
+type JSONConverter struct {
+	Headers   []string // order of the columns
+	Separator string   // joins nested keys (set to "." by NewJSONConverter)
+}
+
+// NewJSONConverter returns a new JSONConverter.
+func NewJSONConverter() *JSONConverter {
+	return &JSONConverter{Separator: "."}
+}
+
+var errNotObject = errors.New("JSON value is not an object")
+
+// jsonField is a flattened key and its value.
+type jsonField struct {
+	key, value string
+}
+
+// Convert reads JSON objects from src, either as a JSON array or as a
+// sequence of objects such as newline-delimited JSON, and writes them to w as
+// CSV records preceded by a header row.  It flushes w before returning.
+func (c *JSONConverter) Convert(src io.Reader, w *Writer) error {
+	br := bufio.NewReader(src)
+	dec := json.NewDecoder(br)
+	array := false
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			br.ReadByte()
+			continue
+		}
+		if b[0] == '[' {
+			dec.Token()
+			array = true
+		}
+		break
+	}
+
+	headers := c.Headers
+	var buffered [][]jsonField
+	if headers != nil {
+		if err := w.writeHeader(headers); err != nil {
+			return err
+		}
+	}
+	for !array || dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		var fields []jsonField
+		if err := c.flatten(raw, "", &fields); err != nil {
+			return err
+		}
+		if headers == nil {
+			buffered = append(buffered, fields)
+			continue
+		}
+		if err := w.writeRecord(jsonRecord(headers, fields), headers, nil); err != nil {
+			return err
+		}
+	}
+	if headers == nil {
+		seen := make(map[string]bool)
+		for _, fields := range buffered {
+			for _, f := range fields {
+				if !seen[f.key] {
+					seen[f.key] = true
+					headers = append(headers, f.key)
+				}
+			}
+		}
+		if err := w.writeHeader(headers); err != nil {
+			return err
+		}
+		for _, fields := range buffered {
+			if err := w.writeRecord(jsonRecord(headers, fields), headers, nil); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// flatten appends the fields of the JSON object data to fields, prefixing
+// their keys with prefix.
+func (c *JSONConverter) flatten(data json.RawMessage, prefix string, fields *[]jsonField) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return errNotObject
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + token.(string)
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return err
+		}
+		value = bytes.TrimSpace(value)
+		switch value[0] {
+		case '{':
+			if err = c.flatten(value, key+c.Separator, fields); err != nil {
+				return err
+			}
+			continue
+		case '"':
+			var s string
+			if err = json.Unmarshal(value, &s); err != nil {
+				return err
+			}
+			*fields = append(*fields, jsonField{key, s})
+		case 'n':
+			*fields = append(*fields, jsonField{key, ""})
+		case '[':
+			var b bytes.Buffer
+			if err = json.Compact(&b, value); err != nil {
+				return err
+			}
+			*fields = append(*fields, jsonField{key, b.String()})
+		default:
+			*fields = append(*fields, jsonField{key, string(value)})
+		}
+	}
+	return nil
+}
+
+// jsonRecord returns the values of fields in the order of headers.
+func jsonRecord(headers []string, fields []jsonField) []string {
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values[f.key] = f.value
+	}
+	record := make([]string, len(headers))
+	for i, header := range headers {
+		record[i] = values[header]
+	}
+	return record
+}
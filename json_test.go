@@ -0,0 +1,138 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var jsonTests = []struct {
+	Name          string
+	Input         string
+	JSONInferRows int
+	NDJSON        string
+	JSON          string
+}{
+	{
+		Name:   "Strings",
+		Input:  "id,name\n1,ann\n2,\"b\"\"ob\"",
+		NDJSON: `{"id":"1","name":"ann"}` + "\n" + `{"id":"2","name":"b\"ob"}` + "\n",
+		JSON:   `[{"id":"1","name":"ann"},{"id":"2","name":"b\"ob"}]`,
+	},
+	{
+		Name:          "Inferred",
+		Input:         "id,price,active,name\n1,2.5,true,ann\n2,,false,bob\n3,x,true,",
+		JSONInferRows: 2,
+		NDJSON: `{"id":1,"price":2.5,"active":true,"name":"ann"}` + "\n" +
+			`{"id":2,"price":null,"active":false,"name":"bob"}` + "\n" +
+			`{"id":3,"price":"x","active":true,"name":null}` + "\n",
+		JSON: `[{"id":1,"price":2.5,"active":true,"name":"ann"},` +
+			`{"id":2,"price":null,"active":false,"name":"bob"},` +
+			`{"id":3,"price":"x","active":true,"name":null}]`,
+	},
+	{
+		Name:   "HeadersOnly",
+		Input:  "id,name\n",
+		NDJSON: "",
+		JSON:   "[]",
+	},
+	{
+		Name:   "ShortRecord",
+		Input:  "id,name\n1",
+		NDJSON: `{"id":"1","name":null}` + "\n",
+		JSON:   `[{"id":"1","name":null}]`,
+	},
+}
+
+func TestJSON(t *testing.T) {
+	for _, tt := range jsonTests {
+		r := NewReader(strings.NewReader(tt.Input))
+		r.FieldsPerRecord = -1
+		r.JSONInferRows = tt.JSONInferRows
+		var b bytes.Buffer
+		if err := r.WriteNDJSON(&b); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if b.String() != tt.NDJSON {
+			t.Errorf("%s: ndjson=%s want %s", tt.Name, b.String(), tt.NDJSON)
+		}
+
+		r = NewReader(strings.NewReader(tt.Input))
+		r.FieldsPerRecord = -1
+		r.JSONInferRows = tt.JSONInferRows
+		out, err := r.ReadAllToJSON()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if string(out) != tt.JSON {
+			t.Errorf("%s: json=%s want %s", tt.Name, out, tt.JSON)
+		}
+	}
+}
+
+func TestSchemaReaderNDJSON(t *testing.T) {
+	schema := &Schema{Columns: []Column{
+		{Name: "id", Type: TypeInt},
+		{Name: "joined", Type: TypeDate, Layouts: []string{"2006-01-02"}, Nullable: true},
+	}}
+	r := NewReader(strings.NewReader("joined,id\n2014-06-01,1\n,2\nx,3"))
+	var b bytes.Buffer
+	report, err := NewSchemaReader(r, schema).WriteNDJSON(&b)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := `{"id":1,"joined":"2014-06-01T00:00:00Z"}` + "\n" + `{"id":2,"joined":null}` + "\n"
+	if b.String() != want {
+		t.Errorf("ndjson=%s want %s", b.String(), want)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Line != 4 {
+		t.Errorf("violations=%v", report.Violations)
+	}
+}
+
+var jsonConverterTests = []struct {
+	Name    string
+	Input   string
+	Headers []string
+	Output  string
+}{
+	{
+		Name:   "NDJSON",
+		Input:  `{"id":1,"name":"ann"}` + "\n" + `{"name":"bob","id":2,"active":true}` + "\n",
+		Output: "id,name,active\n1,ann,\"\"\n2,bob,true\n",
+	},
+	{
+		Name:   "Array",
+		Input:  ` [{"id":1.5,"tags":["a", "b"]}, {"id":null}]`,
+		Output: "id,tags\n1.5,\"[\"\"a\"\",\"\"b\"\"]\"\n\"\",\"\"\n",
+	},
+	{
+		Name:   "Nested",
+		Input:  `{"id":1,"address":{"city":"Paris","geo":{"lat":48.8}}}`,
+		Output: "id,address.city,address.geo.lat\n1,Paris,48.8\n",
+	},
+	{
+		Name:    "Headers",
+		Input:   `{"id":1,"name":"ann","extra":"x"}{"name":"bob"}`,
+		Headers: []string{"name", "id"},
+		Output:  "name,id\nann,1\nbob,\"\"\n",
+	},
+}
+
+func TestJSONConverter(t *testing.T) {
+	for _, tt := range jsonConverterTests {
+		var b bytes.Buffer
+		c := NewJSONConverter()
+		c.Headers = tt.Headers
+		if err := c.Convert(strings.NewReader(tt.Input), NewWriter(&b)); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+	}
+	if err := NewJSONConverter().Convert(strings.NewReader(`[1]`), NewWriter(new(bytes.Buffer))); err == nil {
+		t.Errorf("expected error for non-object")
+	}
+}
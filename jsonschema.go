@@ -0,0 +1,203 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+// jsonSchema is the subset of a JSON Schema understood by ParseJSONSchema.
+type jsonSchema struct {
+	Type       interface{}                `json:"type"`
+	Format     string                     `json:"format"`
+	Properties map[string]json.RawMessage `json:"properties"`
+	Required   []string                   `json:"required"`
+	Enum       []json.RawMessage          `json:"enum"`
+	Pattern    string                     `json:"pattern"`
+	MinLength  *int                       `json:"minLength"`
+	MaxLength  *int                       `json:"maxLength"`
+	Minimum    *float64                   `json:"minimum"`
+	Maximum    *float64                   `json:"maximum"`
+}
+
+// ParseJSONSchema returns a Schema built from a JSON Schema document
+// describing a row as an object.  Each property becomes a column, in the
+// order of the document:
+//
+//   - "integer", "number" and "boolean" properties become TypeInt, TypeFloat
+//     and TypeBool columns;
+//   - "string" properties with the "date" or "date-time" format become TypeDate
+//     columns, and other strings become TypeString columns;
+//   - properties whose type includes "null" are Nullable;
+//   - properties that are not "required" are Optional and Nullable;
+//   - "pattern", "enum", "minimum", "maximum", "minLength" and "maxLength"
+//     become validators.
+//
+// Other keywords are ignored.  Nested objects and arrays are not supported.
+func ParseJSONSchema(data []byte) (*Schema, error) {
+	var doc jsonSchema
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if types, _ := jsonSchemaTypes(doc.Type); len(types) > 0 && types[0] != "object" {
+		return nil, fmt.Errorf("json schema: JSON Schema type %q is not an object", types[0])
+	}
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	names, err := jsonObjectKeys(raw.Properties)
+	if err != nil {
+		return nil, err
+	}
+	required := make(map[string]bool)
+	for _, name := range doc.Required {
+		required[name] = true
+	}
+	schema := new(Schema)
+	for _, name := range names {
+		column, err := jsonSchemaColumn(name, doc.Properties[name])
+		if err != nil {
+			return nil, err
+		}
+		if !required[name] {
+			column.Optional = true
+			column.Nullable = true
+		}
+		schema.Columns = append(schema.Columns, column)
+	}
+	return schema, nil
+}
+
+// jsonSchemaColumn returns the column described by the property name.
+func jsonSchemaColumn(name string, data json.RawMessage) (column Column, err error) {
+	var prop jsonSchema
+	if err = json.Unmarshal(data, &prop); err != nil {
+		return column, fmt.Errorf("json schema: property %q: %v", name, err)
+	}
+	types, err := jsonSchemaTypes(prop.Type)
+	if err != nil {
+		return column, fmt.Errorf("json schema: property %q: %v", name, err)
+	}
+	column.Name = name
+	column.Type = TypeString
+	for _, t := range types {
+		switch t {
+		case "null":
+			column.Nullable = true
+		case "integer":
+			column.Type = TypeInt
+		case "number":
+			column.Type = TypeFloat
+		case "boolean":
+			column.Type = TypeBool
+		case "string":
+			switch prop.Format {
+			case "date":
+				column.Type = TypeDate
+				column.Layouts = []string{"2006-01-02"}
+			case "date-time":
+				column.Type = TypeDate
+				column.Layouts = []string{time.RFC3339}
+			}
+		default:
+			return column, fmt.Errorf("json schema: property %q: unsupported type %q", name, t)
+		}
+	}
+
+	if prop.Pattern != "" {
+		re, err := regexp.Compile(prop.Pattern)
+		if err != nil {
+			return column, fmt.Errorf("json schema: property %q: %v", name, err)
+		}
+		column.Validators = append(column.Validators, Regexp(re))
+	}
+	if prop.Enum != nil {
+		var values []string
+		for _, value := range prop.Enum {
+			var s string
+			if json.Unmarshal(value, &s) != nil {
+				s = string(bytes.TrimSpace(value))
+			}
+			values = append(values, s)
+		}
+		column.Validators = append(column.Validators, OneOf(values...))
+	}
+	if prop.Minimum != nil || prop.Maximum != nil {
+		min, max := math.Inf(-1), math.Inf(1)
+		if prop.Minimum != nil {
+			min = *prop.Minimum
+		}
+		if prop.Maximum != nil {
+			max = *prop.Maximum
+		}
+		column.Validators = append(column.Validators, Range(min, max))
+	}
+	if prop.MinLength != nil {
+		column.Validators = append(column.Validators, MinLength(*prop.MinLength))
+	}
+	if prop.MaxLength != nil {
+		column.Validators = append(column.Validators, MaxLength(*prop.MaxLength))
+	}
+	return column, nil
+}
+
+// jsonSchemaTypes returns the value of a "type" keyword as a list.
+func jsonSchemaTypes(v interface{}) ([]string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		types := make([]string, len(v))
+		for i, t := range v {
+			s, ok := t.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid type %v", t)
+			}
+			types[i] = s
+		}
+		return types, nil
+	}
+	return nil, fmt.Errorf("invalid type %v", v)
+}
+
+// jsonObjectKeys returns the keys of the JSON object data in document order.
+func jsonObjectKeys(data json.RawMessage) (keys []string, err error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("json schema: properties is not an object")
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, token.(string))
+		var skip json.RawMessage
+		if err = dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testJSONSchema = `{
+  "type": "object",
+  "required": ["id", "email", "status"],
+  "properties": {
+    "id": {"type": "integer", "minimum": 1},
+    "email": {"type": "string", "pattern": "@", "maxLength": 20},
+    "status": {"enum": ["open", "closed"]},
+    "score": {"type": ["number", "null"]},
+    "joined": {"type": "string", "format": "date"},
+    "active": {"type": "boolean"}
+  }
+}`
+
+func TestParseJSONSchema(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(testJSONSchema))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []struct {
+		Name               string
+		Type               ColumnType
+		Nullable, Optional bool
+		Validators         int
+	}{
+		{"id", TypeInt, false, false, 1},
+		{"email", TypeString, false, false, 2},
+		{"status", TypeString, false, false, 1},
+		{"score", TypeFloat, true, true, 0},
+		{"joined", TypeDate, true, true, 0},
+		{"active", TypeBool, true, true, 0},
+	}
+	if len(schema.Columns) != len(want) {
+		t.Fatalf("columns=%+v", schema.Columns)
+	}
+	for i, w := range want {
+		c := schema.Columns[i]
+		if c.Name != w.Name || c.Type != w.Type || c.Nullable != w.Nullable || c.Optional != w.Optional || len(c.Validators) != w.Validators {
+			t.Errorf("column %d=%+v want %+v", i, c, w)
+		}
+	}
+
+	r := NewReader(strings.NewReader("id,email,status,joined\n1,a@b.c,open,2014-06-01\n0,nope,pending,"))
+	records, report, err := NewSchemaReader(r, schema).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 1 || !records[0]["joined"].(time.Time).Equal(time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("records=%v", records)
+	}
+	if counts := report.CountByRule(); counts[RuleRange] != 1 || counts[RuleRegexp] != 1 || counts[RuleEnum] != 1 {
+		t.Errorf("violations=%v", report.Violations)
+	}
+}
+
+func TestParseJSONSchemaErrors(t *testing.T) {
+	for _, doc := range []string{
+		`{"type": "array"}`,
+		`{"properties": {"tags": {"type": "array"}}}`,
+		`{"properties": {"id": {"type": "string", "pattern": "("}}}`,
+		`{"properties": [1]}`,
+		`not json`,
+	} {
+		if _, err := ParseJSONSchema([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", doc)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A LazyRecord is a record read with ReadLazy.  Its fields are only split
+// and unquoted when they are accessed, so that reading a few columns of
+// wide records skips most of the parsing work.  Errors in the quoting of a
+// field are returned when it is accessed.
+type LazyRecord struct {
+	Line int // line where the record started
+
+	raw    string   // text of the record, without its line end
+	n      int      // number of fields
+	starts []int    // start in raw of the fields found so far
+	fields []string // fields, if the record was read with Read
+
+	comma        string
+	lazyQuotes   bool
+	trimLeading  bool
+	trimTrailing bool
+}
+
+// ReadLazy reads one record from r like Read, finding where it ends and
+// its number of fields without parsing them.  The header row is the first
+// record.
+//
+// Transforms, validators, selected columns, ColumnMapping, Filter, Stats,
+// fixed-width, backslash-escaped and MultiReader records are parsed with
+// Read, so they save nothing.
+func (r *Reader) ReadLazy() (record *LazyRecord, err error) {
+	if r.needsHeaders() || r.indexTransforms != nil || r.Filter != nil || r.Stats != nil ||
+		r.sources != nil || r.FixedColumns != nil || r.BackslashEscapes || r.copyFormat != 0 {
+		fields, err := r.Read()
+		if fields == nil {
+			return nil, err
+		}
+		return &LazyRecord{Line: r.recordLine, n: len(fields), fields: fields}, err
+	}
+
+	if r.atLimit() {
+		return nil, io.EOF
+	}
+	var raw []byte
+	empty := false // a blank line read with EmptyBlankLines
+	for raw == nil && !empty {
+		skip, err := r.startRecord()
+		if !skip {
+			var blank bool
+			if blank, err = r.readBlankLine(); blank {
+				empty = err == nil && r.BlankLines == EmptyBlankLines
+			} else {
+				raw, err = r.scanRecord()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.records++
+	if empty {
+		r.returned++
+		if r.pastMaxRows() {
+			return nil, ErrMaxRows
+		}
+		return &LazyRecord{Line: r.recordLine, fields: []string{}}, nil
+	}
+
+	record = &LazyRecord{
+		Line:         r.recordLine,
+		raw:          string(raw),
+		n:            r.scanner.commas + 1,
+		starts:       []int{0},
+		comma:        string(r.scanner.comma),
+		lazyQuotes:   r.LazyQuotes,
+		trimLeading:  r.trimLeading(),
+		trimTrailing: r.trimTrailing(),
+	}
+	if r.FieldsPerRecord > 0 {
+		if record.n != r.FieldsPerRecord {
+			r.column = 0 // report at start of record
+			return record, r.error(ErrFieldCount)
+		}
+	} else if r.FieldsPerRecord == 0 {
+		r.FieldsPerRecord, r.inferred = record.n, true
+	}
+	r.returned++
+	if r.pastMaxRows() {
+		return nil, ErrMaxRows
+	}
+	return record, nil
+}
+
+// scanRecord reads the text of a record into r.field, following its quoting
+// with r.scanner to find where it ends, and returns it without its line
+// end.  It returns nil for blank lines.
+func (r *Reader) scanRecord() ([]byte, error) {
+	if r.scanner == nil {
+		r.scanner = newRecordScanner(r)
+	}
+	s := r.scanner
+	s.start()
+	r.field.Reset()
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		r.offset += int64(len(chunk))
+		end := false
+		if r.field.Len() == 0 && err == nil && bytes.IndexByte(chunk, '"') < 0 {
+			// A whole line without quotes.
+			s.commas = bytes.Count(chunk, s.comma)
+			end = true
+		} else {
+			for _, b := range chunk {
+				end = s.scan(b)
+			}
+		}
+		r.field.Write(chunk)
+		if end || err == io.EOF {
+			break
+		}
+		if err == nil {
+			r.line++ // a line end within quotes
+		} else if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+
+	raw := r.field.Bytes()
+	raw = bytes.TrimSuffix(raw, []byte{'\n'})
+	raw = bytes.TrimSuffix(raw, []byte{'\r'})
+	if len(raw) == 0 {
+		return nil, nil // blank line
+	}
+	return raw, nil
+}
+
+// Len returns the number of fields of rec.
+func (rec *LazyRecord) Len() int {
+	return rec.n
+}
+
+// Field returns field i of rec, parsing it and, if it was not accessed
+// before, finding where the fields before it start.
+func (rec *LazyRecord) Field(i int) (string, error) {
+	if i < 0 || i >= rec.n {
+		return "", &ParseError{Line: rec.Line, Err: ErrFieldCount}
+	}
+	if rec.fields != nil {
+		return rec.fields[i], nil
+	}
+	for len(rec.starts) <= min(i+1, rec.n-1) {
+		start := rec.starts[len(rec.starts)-1]
+		rec.starts = append(rec.starts, rec.fieldEnd(start)+len(rec.comma))
+	}
+	end := len(rec.raw)
+	if i+1 < rec.n {
+		end = rec.starts[i+1] - len(rec.comma)
+	}
+	return rec.parseField(rec.starts[i], end)
+}
+
+// Fields returns all the fields of rec.
+func (rec *LazyRecord) Fields() ([]string, error) {
+	fields := make([]string, rec.n)
+	for i := range fields {
+		field, err := rec.Field(i)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+// fieldEnd returns the end of the field starting at start in rec.raw, which
+// is followed by a delimiter.
+func (rec *LazyRecord) fieldEnd(start int) int {
+	text := rec.raw[start:]
+	first := text
+	if rec.trimLeading {
+		first = strings.TrimLeftFunc(text, unicode.IsSpace)
+	}
+	if !strings.HasPrefix(first, `"`) {
+		if i := strings.Index(text, rec.comma); i >= 0 {
+			return start + i
+		}
+		return len(rec.raw)
+	}
+	s := &recordScanner{comma: []byte(rec.comma), quotes: true, lazyQuotes: rec.lazyQuotes, trimLeading: rec.trimLeading}
+	s.start()
+	for i := 0; i < len(text); i++ {
+		s.scan(text[i])
+		if s.commas > 0 {
+			return start + i + 1 - len(rec.comma)
+		}
+	}
+	return len(rec.raw)
+}
+
+// parseField parses the field of rec.raw from start to end, like the
+// parseField method of a Reader.
+func (rec *LazyRecord) parseField(start, end int) (string, error) {
+	text := rec.raw[start:end]
+	if rec.trimLeading {
+		trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+		start += len(text) - len(trimmed)
+		text = trimmed
+	}
+
+	if !strings.HasPrefix(text, `"`) {
+		if i := strings.IndexByte(text, '"'); i >= 0 && !rec.lazyQuotes {
+			return "", rec.error(ErrBareQuote, start+i)
+		}
+		if rec.trimTrailing {
+			text = strings.TrimRightFunc(text, unicode.IsSpace)
+		}
+		return validField(text), nil
+	}
+
+	var field strings.Builder
+	for i := 1; ; {
+		j := strings.IndexByte(text[i:], '"')
+		if j < 0 {
+			if !rec.lazyQuotes {
+				return "", rec.error(ErrQuote, end)
+			}
+			field.WriteString(text[i:])
+			break
+		}
+		field.WriteString(text[i : i+j])
+		i += j + 1
+		rest := text[i:]
+		if rest == "" || (rec.trimTrailing && strings.TrimLeftFunc(rest, unicode.IsSpace) == "") {
+			break
+		}
+		if rest[0] == '"' {
+			field.WriteByte('"')
+			i++
+			continue
+		}
+		if !rec.lazyQuotes {
+			pos := i - 1 // at the closing quote
+			if r1, _ := utf8.DecodeRuneInString(rest); rec.trimTrailing && unicode.IsSpace(r1) {
+				pos = i + len(rest) - len(strings.TrimLeftFunc(rest, unicode.IsSpace)) - 1
+			}
+			return "", rec.error(ErrQuote, start+pos)
+		}
+		field.WriteByte('"') // accept the bare quote
+	}
+	return validField(strings.ReplaceAll(field.String(), "\r\n", "\n")), nil
+}
+
+// validField replaces the invalid UTF-8 bytes of field like readRune.
+func validField(field string) string {
+	if utf8.ValidString(field) {
+		return field
+	}
+	var valid strings.Builder
+	for _, r1 := range field {
+		valid.WriteRune(r1)
+	}
+	return valid.String()
+}
+
+// error creates a new ParseError for err at pos in rec.raw.
+func (rec *LazyRecord) error(err error, pos int) error {
+	before := rec.raw[:pos]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return &ParseError{
+		Line:   rec.Line + strings.Count(before, "\n"),
+		Column: utf8.RuneCountInString(before[lineStart:]),
+		Err:    err,
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readLazyAll reads the records of r with ReadLazy and Fields until the
+// first error.
+func readLazyAll(r *Reader) (records [][]string, err error) {
+	for {
+		record, err := r.ReadLazy()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		fields, err := record.Fields()
+		if err != nil {
+			return records, err
+		}
+		records = append(records, fields)
+	}
+}
+
+// readAllUntilError reads the records of r with Read until the first error.
+func readAllUntilError(r *Reader) (records [][]string, err error) {
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+func TestReadLazy(t *testing.T) {
+	for _, tt := range readTests {
+		if tt.UseHeaders || tt.UseHeadersAndErrs || tt.UseNullable || tt.Select != nil || tt.SelectIndexes != nil ||
+			tt.ColumnMapping != nil || tt.Filter != nil || tt.FilterMap != nil || tt.Transforms != nil ||
+			tt.IndexTransforms != nil || tt.SkipLineOnErr {
+			continue
+		}
+		newReader := func() *Reader {
+			r := NewReader(strings.NewReader(tt.Input))
+			if tt.Comma != 0 {
+				r.Comma = tt.Comma
+			}
+			r.Comment = tt.Comment
+			r.BlankLines = tt.BlankLines
+			r.WhitespaceBlank = tt.WhitespaceBlank
+			r.FieldsPerRecord = -1
+			if tt.UseFieldsPerRecord {
+				r.FieldsPerRecord = tt.FieldsPerRecord
+			}
+			r.LazyQuotes = tt.LazyQuotes
+			r.TrimLeadingSpace = tt.TrimLeadingSpace
+			r.TrimTrailingSpace = tt.TrimTrailingSpace
+			r.TrimFields = tt.TrimFields
+			return r
+		}
+		want, wantErr := readAllUntilError(newReader())
+		out, err := readLazyAll(newReader())
+		if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			t.Errorf("%s: error %v, want %v", tt.Name, err, wantErr)
+		} else if !reflect.DeepEqual(out, want) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, want)
+		}
+	}
+}
+
+func TestReadLazyMaxRows(t *testing.T) {
+	r := NewReader(strings.NewReader("a\n1\n2\n3\n4\n"))
+	r.MaxRows = 2
+	records, err := readLazyAll(r)
+	if want := [][]string{{"a"}, {"1"}, {"2"}}; err != ErrMaxRows || !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q, err=%v, want %q, ErrMaxRows", records, err, want)
+	}
+	if _, err := r.ReadLazy(); err != io.EOF {
+		t.Errorf("error %v after ErrMaxRows, want io.EOF", err)
+	}
+}
+
+func TestLazyRecordField(t *testing.T) {
+	r := NewReader(strings.NewReader("a,\"b,\"\"c\"\"\n\",x\"y,d\n"))
+	r.LazyQuotes = true
+	record, err := r.ReadLazy()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if record.Len() != 4 || record.Line != 1 {
+		t.Errorf("len=%d, line=%d", record.Len(), record.Line)
+	}
+	for _, i := range []int{3, 1, 0, 2} {
+		field, err := record.Field(i)
+		want := []string{"a", "b,\"c\"\n", "x\"y", "d"}[i]
+		if err != nil || field != want {
+			t.Errorf("Field(%d)=%q, err=%v, want %q", i, field, err, want)
+		}
+	}
+	if _, err := record.Field(4); err == nil {
+		t.Errorf("Field(4) did not fail")
+	}
+}
+
+func BenchmarkReadLazy(b *testing.B) {
+	fields := make([]string, 200)
+	for i := range fields {
+		fields[i] = fmt.Sprintf("field%d", i)
+	}
+	input := strings.Repeat(strings.Join(fields, ",")+"\n", 200)
+	b.SetBytes(int64(len(input)))
+	for b.Loop() {
+		r := NewReader(strings.NewReader(input))
+		for {
+			record, err := r.ReadLazy()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, i := range []int{3, 50, 150} {
+				if _, err := record.Field(i); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
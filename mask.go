@@ -0,0 +1,85 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// HashSHA256 returns a TransformFunc replacing each field with the hex
+// encoded HMAC-SHA256 of the field keyed by salt, to pseudonymize a column:
+// equal fields hash to equal values, so that the column can still be joined
+// on, but the fields cannot be recovered without the salt.  Empty fields are
+// left empty.
+//
+//	reader.Transform("email", bettercsv.HashSHA256(salt))
+func HashSHA256(salt []byte) TransformFunc {
+	salt = append([]byte(nil), salt...)
+	return func(field string) string {
+		if field == "" {
+			return ""
+		}
+		mac := hmac.New(sha256.New, salt)
+		mac.Write([]byte(field))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// Redact returns a TransformFunc replacing the runes of each field with
+// mask, except its first keepStart and last keepEnd runes, as in
+// "************4242".  Fields of no more than keepStart+keepEnd runes are
+// masked entirely, so that short fields are not left in the clear.
+//
+//	writer.Transform("card", bettercsv.Redact(0, 4, '*'))
+func Redact(keepStart, keepEnd int, mask rune) TransformFunc {
+	keepStart, keepEnd = max(keepStart, 0), max(keepEnd, 0)
+	return func(field string) string {
+		n := utf8.RuneCountInString(field)
+		if n <= keepStart+keepEnd {
+			return strings.Repeat(string(mask), n)
+		}
+		var b strings.Builder
+		b.Grow(len(field))
+		i := 0
+		for _, r1 := range field {
+			if i < keepStart || i >= n-keepEnd {
+				b.WriteRune(r1)
+			} else {
+				b.WriteRune(mask)
+			}
+			i++
+		}
+		return b.String()
+	}
+}
+
+// Tokenize returns a TransformFunc replacing each field with the token
+// returned by token, such as an identifier issued by a tokenization service.
+// token is called once for each distinct field and its tokens are
+// remembered, so that equal fields get equal tokens.  Empty fields are left
+// empty.  The TransformFunc is safe for concurrent use, and can be shared
+// between the readers and writers of an extract.
+func Tokenize(token func(field string) string) TransformFunc {
+	var mu sync.Mutex
+	tokens := make(map[string]string)
+	return func(field string) string {
+		if field == "" {
+			return ""
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		t, ok := tokens[field]
+		if !ok {
+			t = token(field)
+			tokens[field] = t
+		}
+		return t
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var maskTests = []struct {
+	Name   string
+	Fn     TransformFunc
+	Input  string
+	Output string
+}{
+	{
+		Name:   "HashSHA256",
+		Fn:     HashSHA256([]byte("salt")),
+		Input:  "jane@example.com",
+		Output: "a100ddb709a49d478230dc673238267a10448f7583d81fd97775cd71c9ee3a76",
+	},
+	{
+		Name:   "HashSHA256Empty",
+		Fn:     HashSHA256([]byte("salt")),
+		Input:  "",
+		Output: "",
+	},
+	{
+		Name:   "RedactEnd",
+		Fn:     Redact(0, 4, '*'),
+		Input:  "4111111111111111",
+		Output: "************1111",
+	},
+	{
+		Name:   "RedactStartAndEnd",
+		Fn:     Redact(1, 4, '•'),
+		Input:  "Jöhn Smith",
+		Output: "J•••••mith",
+	},
+	{
+		Name:   "RedactShort",
+		Fn:     Redact(2, 2, '*'),
+		Input:  "abcd",
+		Output: "****",
+	},
+	{
+		Name:   "RedactNegative",
+		Fn:     Redact(-1, -1, 'x'),
+		Input:  "abc",
+		Output: "xxx",
+	},
+	{
+		Name:   "Tokenize",
+		Fn:     Tokenize(func(field string) string { return "tok_" + strings.ToLower(field) }),
+		Input:  "ABC",
+		Output: "tok_abc",
+	},
+	{
+		Name:   "TokenizeEmpty",
+		Fn:     Tokenize(func(field string) string { return "tok" }),
+		Input:  "",
+		Output: "",
+	},
+}
+
+func TestMask(t *testing.T) {
+	for _, tt := range maskTests {
+		if out := tt.Fn(tt.Input); out != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, tt.Output)
+		}
+	}
+}
+
+func TestHashSHA256Salt(t *testing.T) {
+	salt := []byte("salt")
+	hash := HashSHA256(salt)
+	a := hash("jane")
+	salt[0] = 'S'
+	if b := hash("jane"); a != b {
+		t.Errorf("hash changed with salt: %q, %q", a, b)
+	}
+	if b := HashSHA256(salt)("jane"); a == b {
+		t.Errorf("different salts hash to %q", a)
+	}
+}
+
+func TestTokenizeOnce(t *testing.T) {
+	var calls int
+	tokenize := Tokenize(func(field string) string {
+		calls++
+		return fmt.Sprint("t", calls)
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokenize("a")
+			tokenize("b")
+		}()
+	}
+	wg.Wait()
+	if calls != 2 {
+		t.Errorf("calls=%d want 2", calls)
+	}
+	if a, b := tokenize("a"), tokenize("b"); a == b {
+		t.Errorf("tokens %q and %q are equal", a, b)
+	}
+}
+
+func TestMaskReadWrite(t *testing.T) {
+	r := NewReader(strings.NewReader("name,card\nJane,4111111111111111\nBob,5500000000000004\n"))
+	r.Transform("name", Redact(1, 0, '*'))
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	w.Headers = []string{"name", "card"}
+	w.AutoHeader = true
+	w.Transform("card", Redact(0, 4, '*'))
+	if _, err := r.Headers(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := r.ReadAllToMaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteAllMaps(records); err != nil {
+		t.Fatal(err)
+	}
+	out := "name,card\nJ***,************1111\nB**,************0004\n"
+	if b.String() != out {
+		t.Errorf("out=%q want %q", b.String(), out)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import "io"
+
+// Metrics counts what the reading methods of a Reader do, for services
+// exporting them to a monitoring system such as Prometheus or expvar.  The
+// methods are called with increments as records are read.
+type Metrics interface {
+	AddRecords(n int)      // records parsed, including the header row
+	AddBytes(n int64)      // bytes of input consumed, after decompression
+	AddError(kind string)  // an error of the kind returned by ErrorKind
+	AddSkippedLines(n int) // records dropped by Filter or skipped on error with SkipLineOnErr
+}
+
+// ErrorKind returns a name for the kind of err, to count errors by kind with
+// few distinct names: the Rule of a *Violation, the message of the
+// underlying error of a *ParseError, such as "wrong number of fields in
+// line", and "read" for other errors.
+func ErrorKind(err error) string {
+	switch err := err.(type) {
+	case *Violation:
+		return err.Rule
+	case *ParseError:
+		return err.Err.Error()
+	default:
+		return "read"
+	}
+}
+
+// meter adds to r.Metrics the records and bytes read since the last call,
+// and err.
+func (r *Reader) meter(err error) {
+	if n := r.records - r.metered.Records; n > 0 {
+		r.Metrics.AddRecords(n)
+	}
+	if n := r.offset - r.metered.Bytes; n > 0 {
+		r.Metrics.AddBytes(n)
+	}
+	r.metered.Records, r.metered.Bytes = r.records, r.offset
+	if err != nil && err != io.EOF {
+		r.Metrics.AddError(ErrorKind(err))
+		if r.skipError(err) {
+			r.Metrics.AddSkippedLines(1)
+		}
+	}
+}
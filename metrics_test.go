@@ -0,0 +1,95 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// countingMetrics is a Metrics keeping the totals.
+type countingMetrics struct {
+	Records, SkippedLines int
+	Bytes                 int64
+	Errors                map[string]int
+}
+
+func (m *countingMetrics) AddRecords(n int)      { m.Records += n }
+func (m *countingMetrics) AddBytes(n int64)      { m.Bytes += n }
+func (m *countingMetrics) AddSkippedLines(n int) { m.SkippedLines += n }
+func (m *countingMetrics) AddError(kind string) {
+	if m.Errors == nil {
+		m.Errors = make(map[string]int)
+	}
+	m.Errors[kind]++
+}
+
+var metricsTests = []struct {
+	Name      string
+	Input     string
+	Configure func(*Reader)
+	Output    countingMetrics
+}{
+	{
+		Name:   "Simple",
+		Input:  "a,b\n1,2\n3,4\n",
+		Output: countingMetrics{Records: 3, Bytes: 12},
+	},
+	{
+		Name:   "Errors",
+		Input:  "a,b\n1,2\n3\n4,x\"\n5,6\n",
+		Output: countingMetrics{Records: 4, Bytes: 19, Errors: map[string]int{ErrFieldCount.Error(): 1, ErrBareQuote.Error(): 1}, SkippedLines: 2},
+	},
+	{
+		Name:      "Filter",
+		Input:     "a,b\n1,2\n3,4\n",
+		Configure: func(r *Reader) { r.Filter = func(record []string) bool { return record[0] != "1" } },
+		Output:    countingMetrics{Records: 3, Bytes: 12, SkippedLines: 1},
+	},
+	{
+		Name:      "Violation",
+		Input:     "a,b\n1,2\n,4\n",
+		Configure: func(r *Reader) { r.Validate("a", Required()) },
+		Output:    countingMetrics{Records: 3, Bytes: 11, Errors: map[string]int{RuleRequired: 1}, SkippedLines: 1},
+	},
+	{
+		Name:      "MaxRows",
+		Input:     "a\n1\n2\n3\n",
+		Configure: func(r *Reader) { r.MaxRows = 1 },
+		Output:    countingMetrics{Records: 3, Bytes: 6, Errors: map[string]int{"read": 1}},
+	},
+}
+
+func TestMetrics(t *testing.T) {
+	for _, tt := range metricsTests {
+		r := NewReader(strings.NewReader(tt.Input))
+		if tt.Configure != nil {
+			tt.Configure(r)
+		}
+		m := new(countingMetrics)
+		r.Metrics = m
+		r.ReadAllWithErrors()
+		if !reflect.DeepEqual(*m, tt.Output) {
+			t.Errorf("%s: out=%+v want %+v", tt.Name, *m, tt.Output)
+		}
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		Err  error
+		Kind string
+	}{
+		{&ParseError{Line: 2, Err: ErrQuote}, ErrQuote.Error()},
+		{&Violation{Line: 2, Rule: RuleUnique, Err: ErrDuplicate}, RuleUnique},
+		{ErrHeaderMismatch, "read"},
+	}
+	for _, tt := range tests {
+		if kind := ErrorKind(tt.Err); kind != tt.Kind {
+			t.Errorf("ErrorKind(%v)=%q want %q", tt.Err, kind, tt.Kind)
+		}
+	}
+}
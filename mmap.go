@@ -0,0 +1,83 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"os"
+)
+
+// A File is a file opened with NewReaderFromFile.  It is an io.ReaderAt of
+// the content of the file, which can also be passed to the ReadAll method
+// of a ParallelReader.
+type File struct {
+	f    *os.File
+	data []byte // mapping of the file, nil if it is read with buffered IO
+	size int64
+}
+
+// NewReaderFromFile opens the named file and returns a Reader of it, with
+// the File it reads from.  If mmap is true, the file is memory-mapped and
+// parsed over the mapping, without read system calls; if it can not be
+// mapped, as on platforms without mmap support or for empty files, it is
+// read with buffered IO.  The Reader is seekable, so SeekTo, BuildIndex and
+// ReadAt can be used.  The caller must close the File once done reading.
+func NewReaderFromFile(name string, mmap bool) (*Reader, *File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	file := &File{f: f, size: info.Size()}
+	if mmap && file.size > 0 && int64(int(file.size)) == file.size {
+		// Mapping errors are not reported, the file is read instead.
+		file.data, _ = mmapFile(f, int(file.size))
+	}
+	return NewReader(io.NewSectionReader(file, 0, file.size)), file, nil
+}
+
+// Mapped reports whether f is memory-mapped.
+func (f *File) Mapped() bool {
+	return f.data != nil
+}
+
+// Size returns the size of f in bytes.
+func (f *File) Size() int64 {
+	return f.size
+}
+
+// ReadAt reads len(p) bytes of f at off, from the mapping if f is mapped.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if f.data == nil {
+		return f.f.ReadAt(p, off)
+	}
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close unmaps and closes f.  Reading f or its Reader afterwards returns an
+// error.
+func (f *File) Close() error {
+	var err error
+	if f.data != nil {
+		data := f.data
+		f.data = nil
+		err = munmapFile(data)
+	}
+	if cerr := f.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
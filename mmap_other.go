@@ -0,0 +1,25 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package bettercsv
+
+import (
+	"errors"
+	"os"
+)
+
+// errNoMmap is returned by mmapFile on platforms without mmap support.
+var errNoMmap = errors.New("mmap not supported")
+
+// mmapFile returns errNoMmap, so that files are read with buffered IO.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, errNoMmap
+}
+
+// munmapFile is never called, as mmapFile maps nothing.
+func munmapFile(data []byte) error {
+	return nil
+}
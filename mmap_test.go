@@ -0,0 +1,95 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var readerFromFileTests = []struct {
+	Name   string
+	Input  string
+	Mmap   bool
+	Mapped bool
+}{
+	{Name: "Buffered", Input: "a,b\n1,\"x\ny\"\n2,z\n"},
+	{Name: "Mmap", Input: "a,b\n1,\"x\ny\"\n2,z\n", Mmap: true, Mapped: runtime.GOOS != "windows" && runtime.GOOS != "plan9"},
+	{Name: "MmapEmpty", Input: "", Mmap: true},
+	{Name: "MmapUnterminated", Input: "a,b\n1,2", Mmap: true, Mapped: runtime.GOOS != "windows" && runtime.GOOS != "plan9"},
+}
+
+func TestNewReaderFromFile(t *testing.T) {
+	for _, tt := range readerFromFileTests {
+		name := filepath.Join(t.TempDir(), "data.csv")
+		if err := os.WriteFile(name, []byte(tt.Input), 0666); err != nil {
+			t.Fatal(err)
+		}
+		want, _ := NewReader(strings.NewReader(tt.Input)).ReadAll()
+
+		r, f, err := NewReaderFromFile(name, tt.Mmap)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.Name, err)
+		}
+		if f.Mapped() != tt.Mapped {
+			t.Errorf("%s: Mapped()=%v want %v", tt.Name, f.Mapped(), tt.Mapped)
+		}
+		if f.Size() != int64(len(tt.Input)) {
+			t.Errorf("%s: Size()=%d want %d", tt.Name, f.Size(), len(tt.Input))
+		}
+		out, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if !reflect.DeepEqual(out, want) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, want)
+		}
+
+		p := &ParallelReader{ChunkSize: 4}
+		out, err = p.ReadAll(f, f.Size())
+		if err != nil {
+			t.Errorf("%s: parallel: unexpected error %v", tt.Name, err)
+		} else if !reflect.DeepEqual(out, want) {
+			t.Errorf("%s: parallel: out=%q want %q", tt.Name, out, want)
+		}
+
+		if err := f.Close(); err != nil {
+			t.Errorf("%s: Close: unexpected error %v", tt.Name, err)
+		}
+		if _, err := f.ReadAt(make([]byte, 1), 0); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("%s: ReadAt after Close: error %v, want %v", tt.Name, err, os.ErrClosed)
+		}
+	}
+}
+
+func TestNewReaderFromFileSeek(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(name, []byte("a,b\n1,2\n3,4\n5,6\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	r, f, err := NewReaderFromFile(name, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := r.SeekTo(9); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	record, err := r.Read()
+	if err != nil || !reflect.DeepEqual(record, []string{"5", "6"}) {
+		t.Errorf("record=%q err=%v want [5 6]", record, err)
+	}
+}
+
+func TestNewReaderFromFileMissing(t *testing.T) {
+	_, _, err := NewReaderFromFile(filepath.Join(t.TempDir(), "missing.csv"), true)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("error %v, want %v", err, os.ErrNotExist)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package bettercsv
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the size bytes of f for reading.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmapFile unmaps data, mapped by mmapFile.
+func munmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,223 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+)
+
+// ErrHeaderMismatch is returned by a strict MultiFileReader for files whose
+// header row differs from that of the first file.
+var ErrHeaderMismatch = errors.New("header row does not match")
+
+// A MultiFileReader reads the CSV files matching a glob pattern in an fs.FS,
+// in lexical order, as a single stream of records.
+//
+// The headers of the stream are the union of the headers of the files, in
+// the order they are first seen, and the columns a file does not have are
+// empty in its records.  If Strict is true, every file must have the same
+// header row as the first one instead.
+//
+// If Configure is not nil, it is called with the Reader of each file before
+// it is read, to set options such as Comma.  Errors other than
+// ErrHeaderMismatch are returned as they are by the Reader of the file; if
+// it has SkipLineOnErr set, reading can continue.
+type MultiFileReader struct {
+	Strict    bool          // require the same header row in every file
+	Configure func(*Reader) // configures the Reader of each file
+
+	fsys    fs.FS
+	names   []string
+	headers []string
+
+	next    int     // index of the next file in names
+	file    fs.File // file being read
+	reader  *Reader // Reader of file
+	columns []int   // union column of each column of file
+}
+
+// NewMultiFileReader returns a MultiFileReader reading the files of fsys
+// matching pattern, whose syntax is that of path.Match.  It is an error for
+// no file to match.
+func NewMultiFileReader(fsys fs.FS, pattern string) (*MultiFileReader, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: %w", pattern, fs.ErrNotExist)
+	}
+	return &MultiFileReader{fsys: fsys, names: names}, nil
+}
+
+// Files returns the names of the files read, in order.
+func (m *MultiFileReader) Files() []string {
+	return m.names
+}
+
+// File returns the name of the file of the last record read.
+func (m *MultiFileReader) File() string {
+	if m.next == 0 {
+		return ""
+	}
+	return m.names[m.next-1]
+}
+
+// Headers returns the headers of the stream.  The first call reads the
+// header row of every file.
+func (m *MultiFileReader) Headers() ([]string, error) {
+	if m.headers != nil {
+		return m.headers, nil
+	}
+	var headers []string
+	for _, name := range m.names {
+		fileHeaders, err := m.readHeaders(name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case headers == nil:
+			headers = slices.Clone(fileHeaders)
+		case m.Strict:
+			if !slices.Equal(headers, fileHeaders) {
+				return nil, fmt.Errorf("%s: %w", name, ErrHeaderMismatch)
+			}
+		default:
+			for _, h := range fileHeaders {
+				if !slices.Contains(headers, h) {
+					headers = append(headers, h)
+				}
+			}
+		}
+	}
+	m.headers = headers
+	return headers, nil
+}
+
+// readHeaders returns the header row of the named file.
+func (m *MultiFileReader) readHeaders(name string) ([]string, error) {
+	f, err := m.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	headers, err := m.newReader(f).Headers()
+	if err == io.EOF {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return headers, nil
+}
+
+// newReader returns a configured Reader for f.
+func (m *MultiFileReader) newReader(f fs.File) *Reader {
+	r := NewReader(f)
+	if m.Configure != nil {
+		m.Configure(r)
+	}
+	return r
+}
+
+// Read reads one record, with the fields in the order of Headers.  Header
+// rows are not returned.  Read returns io.EOF once every file is read.
+func (m *MultiFileReader) Read() (record []string, err error) {
+	headers, err := m.Headers()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if m.reader == nil {
+			if m.next == len(m.names) {
+				return nil, io.EOF
+			}
+			if err := m.open(m.names[m.next]); err != nil {
+				return nil, err
+			}
+			m.next++
+		}
+		fields, err := m.reader.readNew()
+		if err == io.EOF {
+			m.Close()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		record = make([]string, len(headers))
+		for i, field := range fields {
+			if i < len(m.columns) {
+				record[m.columns[i]] = field
+			}
+		}
+		return record, nil
+	}
+}
+
+// open starts reading the named file.
+func (m *MultiFileReader) open(name string) error {
+	f, err := m.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	r := m.newReader(f)
+	fileHeaders, err := r.Headers()
+	if err != nil && err != io.EOF {
+		f.Close()
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	m.columns = make([]int, len(fileHeaders))
+	for i, h := range fileHeaders {
+		m.columns[i] = slices.Index(m.headers, h)
+	}
+	m.file, m.reader = f, r
+	return nil
+}
+
+// ReadToMap reads one record as a map of the headers to the fields.
+func (m *MultiFileReader) ReadToMap() (recordMap map[string]string, err error) {
+	record, err := m.Read()
+	if err != nil {
+		return nil, err
+	}
+	recordMap = make(map[string]string, len(record))
+	for i, field := range record {
+		recordMap[m.headers[i]] = field
+	}
+	return recordMap, nil
+}
+
+// ReadAll reads all the remaining records.  Errors of files whose Reader has
+// SkipLineOnErr set are skipped.
+func (m *MultiFileReader) ReadAll() (records [][]string, err error) {
+	for {
+		record, err := m.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			if m.reader != nil && m.reader.skipError(err) {
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// Close closes the file being read.
+func (m *MultiFileReader) Close() error {
+	if m.file == nil {
+		return nil
+	}
+	err := m.file.Close()
+	m.file, m.reader = nil, nil
+	return err
+}
@@ -0,0 +1,56 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+var dailyFiles = fstest.MapFS{
+	"2014-06-02.csv": {Data: []byte("id;name\n3;cy\n")},
+	"2014-06-01.csv": {Data: []byte("id;name\n1;ann\n2;bob\n")},
+	"2014-06-03.csv": {Data: []byte("name;email;id\ndan;d@x.com;4\n")},
+	"notes.txt":      {Data: []byte("not csv")},
+}
+
+func TestMultiFileReader(t *testing.T) {
+	m, err := NewMultiFileReader(dailyFiles, "2014-06-*.csv")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	m.Configure = func(r *Reader) { r.Comma = ';' }
+	records, err := m.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := [][]string{{"1", "ann", ""}, {"2", "bob", ""}, {"3", "cy", ""}, {"4", "dan", "d@x.com"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+	if headers, _ := m.Headers(); !reflect.DeepEqual(headers, []string{"id", "name", "email"}) {
+		t.Errorf("headers=%q", headers)
+	}
+
+	m, _ = NewMultiFileReader(dailyFiles, "2014-06-*.csv")
+	m.Configure = func(r *Reader) { r.Comma = ';' }
+	if record, _ := m.ReadToMap(); record["name"] != "ann" || m.File() != "2014-06-01.csv" {
+		t.Errorf("record=%q from %q", record, m.File())
+	}
+	m.Close()
+
+	m, _ = NewMultiFileReader(dailyFiles, "2014-06-*.csv")
+	m.Configure = func(r *Reader) { r.Comma = ';' }
+	m.Strict = true
+	if _, err := m.Read(); !errors.Is(err, ErrHeaderMismatch) || err.Error() != "2014-06-03.csv: header row does not match" {
+		t.Errorf("error %v, want ErrHeaderMismatch", err)
+	}
+
+	if _, err := NewMultiFileReader(dailyFiles, "*.tsv"); err == nil {
+		t.Errorf("expected error for no matches")
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"slices"
+	"strings"
+)
+
+// MultiReader returns a Reader that reads the CSV inputs one after the
+// other, as a single file.  The first record of each input after the first
+// one is skipped when it repeats the first record of the first input, so that
+// files sharing a header row read as one file with one header row.  Line
+// numbers in errors start again at 1 in each input.
+func MultiReader(readers ...io.Reader) *Reader {
+	if len(readers) == 0 {
+		return NewReader(strings.NewReader(""))
+	}
+	r := NewReader(readers[0])
+	r.sources = append([]io.Reader{}, readers[1:]...)
+	return r
+}
+
+// nextSource is called with each record parsed by a MultiReader.  It moves
+// to the next input at the end of the current one, and reports whether the
+// record must be skipped: either the end of an input or a repeated header
+// row.
+func (r *Reader) nextSource(record []string, err error) (skip bool) {
+	if record == nil {
+		if err != io.EOF || len(r.sources) == 0 {
+			return false
+		}
+		r.r = getBufioReader(r.sources[0])
+		r.detect = true
+		r.sources = r.sources[1:]
+		r.line = 0
+		r.sourceStart = true
+		return true
+	}
+	if r.firstRecord == nil {
+		r.firstRecord = r.keepRecord(record)
+		return false
+	}
+	if r.sourceStart {
+		r.sourceStart = false
+		return slices.Equal(record, r.firstRecord)
+	}
+	return false
+}
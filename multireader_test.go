@@ -0,0 +1,48 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMultiReader(t *testing.T) {
+	r := MultiReader(
+		strings.NewReader("id,name\n1,ann\n2,bob"),
+		strings.NewReader(""),
+		strings.NewReader("\nid,name\n3,cy\n"),
+		strings.NewReader("4,dan\n"),
+	)
+	records, err := r.ReadAllToMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []map[string]string{
+		{"id": "id", "name": "name"},
+		{"id": "1", "name": "ann"},
+		{"id": "2", "name": "bob"},
+		{"id": "3", "name": "cy"},
+		{"id": "4", "name": "dan"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+
+	r = MultiReader(strings.NewReader("a,b\n1,2\n"), strings.NewReader("a,b\n3\n"))
+	r.Read()
+	r.Read()
+	var perr *ParseError
+	if _, err := r.Read(); !errors.As(err, &perr) || perr.Line != 2 || perr.Err != ErrFieldCount {
+		t.Errorf("error %v, want wrong number of fields on line 2", err)
+	}
+
+	if _, err := MultiReader().Read(); err != io.EOF {
+		t.Errorf("error %v, want io.EOF", err)
+	}
+}
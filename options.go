@@ -0,0 +1,194 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrInvalidOption is wrapped by the errors of NewReaderWith and
+// NewWriterWith for options that are invalid or do not apply.
+var ErrInvalidOption = errors.New("invalid option")
+
+// An Option configures the Reader built by NewReaderWith or the Writer built
+// by NewWriterWith.  Options such as WithComma apply to both; others, such as
+// WithSkipOnErr, apply to one of them and are an error for the other.
+type Option struct {
+	name   string
+	reader func(r *Reader) error
+	writer func(w *Writer) error
+}
+
+// NewReaderWith returns a new Reader reading from r, as returned by
+// NewReader, configured by opts:
+//
+//	reader, err := bettercsv.NewReaderWith(f, bettercsv.WithComma(';'), bettercsv.WithSkipOnErr())
+//
+// Unlike the fields of Reader, the options are checked: the returned error
+// wraps ErrInvalidOption if one is invalid, does not apply to a Reader or
+// conflicts with another, as when Comma and Comment are the same.
+func NewReaderWith(r io.Reader, opts ...Option) (*Reader, error) {
+	reader := NewReader(r)
+	if err := reader.applyOptions(opts); err != nil {
+		reader.release()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// applyOptions configures r with opts and checks the result.
+func (r *Reader) applyOptions(opts []Option) error {
+	for _, opt := range opts {
+		if opt.reader == nil {
+			return fmt.Errorf("%w: %s does not apply to a Reader", ErrInvalidOption, opt.name)
+		}
+		if err := opt.reader(r); err != nil {
+			return err
+		}
+	}
+	if r.Comment != 0 && r.Comment == r.Comma {
+		return fmt.Errorf("%w: comment %q is the delimiter", ErrInvalidOption, r.Comment)
+	}
+	return nil
+}
+
+// NewWriterWith returns a new Writer writing to w, as returned by
+// NewWriter, configured by opts.  Like NewReaderWith, it checks the options.
+func NewWriterWith(w io.Writer, opts ...Option) (*Writer, error) {
+	writer := NewWriter(w)
+	for _, opt := range opts {
+		if opt.writer == nil {
+			return nil, fmt.Errorf("%w: %s does not apply to a Writer", ErrInvalidOption, opt.name)
+		}
+		if err := opt.writer(writer); err != nil {
+			return nil, err
+		}
+	}
+	if writer.Comment != 0 && writer.Comment == writer.Comma {
+		return nil, fmt.Errorf("%w: comment %q is the delimiter", ErrInvalidOption, writer.Comment)
+	}
+	return writer, nil
+}
+
+// WithComma sets Comma, the field delimiter.  It may not be a quote, a line
+// ending or an invalid rune.
+func WithComma(comma rune) Option {
+	check := func() error {
+		if !validSpecial(comma) {
+			return fmt.Errorf("%w: comma %q", ErrInvalidOption, comma)
+		}
+		return nil
+	}
+	return Option{
+		name:   "WithComma",
+		reader: func(r *Reader) error { r.Comma = comma; return check() },
+		writer: func(w *Writer) error { w.Comma = comma; return check() },
+	}
+}
+
+// WithComment sets Comment, the character starting comment lines.  It may
+// not be a quote, a line ending or an invalid rune.
+func WithComment(comment rune) Option {
+	check := func() error {
+		if !validSpecial(comment) {
+			return fmt.Errorf("%w: comment %q", ErrInvalidOption, comment)
+		}
+		return nil
+	}
+	return Option{
+		name:   "WithComment",
+		reader: func(r *Reader) error { r.Comment = comment; return check() },
+		writer: func(w *Writer) error { w.Comment = comment; return check() },
+	}
+}
+
+// WithHeaders sets the names of the columns.  A Reader reads the first line
+// of its input as a record rather than as the header row, for files without
+// one; a Writer writes the columns of WriteMap and WriteStruct in this
+// order, as set by Headers.  At least one name is required.
+func WithHeaders(names ...string) Option {
+	check := func() error {
+		if len(names) == 0 {
+			return fmt.Errorf("%w: no headers", ErrInvalidOption)
+		}
+		return nil
+	}
+	return Option{
+		name: "WithHeaders",
+		reader: func(r *Reader) error {
+			r.preset = append([]string(nil), names...)
+			r.headers = r.preset
+			return check()
+		},
+		writer: func(w *Writer) error {
+			w.Headers = append([]string(nil), names...)
+			return check()
+		},
+	}
+}
+
+// WithSkipOnErr sets SkipLineOnErr on a Reader, to skip the records with
+// errors.
+func WithSkipOnErr() Option {
+	return Option{
+		name:   "WithSkipOnErr",
+		reader: func(r *Reader) error { r.SkipLineOnErr = true; return nil },
+	}
+}
+
+// WithFieldsPerRecord sets FieldsPerRecord on a Reader.
+func WithFieldsPerRecord(n int) Option {
+	return Option{
+		name:   "WithFieldsPerRecord",
+		reader: func(r *Reader) error { r.FieldsPerRecord = n; return nil },
+	}
+}
+
+// WithLazyQuotes sets LazyQuotes on a Reader.
+func WithLazyQuotes() Option {
+	return Option{
+		name:   "WithLazyQuotes",
+		reader: func(r *Reader) error { r.LazyQuotes = true; return nil },
+	}
+}
+
+// WithTrimFields sets TrimFields on a Reader.
+func WithTrimFields() Option {
+	return Option{
+		name:   "WithTrimFields",
+		reader: func(r *Reader) error { r.TrimFields = true; return nil },
+	}
+}
+
+// WithCRLF sets UseCRLF on a Writer, to end lines with \r\n.
+func WithCRLF() Option {
+	return Option{
+		name:   "WithCRLF",
+		writer: func(w *Writer) error { w.UseCRLF = true; return nil },
+	}
+}
+
+// WithQuoteMode sets the QuoteMode of a Writer.
+func WithQuoteMode(mode QuoteMode) Option {
+	return Option{
+		name: "WithQuoteMode",
+		writer: func(w *Writer) error {
+			if mode < QuoteMinimal || mode > QuoteNever {
+				return fmt.Errorf("%w: quote mode %d", ErrInvalidOption, mode)
+			}
+			w.QuoteMode = mode
+			return nil
+		},
+	}
+}
+
+// validSpecial reports whether c can be used as a delimiter or comment
+// character.
+func validSpecial(c rune) bool {
+	return c != 0 && c != '"' && c != '\r' && c != '\n' && c != utf8.RuneError && utf8.ValidRune(c)
+}
@@ -0,0 +1,69 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewReaderWith(t *testing.T) {
+	r, err := NewReaderWith(strings.NewReader("# note\n1;2\n3\n4; 5\n"),
+		WithComma(';'), WithComment('#'), WithHeaders("a", "b"), WithSkipOnErr(), WithTrimFields())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	maps, err := r.ReadAllToMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []map[string]string{{"a": "1", "b": "2"}, {"a": "4", "b": "5"}}
+	if !reflect.DeepEqual(maps, want) {
+		t.Errorf("maps=%q, want %q", maps, want)
+	}
+}
+
+func TestNewWriterWith(t *testing.T) {
+	var b strings.Builder
+	w, err := NewWriterWith(&b, WithComma('\t'), WithHeaders("b", "a"), WithCRLF(), WithQuoteMode(QuoteAlways))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.WriteMap(map[string]string{"a": "1", "b": "2"})
+	w.Flush()
+	if want := "\"2\"\t\"1\"\r\n"; b.String() != want {
+		t.Errorf("output %q, want %q", b.String(), want)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Opts   []Option
+		Writer bool
+	}{
+		{Name: "Comma", Opts: []Option{WithComma('"')}},
+		{Name: "Comment", Opts: []Option{WithComment('\n')}},
+		{Name: "CommentComma", Opts: []Option{WithComma(';'), WithComment(';')}},
+		{Name: "Headers", Opts: []Option{WithHeaders()}},
+		{Name: "WriterOption", Opts: []Option{WithCRLF()}},
+		{Name: "ReaderOption", Opts: []Option{WithSkipOnErr()}, Writer: true},
+		{Name: "QuoteMode", Opts: []Option{WithQuoteMode(QuoteMode(-1))}, Writer: true},
+		{Name: "WriterComma", Opts: []Option{WithComma(0)}, Writer: true},
+	}
+	for _, tt := range tests {
+		var err error
+		if tt.Writer {
+			_, err = NewWriterWith(&strings.Builder{}, tt.Opts...)
+		} else {
+			_, err = NewReaderWith(strings.NewReader(""), tt.Opts...)
+		}
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s: error %v, want ErrInvalidOption", tt.Name, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"io"
+	"runtime"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultChunkSize is the size in bytes of the chunks of a ParallelReader
+// whose ChunkSize is 0.
+const DefaultChunkSize = 4 << 20
+
+// A ParallelReader reads an uncompressed input in chunks parsed on several
+// goroutines.  The input is split at record boundaries found by a quick
+// scan for quotes and newlines, so quoted fields spanning lines are kept
+// whole.
+//
+// If Configure is not nil, it is called with the Reader of each chunk
+// before it is read, to set options such as Comma.  It must not share
+// state between Readers, other than Stats: the records are added to Stats
+// in order once every chunk is read.
+type ParallelReader struct {
+	Workers   int           // goroutines parsing chunks; runtime.GOMAXPROCS(0) if 0
+	ChunkSize int64         // approximate size of the chunks; DefaultChunkSize if 0
+	Configure func(*Reader) // configures the Reader of each chunk
+}
+
+// chunk is a part of the input of a ParallelReader, starting on a record
+// boundary.
+type chunk struct {
+	start, end int64
+	line       int // lines before start
+	records    [][]string
+	reader     *Reader
+	err        error
+}
+
+// ReadAll reads all the records of the size bytes of ra, like the ReadAll
+// method of a Reader.  The header row is the first record.  If several
+// chunks have errors, the error of the first is returned.
+func (p *ParallelReader) ReadAll(ra io.ReaderAt, size int64) (records [][]string, err error) {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunkSize := p.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	// The first record is read first, for the headers and the field count
+	// the other chunks check.
+	first := p.newReader(io.NewSectionReader(ra, 0, size))
+	var headers []string
+	for headers == nil {
+		headers, err = first.parseRecord()
+		if headers == nil && err == io.EOF {
+			return nil, nil
+		} else if headers == nil && err != nil {
+			return nil, err
+		}
+	}
+	fieldsPerRecord := first.FieldsPerRecord
+	if fieldsPerRecord == 0 {
+		fieldsPerRecord = len(headers)
+	}
+
+	chunks, err := splitChunks(ra, size, chunkSize, first)
+	if err != nil {
+		return nil, err
+	}
+	next := make(chan *chunk)
+	var wg sync.WaitGroup
+	for range min(workers, len(chunks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range next {
+				c.reader = p.newReader(io.NewSectionReader(ra, c.start, c.end-c.start))
+				c.reader.Stats = nil
+				if c.start > 0 {
+					c.reader.headers = headers
+					c.reader.line = c.line
+					if c.reader.FieldsPerRecord == 0 {
+						c.reader.FieldsPerRecord = fieldsPerRecord
+					}
+				}
+				c.records, c.err = c.reader.ReadAll()
+			}
+		}()
+	}
+	for _, c := range chunks {
+		next <- c
+	}
+	close(next)
+	wg.Wait()
+
+	n := 0
+	for _, c := range chunks {
+		if c.err != nil {
+			return nil, c.err
+		}
+		n += len(c.records)
+	}
+	records = make([][]string, 0, n)
+	for _, c := range chunks {
+		records = append(records, c.records...)
+	}
+
+	// Limit and Stats apply to the records of all the chunks.
+	r := chunks[0].reader
+	if first.Limit > 0 && len(records) > first.Limit+1 {
+		records = records[:first.Limit+1]
+	}
+	if first.Stats != nil && len(records) > 0 {
+		if first.Stats.headers == nil {
+			first.Stats.headers = r.outputHeaders()
+		}
+		start := 0
+		if r.headers != nil {
+			start = 1 // the header row
+		}
+		for _, record := range records[start:] {
+			first.Stats.Add(record)
+		}
+	}
+	return records, nil
+}
+
+// newReader returns a Reader of the uncompressed input r, configured by
+// p.Configure.
+func (p *ParallelReader) newReader(r io.Reader) *Reader {
+	reader := newPlainReader(r)
+	if p.Configure != nil {
+		p.Configure(reader)
+	}
+	return reader
+}
+
+// splitChunks scans the size bytes of ra for record boundaries about
+// chunkSize bytes apart, following the quoting, escaping and comments of
+// the Reader config.
+func splitChunks(ra io.ReaderAt, size, chunkSize int64, config *Reader) ([]*chunk, error) {
+	var comments [][]byte
+	if config.Comment != 0 {
+		comments = append(comments, utf8.AppendRune(nil, config.Comment))
+	}
+	for _, prefix := range config.CommentPrefixes {
+		if prefix != "" {
+			comments = append(comments, []byte(prefix))
+		}
+	}
+
+	chunks := []*chunk{{}}
+	br := getBufioReader(io.NewSectionReader(ra, 0, size))
+	defer putBufioReader(br)
+	s := newRecordScanner(config)
+	var (
+		offset      int64
+		line        int
+		recordStart = true
+	)
+	for {
+		if recordStart && comments != nil {
+			if startsWithAny(br, comments) {
+				skipped, err := br.ReadSlice('\n')
+				for err == bufio.ErrBufferFull {
+					offset += int64(len(skipped))
+					skipped, err = br.ReadSlice('\n')
+				}
+				offset += int64(len(skipped))
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					return nil, err
+				}
+				line++
+				continue
+			}
+		}
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		offset++
+		if b == '\n' {
+			line++
+		}
+		if recordStart = s.scan(b); recordStart {
+			s.start()
+			if offset-chunks[len(chunks)-1].start >= chunkSize && offset < size {
+				chunks[len(chunks)-1].end = offset
+				chunks = append(chunks, &chunk{start: offset, line: line})
+			}
+		}
+	}
+	chunks[len(chunks)-1].end = size
+	return chunks, nil
+}
+
+// startsWithAny reports whether the input of br starts with one of
+// prefixes.
+func startsWithAny(br *bufio.Reader, prefixes [][]byte) bool {
+	for _, prefix := range prefixes {
+		if b, _ := br.Peek(len(prefix)); string(b) == string(prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parallelTests = []struct {
+	Name      string
+	Input     string
+	Configure func(*Reader)
+}{
+	{
+		Name:  "Simple",
+		Input: "a,b,c\n1,2,3\n4,5,6\n7,8,9\n",
+	},
+	{
+		Name:  "QuotedNewlines",
+		Input: "a,b\n\"x\n1\",2\n\"y\"\"\n,\n\",3\r\n4,\"\n\n\"\n5,6",
+	},
+	{
+		Name:      "Comments",
+		Input:     "a,b\n# \"open\n1,2\n#\"\n3,\"4\n#\"\n5,6\n",
+		Configure: func(r *Reader) { r.Comment = '#' },
+	},
+	{
+		Name:      "CommentPrefixes",
+		Input:     "a,b\n-- \"open\n1,2\n//\"\n3,\"4\n--\"\n5,-6\n",
+		Configure: func(r *Reader) { r.CommentPrefixes = []string{"--", "//"} },
+	},
+	{
+		Name:      "MultiByteComma",
+		Input:     "a→b\n\"1\n\"→\"2\n\"\n3→4\n",
+		Configure: func(r *Reader) { r.Comma = '→' },
+	},
+	{
+		Name:      "LazyQuotes",
+		Input:     "a,b\n\"1 \"x\n\",2\n3,4\n",
+		Configure: func(r *Reader) { r.LazyQuotes = true },
+	},
+	{
+		Name:      "TrimLeadingSpace",
+		Input:     "a,b\n1,  \"\n2\"\n3,4\n",
+		Configure: func(r *Reader) { r.TrimLeadingSpace = true },
+	},
+	{
+		Name:      "Select",
+		Input:     "a,b,c\n1,2,3\n4,5,6\n7,8,9\n",
+		Configure: func(r *Reader) { r.Select("c", "a") },
+	},
+	{
+		Name:  "FieldCount",
+		Input: "a,b\n1,2\n3,4\n5\n6,7\n",
+	},
+	{
+		Name:  "BareQuote",
+		Input: "a,b\n1,2\n3,4\n5,x\"\n6,7\n",
+	},
+}
+
+func TestParallelReader(t *testing.T) {
+	for _, tt := range parallelTests {
+		r := NewReader(strings.NewReader(tt.Input))
+		if tt.Configure != nil {
+			tt.Configure(r)
+		}
+		want, wantErr := r.ReadAll()
+
+		for chunkSize := int64(1); chunkSize <= int64(len(tt.Input)); chunkSize++ {
+			p := &ParallelReader{Workers: 3, ChunkSize: chunkSize, Configure: tt.Configure}
+			out, err := p.ReadAll(strings.NewReader(tt.Input), int64(len(tt.Input)))
+			if !reflect.DeepEqual(err, wantErr) {
+				t.Errorf("%s: chunk size %d: error %v, want %v", tt.Name, chunkSize, err, wantErr)
+			} else if !reflect.DeepEqual(out, want) {
+				t.Errorf("%s: chunk size %d: out=%q want %q", tt.Name, chunkSize, out, want)
+			}
+		}
+	}
+}
+
+func TestParallelReaderStats(t *testing.T) {
+	input := "a,b\n1,x\n2,y\n3,z\n4,w\n"
+	stats := new(Stats)
+	p := &ParallelReader{
+		ChunkSize: 4,
+		Configure: func(r *Reader) {
+			r.Limit = 3
+			r.Stats = stats
+			r.Transform("b", strings.ToUpper)
+		},
+	}
+	out, err := p.ReadAll(strings.NewReader(input), int64(len(input)))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := [][]string{{"a", "b"}, {"1", "X"}, {"2", "Y"}, {"3", "Z"}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%q want %q", out, want)
+	}
+	if columns := stats.Columns(); len(columns) != 2 || columns[1].Name != "b" || columns[1].Count != 3 || columns[1].Max != "Z" {
+		t.Errorf("stats=%+v", columns)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// A Pivoter reshapes long records into wide ones: the records sharing the
+// fields of the IDColumns become one record, with a column for each field
+// of KeyColumn holding the field of ValueColumn.
+//
+// If Columns is not nil, only these keys become columns, in this order;
+// otherwise every key does, in the order they are first seen.  Keys missing
+// from a group are empty fields.  The groups are held in memory.
+type Pivoter struct {
+	IDColumns   []string // columns identifying the output records
+	KeyColumn   string   // column whose fields name the output columns
+	ValueColumn string   // column whose fields fill the output columns
+	Columns     []string // output columns; all keys if nil
+}
+
+// pivotGroup is an output record of a Pivoter.
+type pivotGroup struct {
+	id     []string
+	values map[string]string
+}
+
+// Pivot reads the header row and the records of r, which must be at its
+// start, and writes the pivoted records to w, in the order their ID is first
+// seen, after a header row of the IDColumns and the key columns.  A key
+// repeated within a group is a *FieldError wrapping ErrDuplicate, and
+// columns missing from the header row are an error wrapping
+// ErrUnknownColumn.  Records with errors are skipped if r has SkipLineOnErr
+// set.
+func (p *Pivoter) Pivot(r *Reader, w *Writer) error {
+	headers, err := r.readNew()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ids, err := columnIndexes(headers, p.IDColumns)
+	if err != nil {
+		return err
+	}
+	kv, err := columnIndexes(headers, []string{p.KeyColumn, p.ValueColumn})
+	if err != nil {
+		return err
+	}
+
+	keys := p.Columns
+	var groups []*pivotGroup
+	byID := make(map[string]*pivotGroup)
+	for {
+		record, err := r.readNew()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return err
+		}
+		id := fieldsAt(record, ids)
+		k := dedupeKey(id, nil)
+		g := byID[k]
+		if g == nil {
+			g = &pivotGroup{id: id, values: make(map[string]string)}
+			byID[k] = g
+			groups = append(groups, g)
+		}
+		kvFields := fieldsAt(record, kv)
+		key, value := kvFields[0], kvFields[1]
+		if _, dup := g.values[key]; dup {
+			err := &FieldError{Line: r.recordLine, Column: kv[0], Name: p.KeyColumn, Err: fmt.Errorf("%w: %q", ErrDuplicate, key)}
+			if r.skipError(err) {
+				continue
+			}
+			return err
+		}
+		g.values[key] = value
+		if p.Columns == nil && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	if err := w.writeHeader(append(slices.Clone(p.IDColumns), keys...)); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		record := slices.Clone(g.id)
+		for _, key := range keys {
+			record = append(record, g.values[key])
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// An Unpivoter reshapes wide records into long ones: each field of the
+// Columns becomes a record made of the fields of the IDColumns, the name of
+// the column and the field.  If Columns is nil, every column but the
+// IDColumns is unpivoted.
+type Unpivoter struct {
+	IDColumns   []string // columns copied to every output record
+	Columns     []string // columns unpivoted; all others if nil
+	KeyColumn   string   // header of the column of names ("key" if empty)
+	ValueColumn string   // header of the column of fields ("value" if empty)
+	SkipEmpty   bool     // true to write no record for empty fields
+}
+
+// Unpivot reads the header row and the records of r, which must be at its
+// start, and writes the unpivoted records to w, one record at a time, after a
+// header row of the IDColumns, KeyColumn and ValueColumn.  Columns missing
+// from the header row are an error wrapping ErrUnknownColumn.  Records with
+// errors are skipped if r has SkipLineOnErr set.
+func (u *Unpivoter) Unpivot(r *Reader, w *Writer) error {
+	headers, err := r.readNew()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ids, err := columnIndexes(headers, u.IDColumns)
+	if err != nil {
+		return err
+	}
+	names := u.Columns
+	if names == nil {
+		for _, h := range headers {
+			if !slices.Contains(u.IDColumns, h) {
+				names = append(names, h)
+			}
+		}
+	}
+	columns, err := columnIndexes(headers, names)
+	if err != nil {
+		return err
+	}
+
+	keyColumn, valueColumn := u.KeyColumn, u.ValueColumn
+	if keyColumn == "" {
+		keyColumn = "key"
+	}
+	if valueColumn == "" {
+		valueColumn = "value"
+	}
+	if err := w.writeHeader(append(slices.Clone(u.IDColumns), keyColumn, valueColumn)); err != nil {
+		return err
+	}
+	for {
+		record, err := r.readNew()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return err
+		}
+		id := fieldsAt(record, ids)
+		for i, value := range fieldsAt(record, columns) {
+			if u.SkipEmpty && value == "" {
+				continue
+			}
+			if err := w.Write(append(slices.Clone(id), names[i], value)); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// columnIndexes returns the indexes of the named columns in headers.
+func columnIndexes(headers, names []string) ([]int, error) {
+	indexes := make([]int, len(names))
+	for i, name := range names {
+		if indexes[i] = slices.Index(headers, name); indexes[i] < 0 {
+			return nil, fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+	}
+	return indexes, nil
+}
+
+// fieldsAt returns the fields of record at indexes, empty past its end.
+func fieldsAt(record []string, indexes []int) []string {
+	fields := make([]string, len(indexes))
+	for i, index := range indexes {
+		if index < len(record) {
+			fields[i] = record[index]
+		}
+	}
+	return fields
+}
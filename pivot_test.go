@@ -0,0 +1,71 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const longInput = `store,month,metric,amount
+A,jan,sales,10
+A,jan,returns,1
+B,jan,sales,7
+A,feb,sales,12
+`
+
+const wideOutput = `store,month,sales,returns
+A,jan,10,1
+B,jan,7,""
+A,feb,12,""
+`
+
+func TestPivot(t *testing.T) {
+	p := &Pivoter{IDColumns: []string{"store", "month"}, KeyColumn: "metric", ValueColumn: "amount"}
+	var b bytes.Buffer
+	if err := p.Pivot(NewReader(strings.NewReader(longInput)), NewWriter(&b)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if b.String() != wideOutput {
+		t.Errorf("out=%q want %q", b.String(), wideOutput)
+	}
+
+	b.Reset()
+	p.Columns = []string{"returns"}
+	p.Pivot(NewReader(strings.NewReader(longInput)), NewWriter(&b))
+	if want := "store,month,returns\nA,jan,1\nB,jan,\"\"\nA,feb,\"\"\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+
+	err := p.Pivot(NewReader(strings.NewReader(longInput+"A,jan,sales,11\n")), NewWriter(&bytes.Buffer{}))
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Line != 6 || !errors.Is(err, ErrDuplicate) {
+		t.Errorf("error %v, want duplicate on line 6", err)
+	}
+	p.KeyColumn = "kind"
+	if err := p.Pivot(NewReader(strings.NewReader(longInput)), NewWriter(&bytes.Buffer{})); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("error %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestUnpivot(t *testing.T) {
+	u := &Unpivoter{IDColumns: []string{"store", "month"}, KeyColumn: "metric", ValueColumn: "amount", SkipEmpty: true}
+	var b bytes.Buffer
+	if err := u.Unpivot(NewReader(strings.NewReader(wideOutput)), NewWriter(&b)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if b.String() != longInput {
+		t.Errorf("out=%q want %q", b.String(), longInput)
+	}
+
+	b.Reset()
+	u = &Unpivoter{IDColumns: []string{"store"}, Columns: []string{"returns"}}
+	u.Unpivot(NewReader(strings.NewReader(wideOutput)), NewWriter(&b))
+	if want := "store,key,value\nA,returns,1\nB,returns,\"\"\nA,returns,\"\"\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// bufioPool holds the buffered readers of inputs read to the end, so that
+// reading many small inputs does not allocate buffers for each of them.
+var bufioPool sync.Pool
+
+// getBufioReader returns a buffered reader of r from bufioPool, or a new one.
+// It is never r itself, even if r is a *bufio.Reader, which bufio.NewReader
+// would return, so that the buffered readers of callers are not pooled.
+func getBufioReader(r io.Reader) *bufio.Reader {
+	br, ok := bufioPool.Get().(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(nil)
+	}
+	br.Reset(r)
+	return br
+}
+
+// putBufioReader returns br to bufioPool.  It must not be used afterwards.
+func putBufioReader(br *bufio.Reader) {
+	br.Reset(nil)
+	bufioPool.Put(br)
+}
+
+// release returns the buffered reader of r to bufioPool once the input is
+// read to the end.  Reading methods then return io.EOF, unless r is moved
+// back by SeekTo or ReadAt.
+func (r *Reader) release() {
+	if r.r != nil {
+		putBufioReader(r.r)
+		r.r = nil
+	}
+	if r.compressed != nil {
+		putBufioReader(r.compressed)
+		r.compressed = nil
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRelease(t *testing.T) {
+	input := "a,b\n1,2\n"
+	r := NewReader(strings.NewReader(input))
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if r.r != nil {
+		t.Errorf("buffered reader not released at the end of the input")
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("error %v, want io.EOF", err)
+	}
+
+	// Seeking back takes a buffered reader again.
+	if err := r.SeekTo(0); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	records, err := r.ReadAll()
+	if want := [][]string{{"a", "b"}, {"1", "2"}}; err != nil || !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q, err=%v, want %q", records, err, want)
+	}
+}
+
+func TestReset(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,2\n3"))
+	r.Select("b")
+	r.SkipLineOnErr = true
+	if _, err := r.ReadAllToMaps(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// A file with other columns, read before the end of the input.
+	r.Reset(strings.NewReader("b,c,d\n4,5,6\n7,8,9\n"))
+	record, err := r.ReadToMap()
+	if want := map[string]string{"b": "b"}; err != nil || !reflect.DeepEqual(record, want) {
+		t.Errorf("header=%q, err=%v, want %q", record, err, want)
+	}
+	record, err = r.ReadToMap()
+	if want := map[string]string{"b": "4"}; err != nil || !reflect.DeepEqual(record, want) {
+		t.Errorf("record=%q, err=%v, want %q", record, err, want)
+	}
+
+	// The field count is set from the first record again, and lines are
+	// counted from the start.
+	r.Reset(strings.NewReader("b\n1\n2,3\n"))
+	records, errs := r.ReadAllWithErrors()
+	if want := [][]string{{"b"}, {"1"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q, want %q", records, want)
+	}
+	if want := []error{&ParseError{Line: 3, Err: ErrFieldCount}}; !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors=%v, want %v", errs, want)
+	}
+
+	r, err = NewReaderWith(strings.NewReader("1,2\n"), WithHeaders("x", "y"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	r.ReadAll()
+	r.Reset(strings.NewReader("3,4\n"))
+	if record, err := r.ReadToMap(); err != nil || record["y"] != "4" {
+		t.Errorf("record=%q, err=%v with preset headers", record, err)
+	}
+}
+
+func TestReleaseCallerBuffer(t *testing.T) {
+	br := bufio.NewReader(strings.NewReader("a\n1\n"))
+	r := NewReader(br)
+	if r.r == br {
+		t.Fatalf("the bufio.Reader of the caller is used as the buffer of the Reader")
+	}
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func BenchmarkReadSmall(b *testing.B) {
+	input := "id,name,email\n1,John,john@doe.com\n2,Jane,jane@doe.com\n"
+	b.ReportAllocs()
+	for b.Loop() {
+		r := NewReader(strings.NewReader(input))
+		if _, err := r.ReadAllToMaps(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
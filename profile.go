@@ -0,0 +1,244 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"hash/maphash"
+	"io"
+	"math"
+	"math/bits"
+	"regexp"
+)
+
+// These are the defaults of a Profiler whose Examples and ExactDistinct
+// are 0.
+const (
+	DefaultProfileExamples = 5
+	DefaultExactDistinct   = 10000
+)
+
+// These are the patterns detected by a Profiler.
+const (
+	PatternEmail = "email"
+	PatternPhone = "phone"
+	PatternDate  = "date"
+	PatternURL   = "url"
+	PatternUUID  = "uuid"
+)
+
+var (
+	emailRe = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	phoneRe = regexp.MustCompile(`^\+?[0-9(][0-9 ().-]*[0-9]$`)
+	urlRe   = regexp.MustCompile(`^https?://\S+$`)
+	uuidRe  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// A ColumnProfile describes the values of a column, as profiled by a
+// Profiler.
+type ColumnProfile struct {
+	Name        string         // header of the column
+	Count       int            // number of non-null values
+	NullCount   int            // number of empty or null values
+	NullRatio   float64        // NullCount over the number of records
+	Distinct    int            // number of distinct non-null values
+	Approximate bool           // true if Distinct is an estimate
+	Type        ColumnType     // narrowest type holding every non-null value
+	Layout      string         // layout of the dates if Type is TypeDate
+	Patterns    map[string]int // number of values matching each pattern
+	Pattern     string         // pattern matched by every non-null value, or ""
+	Examples    []string       // first distinct non-null values
+}
+
+// A Profiler profiles the columns of a file, the usual first step before
+// designing an import: how often each column is null, how many distinct
+// values it has, which type and patterns its values follow, and examples of
+// them.
+//
+// Distinct values are counted exactly up to ExactDistinct values per
+// column, and estimated beyond with a HyperLogLog sketch, with an error of
+// about 1%, so that columns of high cardinality do not hold every value in
+// memory.
+type Profiler struct {
+	Examples      int // examples per column; DefaultProfileExamples if 0
+	ExactDistinct int // distinct values counted exactly; DefaultExactDistinct if 0
+}
+
+// columnProfile is the running state of a column.
+type columnProfile struct {
+	ColumnProfile
+	info   ColumnInfo
+	values map[string]struct{} // distinct values, until they are estimated
+	sketch *hyperLogLog        // estimate of the distinct values
+}
+
+// Profile reads the headers, if they have not been read, and all the
+// remaining records of r, and returns the profile of each column.  Empty
+// fields, fields matching one of the NullValues of r and the fields missing
+// from short records are null.  Dates are detected with the layouts of
+// TimeLayout and DateLayouts.  Records with errors are skipped if
+// SkipLineOnErr is true; otherwise the error is returned.
+func (p *Profiler) Profile(r *Reader) ([]ColumnProfile, error) {
+	if _, err := r.Headers(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	examples := p.Examples
+	if examples <= 0 {
+		examples = DefaultProfileExamples
+	}
+	exactDistinct := p.ExactDistinct
+	if exactDistinct <= 0 {
+		exactDistinct = DefaultExactDistinct
+	}
+	var columns []*columnProfile
+	for _, name := range r.outputHeaders() {
+		columns = append(columns, &columnProfile{
+			ColumnProfile: ColumnProfile{Name: name, Patterns: make(map[string]int)},
+			values:        make(map[string]struct{}),
+		})
+	}
+
+	records := 0
+	for {
+		record, _, err := r.read(true)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return nil, err
+		}
+		records++
+		for i, c := range columns {
+			if i >= len(record) || record[i] == "" || r.isNull(record[i]) {
+				c.NullCount++
+				continue
+			}
+			c.add(record[i], r.timeLayouts[c.Name], examples, exactDistinct)
+		}
+	}
+
+	profiles := make([]ColumnProfile, len(columns))
+	for i, c := range columns {
+		if records > 0 {
+			c.NullRatio = float64(c.NullCount) / float64(records)
+		}
+		if c.sketch != nil {
+			c.Distinct = c.sketch.estimate()
+			c.Approximate = true
+		} else {
+			c.Distinct = len(c.values)
+		}
+		c.Type, c.Layout = c.info.Type, c.info.Layout
+		for _, pattern := range []string{PatternEmail, PatternPhone, PatternDate, PatternURL, PatternUUID} {
+			if c.Count > 0 && c.Patterns[pattern] == c.Count {
+				c.Pattern = pattern
+				break
+			}
+		}
+		profiles[i] = c.ColumnProfile
+	}
+	return profiles, nil
+}
+
+// add adds the non-null value to the profile of the column.
+func (c *columnProfile) add(value string, layouts []string, examples, exactDistinct int) {
+	c.Count++
+	c.info.infer(value, layouts)
+	if c.sketch != nil {
+		c.sketch.add(value)
+	} else if _, ok := c.values[value]; !ok {
+		if len(c.Examples) < examples {
+			c.Examples = append(c.Examples, value)
+		}
+		c.values[value] = struct{}{}
+		if len(c.values) > exactDistinct {
+			c.sketch = newHyperLogLog()
+			for v := range c.values {
+				c.sketch.add(v)
+			}
+			c.values = nil
+		}
+	}
+	if pattern := detectPattern(value, layouts); pattern != "" {
+		c.Patterns[pattern]++
+	}
+}
+
+// detectPattern returns the pattern of value, or "" if it has none.
+func detectPattern(value string, layouts []string) string {
+	switch {
+	case emailRe.MatchString(value):
+		return PatternEmail
+	case uuidRe.MatchString(value):
+		return PatternUUID
+	case urlRe.MatchString(value):
+		return PatternURL
+	}
+	if t, _ := inferType(value, layouts); t == TypeDate {
+		return PatternDate
+	}
+	if isPhone(value) {
+		return PatternPhone
+	}
+	return ""
+}
+
+// isPhone reports whether value is a phone number: 7 to 15 digits, with a
+// leading + or separators such as spaces, dashes or parentheses.
+func isPhone(value string) bool {
+	if !phoneRe.MatchString(value) {
+		return false
+	}
+	digits := 0
+	for i := 0; i < len(value); i++ {
+		if '0' <= value[i] && value[i] <= '9' {
+			digits++
+		}
+	}
+	// Plain numbers are not taken for phone numbers.
+	return 7 <= digits && digits <= 15 && digits < len(value)
+}
+
+// hyperLogLog estimates the number of distinct strings added to it.
+type hyperLogLog struct {
+	seed      maphash.Seed
+	registers [1 << hllPrecision]uint8
+}
+
+// hllPrecision is the number of bits of the hash selecting a register,
+// for a standard error of 1.04 / sqrt(1 << hllPrecision), about 0.8%.
+const hllPrecision = 14
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{seed: maphash.MakeSeed()}
+}
+
+// add adds s to h.
+func (h *hyperLogLog) add(s string) {
+	x := maphash.String(h.seed, s)
+	i := x >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	h.registers[i] = max(h.registers[i], rank)
+}
+
+// estimate returns the estimated number of distinct strings added to h.
+func (h *hyperLogLog) estimate() int {
+	const m = float64(1 << hllPrecision)
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities.
+		e = m * math.Log(m/float64(zeros))
+	}
+	return int(math.Round(e))
+}
@@ -0,0 +1,64 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	input := `id,email,phone,joined,site,note
+1,ann@example.com,+1 555 0100,2014-06-01,https://example.com,x
+2,bob@example.com,(555) 010-0101,2014-06-02,,y
+3,NA,555-0102 ext,06/03/2014,http://example.org/a,x
+4,cat@example.com,,2014-06-04,,z
+`
+	r := NewReader(strings.NewReader(input))
+	r.NullValues = []string{"NA"}
+	profiles, err := (&Profiler{Examples: 2}).Profile(r)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []ColumnProfile{
+		{Name: "id", Count: 4, Distinct: 4, Type: TypeInt, Patterns: map[string]int{}, Examples: []string{"1", "2"}},
+		{Name: "email", Count: 3, NullCount: 1, NullRatio: 0.25, Distinct: 3, Type: TypeString,
+			Patterns: map[string]int{PatternEmail: 3}, Pattern: PatternEmail, Examples: []string{"ann@example.com", "bob@example.com"}},
+		{Name: "phone", Count: 3, NullCount: 1, NullRatio: 0.25, Distinct: 3, Type: TypeString,
+			Patterns: map[string]int{PatternPhone: 2}, Examples: []string{"+1 555 0100", "(555) 010-0101"}},
+		{Name: "joined", Count: 4, Distinct: 4, Type: TypeString,
+			Patterns: map[string]int{PatternDate: 4}, Pattern: PatternDate, Examples: []string{"2014-06-01", "2014-06-02"}},
+		{Name: "site", Count: 2, NullCount: 2, NullRatio: 0.5, Distinct: 2, Type: TypeString,
+			Patterns: map[string]int{PatternURL: 2}, Pattern: PatternURL, Examples: []string{"https://example.com", "http://example.org/a"}},
+		{Name: "note", Count: 4, Distinct: 3, Type: TypeString, Patterns: map[string]int{}, Examples: []string{"x", "y"}},
+	}
+	for i := range want {
+		if !reflect.DeepEqual(profiles[i], want[i]) {
+			t.Errorf("profile=%+v want %+v", profiles[i], want[i])
+		}
+	}
+}
+
+func TestProfilerApproximate(t *testing.T) {
+	const n = 50000
+	var b strings.Builder
+	b.WriteString("id,mod\n")
+	for i := range n {
+		fmt.Fprintf(&b, "%d,%d\n", i, i%100)
+	}
+	profiles, err := (&Profiler{ExactDistinct: 1000}).Profile(NewReader(strings.NewReader(b.String())))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if id := profiles[0]; !id.Approximate || math.Abs(float64(id.Distinct-n)) > n*0.03 {
+		t.Errorf("id: distinct=%d approximate=%v, want about %d", id.Distinct, id.Approximate, n)
+	}
+	if mod := profiles[1]; mod.Approximate || mod.Distinct != 100 {
+		t.Errorf("mod: distinct=%d approximate=%v, want 100", mod.Distinct, mod.Approximate)
+	}
+}
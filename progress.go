@@ -0,0 +1,40 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import "io"
+
+// DefaultProgressInterval is the number of records between calls to the
+// OnProgress function of a Reader whose ProgressInterval is 0.
+const DefaultProgressInterval = 10000
+
+// Progress is passed to the OnProgress function of a Reader to report how
+// far reading has gone, for instance to drive a progress bar during a long
+// ReadAll.
+type Progress struct {
+	Bytes   int64 // bytes of input consumed, after decompression
+	Records int   // records parsed, including the header row and filtered records
+	Errors  int   // errors returned, including the records skipped with SkipLineOnErr
+}
+
+// progress counts err and calls r.OnProgress once ProgressInterval records
+// were parsed since the last call, or at the end of the input.
+func (r *Reader) progress(err error) {
+	if err != nil && err != io.EOF {
+		r.errors++
+	}
+	p := Progress{Bytes: r.offset, Records: r.records, Errors: r.errors}
+	if p == r.reported {
+		return
+	}
+	interval := r.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	if err == io.EOF || p.Records >= r.reported.Records+interval {
+		r.reported = p
+		r.OnProgress(p)
+	}
+}
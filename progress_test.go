@@ -0,0 +1,86 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var progressTests = []struct {
+	Name      string
+	Input     string
+	Interval  int
+	Configure func(*Reader)
+	Output    []Progress
+}{
+	{
+		Name:     "Interval",
+		Input:    "a,b\n1,2\n3,4\n5,6\n7,8\n",
+		Interval: 2,
+		Output:   []Progress{{Bytes: 8, Records: 2}, {Bytes: 16, Records: 4}, {Bytes: 20, Records: 5}},
+	},
+	{
+		Name:     "EndOnInterval",
+		Input:    "a,b\n1,2\n3,4\n5,6\n",
+		Interval: 2,
+		Output:   []Progress{{Bytes: 8, Records: 2}, {Bytes: 16, Records: 4}},
+	},
+	{
+		Name:   "DefaultInterval",
+		Input:  "a,b\n1,2\n3,4\n",
+		Output: []Progress{{Bytes: 12, Records: 3}},
+	},
+	{
+		Name:      "Errors",
+		Input:     "a,b\n1,2\n3\n4,\"5\n",
+		Interval:  2,
+		Configure: func(r *Reader) { r.SkipLineOnErr = true },
+		Output:    []Progress{{Bytes: 8, Records: 2}, {Bytes: 15, Records: 3, Errors: 2}},
+	},
+	{
+		Name:      "Filter",
+		Input:     "a,b\n1,2\n3,4\n5,6\n",
+		Interval:  3,
+		Configure: func(r *Reader) { r.Filter = func(record []string) bool { return record[0] != "3" } },
+		Output:    []Progress{{Bytes: 12, Records: 3}, {Bytes: 16, Records: 4}},
+	},
+	{
+		Name:      "Limit",
+		Input:     "a,b\n1,2\n3,4\n5,6\n",
+		Interval:  10,
+		Configure: func(r *Reader) { r.Limit = 1 },
+		Output:    []Progress{{Bytes: 8, Records: 2}},
+	},
+}
+
+func TestProgress(t *testing.T) {
+	for _, tt := range progressTests {
+		r := NewReader(strings.NewReader(tt.Input))
+		if tt.Configure != nil {
+			tt.Configure(r)
+		}
+		var out []Progress
+		r.OnProgress = func(p Progress) { out = append(out, p) }
+		r.ProgressInterval = tt.Interval
+		r.ReadAll()
+		if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%+v want %+v", tt.Name, out, tt.Output)
+		}
+	}
+}
+
+func TestProgressReadAllToMaps(t *testing.T) {
+	var out []Progress
+	r := NewReader(strings.NewReader("a,b\n1,2\n3,4\n"))
+	r.OnProgress = func(p Progress) { out = append(out, p) }
+	r.ProgressInterval = 1
+	r.ReadAllToMaps()
+	want := []Progress{{Bytes: 4, Records: 1}, {Bytes: 8, Records: 2}, {Bytes: 12, Records: 3}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%+v want %+v", out, want)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import "io"
+
+// ReadBytes reads one record from r like Read, without converting its
+// fields to strings.  The bytes of the fields are appended to buf[:0] and
+// the fields, slices of buf, to fields[:0].  Passing the returned slices to
+// the next call reuses their storage, so the fields are only valid until
+// then.
+//
+// Transforms, validators, selected columns, ColumnMapping, Filter, Stats,
+// fixed-width, backslash-escaped and MultiReader records are read with
+// Read and copied, so they save nothing.
+func (r *Reader) ReadBytes(fields [][]byte, buf []byte) ([][]byte, []byte, error) {
+	fields, buf = fields[:0], buf[:0]
+	if r.needsHeaders() || r.indexTransforms != nil || r.Filter != nil || r.Stats != nil ||
+		r.sources != nil || r.FixedColumns != nil || r.BackslashEscapes || r.copyFormat != 0 {
+		record, err := r.Read()
+		for _, field := range record {
+			buf = append(buf, field...)
+		}
+		start := 0
+		for _, field := range record {
+			end := start + len(field)
+			fields = append(fields, buf[start:end:end])
+			start = end
+		}
+		return fields, buf, err
+	}
+
+	if r.atLimit() {
+		return fields, buf, io.EOF
+	}
+	for {
+		skip, err := r.startRecord()
+		if !skip {
+			var blank bool
+			if blank, err = r.readBlankLine(); blank {
+				if err == nil && r.BlankLines == EmptyBlankLines {
+					r.field.Reset()
+					r.fieldEnds = r.fieldEnds[:0]
+					break
+				}
+			} else if err = r.parseFields(); len(r.fieldEnds) > 0 && (err == nil || err == io.EOF) {
+				break
+			}
+		}
+		if err != nil {
+			return fields, buf, err
+		}
+	}
+	r.records++
+
+	buf = append(buf, r.field.Bytes()...)
+	start := 0
+	for _, end := range r.fieldEnds {
+		fields = append(fields, buf[start:end:end])
+		start = end
+	}
+	// Blank lines read with EmptyBlankLines have no field count.
+	if r.FieldsPerRecord > 0 {
+		if len(fields) != r.FieldsPerRecord && len(fields) > 0 {
+			r.column = 0 // report at start of record
+			return fields, buf, r.error(ErrFieldCount)
+		}
+	} else if r.FieldsPerRecord == 0 && len(fields) > 0 {
+		r.FieldsPerRecord, r.inferred = len(fields), true
+	}
+	r.returned++
+	if r.pastMaxRows() {
+		return fields[:0], buf[:0], ErrMaxRows
+	}
+	return fields, buf, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var readBytesTests = []struct {
+	Name      string
+	Input     string
+	Configure func(*Reader)
+}{
+	{
+		Name:  "Simple",
+		Input: "a,b,c\n1,\"2\n\"\"x\"\"\",3\n\n4,,6",
+	},
+	{
+		Name:      "Comments",
+		Input:     "# c\na,b\n# d\n1,2\n",
+		Configure: func(r *Reader) { r.Comment = '#' },
+	},
+	{
+		Name:      "Limit",
+		Input:     "a\n1\n2\n3\n",
+		Configure: func(r *Reader) { r.Limit = 2 },
+	},
+	{
+		Name:      "MaxRows",
+		Input:     "a\n1\n2\n3\n4\n",
+		Configure: func(r *Reader) { r.MaxRows = 2 },
+	},
+	{
+		Name:      "EmptyBlankLines",
+		Input:     "a,b\n\n1,2\r\n\r\n",
+		Configure: func(r *Reader) { r.BlankLines = EmptyBlankLines },
+	},
+	{
+		Name:      "ErrorBlankLines",
+		Input:     "a,b\n1,2\n\n3,4\n",
+		Configure: func(r *Reader) { r.BlankLines = ErrorBlankLines },
+	},
+	{
+		Name:      "WhitespaceBlank",
+		Input:     "a,b\n  \n1,2\n\t\n3,4",
+		Configure: func(r *Reader) { r.WhitespaceBlank = true },
+	},
+	{
+		Name:      "WhitespaceBlankError",
+		Input:     "a,b\n1,2\n \t\n",
+		Configure: func(r *Reader) { r.WhitespaceBlank = true; r.BlankLines = ErrorBlankLines },
+	},
+	{
+		Name:  "FieldCount",
+		Input: "a,b\n1,2\n3\n4,5\n",
+	},
+	{
+		Name:  "BareQuote",
+		Input: "a,b\n1,x\"\n",
+	},
+	{
+		Name:      "Select",
+		Input:     "a,b,c\n1,2,3\n",
+		Configure: func(r *Reader) { r.Select("c", "a") },
+	},
+	{
+		Name:      "TSV",
+		Input:     "a\tb\n1\\t\t2\n",
+		Configure: func(r *Reader) { r.Comma = '\t'; r.BackslashEscapes = true },
+	},
+}
+
+func TestReadBytes(t *testing.T) {
+	for _, tt := range readBytesTests {
+		r := NewReader(strings.NewReader(tt.Input))
+		br := NewReader(strings.NewReader(tt.Input))
+		if tt.Configure != nil {
+			tt.Configure(r)
+			tt.Configure(br)
+		}
+		var fields [][]byte
+		var buf []byte
+		for i := 0; ; i++ {
+			record, err := r.Read()
+			var err2 error
+			fields, buf, err2 = br.ReadBytes(fields, buf)
+			if !reflect.DeepEqual(err2, err) {
+				t.Errorf("%s: record %d: error %v, want %v", tt.Name, i, err2, err)
+			}
+			got := make([]string, len(fields))
+			for j, field := range fields {
+				got[j] = string(field)
+			}
+			if len(record) > 0 && !reflect.DeepEqual(got, record) {
+				t.Errorf("%s: record %d: fields=%q want %q", tt.Name, i, got, record)
+			}
+			if err == io.EOF || err2 == io.EOF {
+				break
+			}
+		}
+	}
+}
+
+func TestReadBytesReuse(t *testing.T) {
+	r := NewReader(strings.NewReader("aa,bb\ncc,dd\n"))
+	fields, buf, _ := r.ReadBytes(nil, nil)
+	fields, buf, err := r.ReadBytes(fields, buf)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(buf) != "ccdd" || string(fields[0]) != "cc" || &fields[1][0] != &buf[2] {
+		t.Errorf("fields=%q, buf=%q", fields, buf)
+	}
+	if len(fields[0]) != cap(fields[0]) {
+		t.Errorf("appending to a field overwrites the next one")
+	}
+}
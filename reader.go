@@ -14,8 +14,10 @@
 //
 // Carriage returns before newline characters are silently removed.
 //
-// Blank lines are ignored.  A line with only whitespace characters (excluding
-// the ending newline character) is not considered a blank line.
+// Blank lines are ignored, unless the BlankLines policy of the Reader says
+// otherwise.  A line with only whitespace characters (excluding the ending
+// newline character) is not considered a blank line, unless the
+// WhitespaceBlank option of the Reader is set.
 //
 // Fields which start and stop with the quote character " are called
 // quoted-fields.  The beginning and ending quote are not part of the
@@ -55,7 +57,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"slices"
+	"strings"
 	"unicode"
+	"unicode/utf8"
+	"unsafe"
 )
 
 // A ParseError is returned for parsing errors.
@@ -76,8 +83,55 @@ var (
 	ErrBareQuote     = errors.New("bare \" in non-quoted-field")
 	ErrQuote         = errors.New("extraneous \" in field")
 	ErrFieldCount    = errors.New("wrong number of fields in line")
+	ErrUnknownColumn = errors.New("unknown column")
+	ErrBlankLine     = errors.New("blank line")
+	ErrNUL           = errors.New("NUL byte in field")
+	ErrExtraFields   = errors.New("more fields than headers")
 )
 
+// ErrMaxRows is returned when the input has more records than the MaxRows
+// of the Reader.
+var ErrMaxRows = errors.New("too many records")
+
+// A BlankPolicy selects how a Reader handles blank lines.
+type BlankPolicy int
+
+const (
+	SkipBlankLines  BlankPolicy = iota // ignore blank lines
+	EmptyBlankLines                    // read blank lines as records without fields
+	ErrorBlankLines                    // return a ParseError wrapping ErrBlankLine
+)
+
+// A NULPolicy selects how a Reader handles NUL bytes.
+type NULPolicy int
+
+const (
+	KeepNULs  NULPolicy = iota // read NUL bytes as part of the fields
+	StripNULs                  // drop NUL bytes
+	ErrorNULs                  // return a ParseError wrapping ErrNUL
+)
+
+// An ExtraPolicy selects how the map reading methods handle the fields of a
+// record past the headers.
+type ExtraPolicy int
+
+const (
+	DropExtraFields  ExtraPolicy = iota // leave the extra fields out of the map
+	ErrorExtraFields                    // return a ParseError wrapping ErrExtraFields
+	JoinExtraFields                     // join the extra fields under ExtraKey
+)
+
+// A ColumnMap maps a column of the input, found by its header, to a column of
+// the output.
+type ColumnMap struct {
+	From string // header of the column in the input
+	To   string // name of the column in the output
+}
+
+// A TransformFunc converts a field.  Functions such as strings.TrimSpace and
+// strings.ToUpper can be used directly.
+type TransformFunc func(field string) string
+
 // A Reader reads records from a CSV-encoded file.
 //
 // As returned by NewReader, a Reader expects input conforming to RFC 4180.
@@ -87,7 +141,34 @@ var (
 // Comma is the field delimiter.  It defaults to ','.
 //
 // Comment, if not 0, is the comment character. Lines beginning with the
-// Comment character are ignored.
+// Comment character are ignored, unless KeepComments is true: they are then
+// returned by Comments.  CommentPrefixes lists further prefixes of comment
+// lines, which may be longer than one character, such as "//" or "--".
+//
+// InlineComment, if not 0, is the character starting comments at the end of
+// lines, such as the '#' of "a,b,c  # note".  Outside quotes, it ends the
+// field it appears in and the rest of the line is ignored, along with the
+// white space before it; inside quotes, it is part of the field.  A line
+// holding only a comment is skipped like a blank line.  ReadLazy does not
+// support InlineComment.
+//
+// Continuation, if not 0, is the character that, outside quotes and at the
+// end of a line, joins the line to the next one, as with the backslash of
+// some legacy feeds.  The character and the line end are dropped, so that
+// "a,b\\\nc" reads the record "a", "bc".  ReadLazy and ParallelReader do not
+// support Continuation.
+//
+// BlankLines selects how blank lines are read: skipped with SkipBlankLines,
+// the default, read as records without fields with EmptyBlankLines, which
+// are exempt from FieldsPerRecord, or returned as a ParseError wrapping
+// ErrBlankLine with ErrorBlankLines, for feeds where a blank line means the
+// data was truncated.  If WhitespaceBlank is true, lines of only spaces and
+// tabs are blank lines too, rather than records of one field.
+//
+// NULs selects how NUL bytes, which some database exports embed in fields,
+// are read: as part of the fields with KeepNULs, the default, dropped with
+// StripNULs, or as a ParseError wrapping ErrNUL at their position with
+// ErrorNULs.  ReadLazy always keeps them.
 //
 // If FieldsPerRecord is positive, Read requires each record to
 // have the given number of fields.  If FieldsPerRecord is 0, Read sets it to
@@ -99,31 +180,217 @@ var (
 // non-doubled quote may appear in a quoted field.
 //
 // If TrimLeadingSpace is true, leading white space in a field is ignored.
+// If TrimTrailingSpace is true, trailing white space in a field is ignored.
+// TrimFields is the same as setting both.  White space inside a quoted-field
+// is kept; white space between the closing quote and the delimiter is ignored
+// when trailing white space is trimmed.
 //
 // If SkipLineOnErr is true, the rest of the line is ignored.
+//
+// If ColumnMapping is not nil, records contain only the mapped columns, in the
+// order of the mapping, and the header row is returned with the To names.
+// ColumnMapping takes precedence over Select and SelectIndexes.
+//
+// NullValues lists the field values that the nullable map methods return as
+// nil, such as "NULL" or `\N`.  If BareEmptyNull is true, they also return
+// unquoted empty fields as nil, while quoted empty fields, "", are empty
+// strings, for files whose producers write null as nothing at all.
+//
+// ExtraFields selects how the map reading methods handle the fields of a
+// record past the headers, which FieldsPerRecord allows if it is negative:
+// they are left out with DropExtraFields, the default, returned as a
+// ParseError wrapping ErrExtraFields with ErrorExtraFields, or joined with
+// Comma under ExtraKey, "_extra" if it is empty, with JoinExtraFields.
+// Record.Extra returns them as a slice.
+//
+// If Stats is not nil, the records read, other than the header row, are added
+// to it.
+//
+// JSONInferRows is the number of records WriteNDJSON and ReadAllToJSON sample
+// to infer the JSON types of the values.  If it is 0, every value is written
+// as a string.
+//
+// Defaults holds, by column name, the values struct decoding stores in the
+// fields whose column is missing from the file.  They take precedence over
+// the default option of the csv tags.  If DisallowUnknownColumns is true,
+// struct decoding fails when the file has columns that are not stored in any
+// field.
+//
+// If FixedColumns is not nil, lines are read as fixed-width records rather
+// than delimited ones, as with NewFixedWidthReader.
+//
+// If BackslashEscapes is true, quotes have no special meaning and fields
+// escape special characters with backslashes, as in the files read by
+// NewTSVReader.
+//
+// If Limit is positive, the reading methods return io.EOF once Limit records
+// following the header row have been read, without reading further input.
+// If MaxRows is positive, the input may not have more than MaxRows records
+// following the header row: reading the next record returns ErrMaxRows,
+// which SkipLineOnErr does not skip, and io.EOF afterwards.  It protects
+// services reading uploads from files far larger than advertised.
+//
+// If Filter is not nil, records for which it returns false are skipped.
+// FilterMap does the same for the map reading methods.  The header row, once
+// read as such, is never filtered; Read, which reads the first line as a
+// record unless column names are used, passes it to Filter like the others.
+//
+// If OnProgress is not nil, it is called while reading every
+// ProgressInterval records, and once more at the end of the input.  If
+// Metrics is not nil, the records, bytes, errors and skipped records of the
+// reading methods are counted in it.  If Logger is not nil, skipped and
+// filtered records, quotes accepted with LazyQuotes and the detection of
+// compressed input, of the header row and of the field count are logged to
+// it at debug level.
 type Reader struct {
-	Comma            rune // field delimiter (set to ',' by NewReader)
-	Comment          rune // comment character for start of line
-	FieldsPerRecord  int  // number of expected fields per record
-	LazyQuotes       bool // allow lazy quotes
-	TrailingComma    bool // ignored; here for backwards compatibility
-	TrimLeadingSpace bool // trim leading space
-	SkipLineOnErr    bool // skip rest of line on error
-	headers          []string
-	line             int
-	column           int
-	r                *bufio.Reader
-	field            bytes.Buffer
-}
-
-// NewReader returns a new Reader that reads from r.
+	Comma             rune          // field delimiter (set to ',' by NewReader)
+	Comment           rune          // comment character for start of line
+	InlineComment     rune          // comment character for the end of lines
+	CommentPrefixes   []string      // prefixes of comment lines, in addition to Comment
+	BlankLines        BlankPolicy   // handling of blank lines
+	WhitespaceBlank   bool          // lines of spaces and tabs are blank lines
+	Continuation      rune          // character joining a line to the next one
+	NULs              NULPolicy     // handling of NUL bytes
+	FieldsPerRecord   int           // number of expected fields per record
+	LazyQuotes        bool          // allow lazy quotes
+	TrailingComma     bool          // ignored; here for backwards compatibility
+	TrimLeadingSpace  bool          // trim leading space
+	TrimTrailingSpace bool          // trim trailing space
+	TrimFields        bool          // trim leading and trailing space
+	SkipLineOnErr     bool          // skip rest of line on error
+	ColumnMapping     []ColumnMap   // rename and reorder columns
+	NullValues        []string      // values read as nil by the nullable map methods
+	BareEmptyNull     bool          // unquoted empty fields are read as nil by the nullable map methods
+	ExtraFields       ExtraPolicy   // handling of fields past the headers in maps
+	ExtraKey          string        // map key of the fields joined by JoinExtraFields
+	Stats             *Stats        // collects statistics of the records read
+	JSONInferRows     int           // records sampled to infer JSON value types
+	Limit             int           // maximum number of records after the header row; 0 for no limit
+	MaxRows           int           // records after the header row past which reading fails; 0 for no limit
+	Index             *Index        // record offsets used by ReadAt
+	ReuseRecord       bool          // Read may reuse the slice of the previous record
+	ZeroCopy          bool          // fields returned by Read alias an internal buffer
+	KeepComments      bool          // capture comment lines for Comments
+	FixedColumns      []FixedColumn // columns of fixed-width records
+	BackslashEscapes  bool          // fields are escaped with backslashes rather than quoted
+
+	Defaults               map[string]string // struct field values for missing columns
+	DisallowUnknownColumns bool              // struct decoding fails on columns without a field
+
+	Filter    func(record []string) bool          // keep records for which Filter is true
+	FilterMap func(record map[string]string) bool // keep map records for which FilterMap is true
+
+	OnProgress       func(p Progress) // called as records are read
+	ProgressInterval int              // records between calls to OnProgress; DefaultProgressInterval if 0
+	Metrics          Metrics          // counts records, bytes and errors as they are read
+	Logger           *slog.Logger     // logs skipped records and repairs at debug level
+
+	headers    []string
+	headerRead bool           // the first record, the header row if any, was read
+	preset     []string       // headers set by WithHeaders, kept by Reset
+	selection  []string       // column names passed to Select
+	columns    []int          // selected column indexes; nil means all columns
+	names      []string       // output column names; nil means use headers
+	index      map[string]int // output column indexes by name
+
+	transforms      map[string][]TransformFunc // transforms by header
+	indexTransforms map[int][]TransformFunc    // transforms by column index
+	timeLayouts     map[string][]string        // time layouts by header
+	validators      map[string][]Validator     // validators by header
+
+	comments []Comment // comment lines captured with KeepComments
+	records  int       // records parsed
+	returned int       // records returned, including the header row
+	filtered int       // records dropped by Filter and FilterMap
+	inferred bool      // FieldsPerRecord was set from the first record
+	errors   int       // errors returned, for OnProgress
+	reported Progress  // last Progress passed to OnProgress
+	metered  Progress  // records and bytes added to Metrics
+
+	reuse     bool     // ReuseRecord applies to the record being read
+	alias     bool     // ZeroCopy applies to the record being read
+	record    []string // slice reused for parsed records
+	projected []string // slice reused for selected columns
+
+	sources     []io.Reader // inputs following r, not nil for MultiReader
+	firstRecord []string    // first record of the first input
+	sourceStart bool        // no record has been read from r yet
+
+	line       int
+	recordLine int           // line where the last record started
+	recordPos  int64         // offset where the last record started
+	offset     int64         // bytes of input read
+	dataStart  int64         // offset of the first record after the header row
+	seeker     io.ReadSeeker // input of NewReader, if seekable
+	column     int
+	r          *bufio.Reader
+	compressed *bufio.Reader  // compressed input read by r, if decompressing
+	detect     bool           // startRecord checks the input for compression
+	field      bytes.Buffer   // fields of the record being parsed
+	fieldEnds  []int          // end of each field in field
+	scanner    *recordScanner // finds the end of records for ReadLazy
+
+	classes    [256]uint8     // classes of the input bytes, for classesFor
+	classesFor byteClassesKey // options of classes
+
+	copyFormat CopyFormat // COPY format read, if not 0
+	quoted     bool       // the last field parsed was quoted
+	quotes     []bool     // fields of the last record that were quoted
+	nulls      []bool     // fields of the last record that are NULL in copyFormat or bare empty
+}
+
+// NewReader returns a new Reader that reads from r.  Input compressed with
+// gzip, bzip2 or a format registered with RegisterDecompressor is detected by
+// its magic bytes and decompressed.
 func NewReader(r io.Reader) *Reader {
+	reader := newPlainReader(r)
+	reader.seeker, _ = r.(io.ReadSeeker)
+	reader.detect = true
+	return reader
+}
+
+// newPlainReader returns a new Reader of r that does not detect compressed
+// input.
+func newPlainReader(r io.Reader) *Reader {
 	return &Reader{
 		Comma: ',',
-		r:     bufio.NewReader(r),
+		r:     getBufioReader(r),
 	}
 }
 
+// Reset discards the state of r, such as its position, line count, headers
+// and buffered input, and makes it read src as if it were returned by
+// NewReader, so that Readers can be kept in a sync.Pool rather than
+// allocated for each input.  The exported fields and the options set by
+// methods such as Select, Transform and Validate are kept, except for
+// FieldsPerRecord if it was set from the first record, which is 0 again.
+// Headers set by WithHeaders are kept too.
+func (r *Reader) Reset(src io.Reader) {
+	r.seeker, _ = src.(io.ReadSeeker)
+	r.release()
+	r.r = getBufioReader(src)
+	r.detect = true
+	if r.inferred {
+		r.FieldsPerRecord, r.inferred = 0, false
+	}
+	r.headers, r.headerRead = r.preset, false
+	if r.selection != nil {
+		r.columns = nil // resolved from the headers
+	}
+	r.index = nil
+	r.comments = nil
+	r.records, r.returned, r.filtered, r.errors = 0, 0, 0, 0
+	r.reported, r.metered = Progress{}, Progress{}
+	r.sources, r.firstRecord, r.sourceStart = nil, nil, false
+	r.line, r.recordLine, r.column = 0, 0, 0
+	r.recordPos, r.offset, r.dataStart = 0, 0, 0
+	r.field.Reset()
+	r.fieldEnds = r.fieldEnds[:0]
+	r.scanner = nil
+	r.quoted = false
+	r.quotes, r.nulls = r.quotes[:0], r.nulls[:0]
+}
+
 // error creates a new ParseError based on err.
 func (r *Reader) error(err error) error {
 	return &ParseError{
@@ -133,6 +400,13 @@ func (r *Reader) error(err error) error {
 	}
 }
 
+// debug logs msg to r.Logger at debug level, with the current line.  Callers
+// check that r.Logger is not nil first, so that args are not built for
+// nothing.
+func (r *Reader) debug(msg string, args ...any) {
+	r.Logger.Debug(msg, append([]any{"line", r.line}, args...)...)
+}
+
 // Return headers if it has been set, or read the first row
 func (r *Reader) Headers() (headers []string, err error) {
 	if r.headers == nil {
@@ -144,59 +418,234 @@ func (r *Reader) Headers() (headers []string, err error) {
 	return r.headers, nil
 }
 
+// Select restricts the records returned by the Read and ReadToMap methods to
+// the named columns, in the given order.  Names are looked up in the header
+// row; if the headers have not been read yet, the first record is used.
+// Calling Select with no names returns all columns again.
+func (r *Reader) Select(names ...string) {
+	r.selection = nil
+	r.columns = nil
+	r.index = nil
+	if len(names) > 0 {
+		r.selection = names
+	}
+}
+
+// SelectIndexes restricts the records returned by the Read and ReadToMap
+// methods to the columns at the given indexes, in the given order.  The first
+// column is 0.  Calling SelectIndexes with no indexes returns all columns
+// again.
+func (r *Reader) SelectIndexes(indexes ...int) {
+	r.selection = nil
+	r.columns = nil
+	r.index = nil
+	if len(indexes) > 0 {
+		r.columns = indexes
+	}
+}
+
+// Transform registers functions that are applied, in order, to each field of
+// the named column before records are returned.  The header row is not
+// transformed.
+func (r *Reader) Transform(name string, fns ...TransformFunc) {
+	if r.transforms == nil {
+		r.transforms = make(map[string][]TransformFunc)
+	}
+	r.transforms[name] = append(r.transforms[name], fns...)
+}
+
+// TransformIndex registers functions that are applied, in order, to each
+// field of the column at index before records are returned.  The first column
+// is 0.  As with Filter, the header row is not transformed, but Read reads
+// the first line as a record, transformed, unless column names are used.
+func (r *Reader) TransformIndex(index int, fns ...TransformFunc) {
+	if r.indexTransforms == nil {
+		r.indexTransforms = make(map[int][]TransformFunc)
+	}
+	r.indexTransforms[index] = append(r.indexTransforms[index], fns...)
+}
+
 // Read reads one record from r.  The record is a slice of strings with each
-// string representing one field.
+// string representing one field.  If ReuseRecord is true, the slice may be
+// overwritten by the next call to Read.
+//
+// If ZeroCopy is true, the fields are not copied out of the buffer they are
+// parsed into, and their bytes change on the next call to Read: they must be
+// copied, for instance with strings.Clone, to be kept.  ZeroCopy is ignored
+// when Stats or validators, which keep fields, are set.
 func (r *Reader) Read() (record []string, err error) {
+	r.reuse = r.ReuseRecord
+	r.alias = r.ZeroCopy && r.Stats == nil && r.validators == nil
+	record, _, err = r.read(r.needsHeaders())
+	r.reuse, r.alias = false, false
+	return record, err
+}
+
+// readNew is Read ignoring ReuseRecord, for callers that keep the records.
+func (r *Reader) readNew() (record []string, err error) {
+	record, _, err = r.read(r.needsHeaders())
+	return record, err
+}
+
+// Read reads one record from r.  The record is a map of strings with each
+// key being the header and value being the field.
+func (r *Reader) ReadToMap() (recordMap map[string]string, err error) {
 	for {
-		record, err = r.parseRecord()
-		if record != nil {
-			break
-		}
+		record, isHeader, err := r.read(true)
 		if err != nil {
 			return nil, err
 		}
+		if recordMap, err = r.recordToMap(record); err != nil {
+			return nil, err
+		}
+		if isHeader || r.keepMap(recordMap) {
+			return recordMap, nil
+		}
 	}
+}
 
-	if r.FieldsPerRecord > 0 {
-		if len(record) != r.FieldsPerRecord {
-			r.column = 0 // report at start of record
-			return record, r.error(ErrFieldCount)
+// read reads the next record from r that is kept by Filter and adds it to
+// Stats.  If captureHeaders is true and the headers have not been read, the
+// first record is kept as the headers and isHeader is true.  The header row is
+// never filtered.  Once Limit records follow the header row, read returns
+// io.EOF.  Reading a record past MaxRows returns ErrMaxRows, then io.EOF.
+func (r *Reader) read(captureHeaders bool) (record []string, isHeader bool, err error) {
+	if r.atLimit() {
+		if r.OnProgress != nil {
+			r.progress(io.EOF)
+		}
+		return nil, false, io.EOF
+	}
+	for {
+		record, isHeader, err = r.nextRecord(captureHeaders)
+		if err == nil {
+			r.returned++
+			if r.pastMaxRows() {
+				record, isHeader, err = nil, false, ErrMaxRows
+			}
+		}
+		if r.OnProgress != nil {
+			r.progress(err)
 		}
-	} else if r.FieldsPerRecord == 0 {
-		r.FieldsPerRecord = len(record)
+		if r.Metrics != nil {
+			r.meter(err)
+		}
+		if r.Logger != nil && err != nil && err != io.EOF && r.skipError(err) {
+			r.debug("skipped record", "error", err)
+		}
+		if err != nil || isHeader {
+			return record, isHeader, err
+		}
+		if r.Filter == nil || r.Filter(record) {
+			break
+		}
+		r.filtered++
+		if r.Metrics != nil {
+			r.Metrics.AddSkippedLines(1)
+		}
+		if r.Logger != nil {
+			r.debug("filtered record")
+		}
+	}
+	if r.Stats != nil {
+		if r.Stats.headers == nil {
+			r.Stats.headers = r.outputHeaders()
+		}
+		r.Stats.Add(record)
 	}
-	return record, nil
+	return record, false, nil
 }
 
-// Read reads one record from r.  The record is a map of strings with each
-// key being the header and value being the field.
-func (r *Reader) ReadToMap() (recordMap map[string]string, err error) {
-	var record []string
-	recordMap = make(map[string]string)
+// keepMap reports whether FilterMap keeps recordMap, counting the records it
+// drops.
+func (r *Reader) keepMap(recordMap map[string]string) bool {
+	if r.FilterMap == nil || r.FilterMap(recordMap) {
+		return true
+	}
+	r.filtered++
+	return false
+}
+
+// nextRecord reads the next record from r, checks its field count and applies
+// the column selection.
+func (r *Reader) nextRecord(captureHeaders bool) (record []string, isHeader bool, err error) {
 	for {
 		record, err = r.parseRecord()
-		if r.headers == nil && r.line == 1 {
-			r.headers = record
+		if r.sources != nil && r.nextSource(record, err) {
+			continue
+		}
+		if record == nil && err == nil {
+			continue // comment or skipped blank line
+		}
+		// The header row is the first record, whatever comments or blank
+		// lines precede it.
+		if captureHeaders && r.headers == nil && !r.headerRead && record != nil {
+			r.headers = r.keepRecord(record)
+			r.dataStart = r.offset
+			isHeader = true
+			if r.Logger != nil {
+				r.debug("read header row", "headers", r.headers)
+			}
+		}
+		if err != io.EOF || record != nil {
+			r.headerRead = true
 		}
 		if record != nil {
+			r.records++
 			break
 		}
-		if err != nil {
-			return nil, err
-		}
+		return nil, false, err
 	}
 
+	// Blank lines read with EmptyBlankLines have no field count.
 	if r.FieldsPerRecord > 0 {
-		if len(record) != r.FieldsPerRecord {
+		if len(record) != r.FieldsPerRecord && len(record) > 0 {
 			r.column = 0 // report at start of record
-			return nil, r.error(ErrFieldCount)
+			return record, isHeader, r.error(ErrFieldCount)
+		}
+	} else if r.FieldsPerRecord == 0 && len(record) > 0 {
+		r.FieldsPerRecord, r.inferred = len(record), true
+		if r.Logger != nil {
+			r.debug("set field count from first record", "fields", r.FieldsPerRecord)
 		}
-	} else if r.FieldsPerRecord == 0 {
-		r.FieldsPerRecord = len(record)
 	}
-	recordMap = r.recordToMap(record)
+	if !isHeader {
+		r.transform(record)
+		if err = r.validate(record); err != nil {
+			return nil, false, err
+		}
+	}
+	if record, err = r.project(record); err != nil {
+		return nil, isHeader, err
+	}
+	if isHeader && r.names != nil {
+		record = append([]string(nil), r.names...)
+	}
+	return record, isHeader, nil
+}
 
-	return recordMap, nil
+// ReadN reads up to n records from r, like Read.  It returns fewer records
+// at the end of the input, and io.EOF once no records remain.  Records with
+// errors are skipped if SkipLineOnErr is true; otherwise the records read
+// before the error are returned with it.
+func (r *Reader) ReadN(n int) (records [][]string, err error) {
+	for len(records) < n {
+		record, err := r.readNew()
+		if err == io.EOF {
+			if records == nil {
+				return nil, io.EOF
+			}
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
 }
 
 // ReadAll reads all the remaining records from r.
@@ -206,12 +655,12 @@ func (r *Reader) ReadToMap() (recordMap map[string]string, err error) {
 // reported.
 func (r *Reader) ReadAll() (records [][]string, err error) {
 	for {
-		record, err := r.Read()
+		record, err := r.readNew()
 		if err == io.EOF {
 			return records, nil
 		}
 		if err != nil {
-			if r.SkipLineOnErr {
+			if r.skipError(err) {
 				continue
 			}
 			return nil, err
@@ -234,7 +683,7 @@ func (r *Reader) ReadAllToMaps() (records []map[string]string, err error) {
 			return records, nil
 		}
 		if err != nil {
-			if r.SkipLineOnErr {
+			if r.skipError(err) {
 				continue
 			}
 			return nil, err
@@ -243,6 +692,52 @@ func (r *Reader) ReadAllToMaps() (records []map[string]string, err error) {
 	}
 }
 
+// ReadColumns reads all the remaining records from r and returns their
+// fields by column, with the header being the key and the fields of the
+// column, in record order, being the value.  The header row is not included.
+// Fields missing from short records are empty.  Unlike ReadAllToMaps,
+// FilterMap is not applied.
+func (r *Reader) ReadColumns() (columns map[string][]string, err error) {
+	var headers []string
+	var fields [][]string
+	for {
+		record, isHeader, err := r.read(true)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return nil, err
+		}
+		if isHeader {
+			continue
+		}
+		if headers == nil {
+			headers = r.outputHeaders()
+			fields = make([][]string, len(headers))
+		}
+		for i := range fields {
+			field := ""
+			if i < len(record) {
+				field = record[i]
+			}
+			fields[i] = append(fields[i], field)
+		}
+	}
+
+	if headers == nil {
+		headers = r.outputHeaders()
+		fields = make([][]string, len(headers))
+	}
+	columns = make(map[string][]string, len(headers))
+	for i, header := range headers {
+		columns[header] = fields[i]
+	}
+	return columns, nil
+}
+
 // ReadAllWithErrors reads all the remaining records from r.
 // Each record is a slice of fields.
 // A successful call returns a slice of records and a slice of errors.
@@ -252,7 +747,7 @@ func (r *Reader) ReadAllWithErrors() (records [][]string, errs []error) {
 	skipLine := r.SkipLineOnErr
 	r.SkipLineOnErr = true
 	for {
-		record, err := r.Read()
+		record, err := r.readNew()
 		if err == io.EOF {
 			r.SkipLineOnErr = skipLine
 			return records, errs
@@ -288,37 +783,403 @@ func (r *Reader) ReadAllToMapsWithErrors() (records []map[string]string, errs []
 	}
 }
 
+// EachMap reads the remaining records from r using ReadToMap, calling fn with
+// each of them without keeping them in memory, until fn returns false.  If
+// SkipLineOnErr is true, fn is also called with the ParseError of each
+// skipped record and a nil map; otherwise reading stops at the first error,
+// which is returned.  Other errors, such as those of Validate, stop reading
+// too.  At the end of the input, EachMap returns nil.
+func (r *Reader) EachMap(fn func(recordMap map[string]string, err *ParseError) bool) error {
+	for {
+		recordMap, err := r.ReadToMap()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			parseErr, ok := err.(*ParseError)
+			if !ok || !r.SkipLineOnErr {
+				return err
+			}
+			if !fn(nil, parseErr) {
+				return nil
+			}
+			continue
+		}
+		if !fn(recordMap, nil) {
+			return nil
+		}
+	}
+}
+
+// ReadToNullableMap reads one record from r like ReadToMap, except that the
+// values are nil for fields matching one of the NullValues and for columns
+// missing from the end of the record.  Records may only be shorter than the
+// headers if FieldsPerRecord is negative.
+func (r *Reader) ReadToNullableMap() (recordMap map[string]*string, err error) {
+	for {
+		record, isHeader, err := r.read(true)
+		if err != nil {
+			return nil, err
+		}
+		if !isHeader && r.FilterMap != nil {
+			filterMap, err := r.recordToMap(record)
+			if err != nil {
+				return nil, err
+			}
+			if !r.keepMap(filterMap) {
+				continue
+			}
+		}
+		return r.recordToNullableMap(record, isHeader)
+	}
+}
+
+// ReadAllToNullableMaps reads all the remaining records from r using
+// ReadToNullableMap.
+// A successful call returns err == nil, not err == EOF. Because
+// ReadAllToNullableMaps is defined to read until EOF, it does not treat end of
+// file as an error to be reported.
+func (r *Reader) ReadAllToNullableMaps() (records []map[string]*string, err error) {
+	for {
+		record, err := r.ReadToNullableMap()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
 // recordToMap will take in a normal csv record and convert it into a map
-// with the headers as the keys and the record values as the values.
-func (r *Reader) recordToMap(record []string) (recordMap map[string]string) {
+// with the headers as the keys and the record values as the values.  Fields
+// past the headers are handled according to ExtraFields.
+func (r *Reader) recordToMap(record []string) (recordMap map[string]string, err error) {
 	recordMap = make(map[string]string)
+	keys := r.outputHeaders()
 	for index, field := range record {
-		key := r.headers[index]
-		recordMap[key] = field
+		if index == len(keys) {
+			break
+		}
+		recordMap[keys[index]] = field
+	}
+	extra, ok, err := r.extraFields(record)
+	if ok {
+		recordMap[r.extraKey()] = extra
+	}
+	return recordMap, err
+}
+
+// recordToNullableMap converts record into a map like recordToMap.  Fields
+// matching one of the NullValues and columns missing from the end of record
+// are nil.
+func (r *Reader) recordToNullableMap(record []string, isHeader bool) (recordMap map[string]*string, err error) {
+	recordMap = make(map[string]*string)
+	extra, ok, err := r.extraFields(record)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		recordMap[r.extraKey()] = &extra
+	}
+	for index, key := range r.outputHeaders() {
+		if index >= len(record) {
+			recordMap[key] = nil
+			continue
+		}
+		field := record[index]
+		if !isHeader && r.fieldIsNull(index, field) {
+			recordMap[key] = nil
+			continue
+		}
+		recordMap[key] = &field
 	}
-	return recordMap
+	return recordMap, nil
+}
+
+// extraFields returns the fields of record past the headers joined with
+// Comma, and whether they are kept in maps under ExtraKey.  With
+// ErrorExtraFields, it returns a ParseError if there are any.
+func (r *Reader) extraFields(record []string) (extra string, ok bool, err error) {
+	n := len(r.outputHeaders())
+	if len(record) <= n {
+		return "", false, nil
+	}
+	switch r.ExtraFields {
+	case ErrorExtraFields:
+		return "", false, &ParseError{Line: r.recordLine, Err: ErrExtraFields}
+	case JoinExtraFields:
+		return strings.Join(record[n:], string(r.Comma)), true, nil
+	}
+	return "", false, nil
+}
+
+// extraKey returns the map key of the fields joined by JoinExtraFields.
+func (r *Reader) extraKey() string {
+	if r.ExtraKey == "" {
+		return "_extra"
+	}
+	return r.ExtraKey
+}
+
+// isNull reports whether field is one of the NullValues.
+func (r *Reader) isNull(field string) bool {
+	for _, null := range r.NullValues {
+		if field == null {
+			return true
+		}
+	}
+	return false
+}
+
+// outputHeaders returns the names of the columns of the records returned by
+// r, after selection and mapping.
+func (r *Reader) outputHeaders() []string {
+	if r.names != nil {
+		return r.names
+	}
+	if r.columns == nil {
+		return r.headers
+	}
+	keys := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		keys[i] = r.headers[column]
+	}
+	return keys
+}
+
+// transform applies the registered transforms to record in place.
+func (r *Reader) transform(record []string) {
+	if r.transforms == nil && r.indexTransforms == nil {
+		return
+	}
+	for index := range record {
+		for _, fn := range r.indexTransforms[index] {
+			record[index] = fn(record[index])
+		}
+		if index < len(r.headers) {
+			for _, fn := range r.transforms[r.headers[index]] {
+				record[index] = fn(record[index])
+			}
+		}
+	}
+}
+
+// project returns the selected columns of record in selection order.  If no
+// columns are selected, record is returned unchanged.
+func (r *Reader) project(record []string) ([]string, error) {
+	if r.ColumnMapping != nil && r.names == nil {
+		r.selection = make([]string, len(r.ColumnMapping))
+		r.names = make([]string, len(r.ColumnMapping))
+		for i, m := range r.ColumnMapping {
+			r.selection[i] = m.From
+			r.names[i] = m.To
+		}
+		r.columns = nil
+	}
+	if r.selection != nil && r.columns == nil {
+		if err := r.resolveSelection(); err != nil {
+			return nil, err
+		}
+	}
+	if r.columns == nil {
+		return record, nil
+	}
+	projected := r.recordSlice(&r.projected, len(r.columns))
+	for i, index := range r.columns {
+		if index < 0 || index >= len(record) {
+			r.column = 0 // report at start of record
+			return nil, r.error(ErrFieldCount)
+		}
+		projected[i] = record[index]
+	}
+	return projected, nil
+}
+
+// needsHeaders reports whether the header row must be captured by Read to
+// resolve column names.
+func (r *Reader) needsHeaders() bool {
+	return r.selection != nil || r.ColumnMapping != nil || r.transforms != nil || r.validators != nil
+}
+
+// resolveSelection converts the column names passed to Select into column
+// indexes using the headers.
+func (r *Reader) resolveSelection() error {
+	columns := make([]int, len(r.selection))
+	for i, name := range r.selection {
+		columns[i] = -1
+		for index, header := range r.headers {
+			if header == name {
+				columns[i] = index
+				break
+			}
+		}
+		if columns[i] < 0 {
+			r.column = 0
+			return r.error(fmt.Errorf("%w %q", ErrUnknownColumn, name))
+		}
+	}
+	r.columns = columns
+	return nil
 }
 
 // readRune reads one rune from r, folding \r\n to \n and keeping track
 // of how far into the line we have read.  r.column will point to the start
 // of this rune, not the end of this rune.
+//
+// The delimiters, quotes and line ends the parser looks for are usually
+// ASCII, so readRune reads single bytes and only decodes runes for bytes
+// outside ASCII.  Runs of bytes needing no handling are read by readPlain.
 func (r *Reader) readRune() (rune, error) {
-	r1, _, err := r.r.ReadRune()
+	r.column++
+	b, err := r.r.ReadByte()
+	for err == nil && b == 0 && r.NULs == StripNULs {
+		r.offset++
+		b, err = r.r.ReadByte()
+	}
+	if err != nil {
+		return 0, err
+	}
+	if b == 0 && r.NULs == ErrorNULs {
+		r.offset++
+		err := r.error(ErrNUL)
+		if r.SkipLineOnErr {
+			r.skipLine()
+		}
+		return 0, err
+	}
+	if b >= utf8.RuneSelf {
+		r.r.UnreadByte()
+		r1, size, err := r.r.ReadRune()
+		r.offset += int64(size)
+		return r1, err
+	}
+	r.offset++
 
 	// Handle \r\n here.  We make the simplifying assumption that
 	// anytime \r is followed by \n that it can be folded to \n.
 	// We will not detect files which contain both \r\n and bare \n.
-	if r1 == '\r' {
-		r1, _, err = r.r.ReadRune()
-		if err == nil {
-			if r1 != '\n' {
-				r.r.UnreadRune()
-				r1 = '\r'
+	if b == '\r' {
+		next, err := r.r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if next[0] == '\n' {
+			r.r.Discard(1)
+			r.offset++
+			return '\n', nil
+		}
+	}
+	return rune(b), nil
+}
+
+// Byte classes of the input, as flags of Reader.classes.
+const (
+	specialByte       = 1 << iota // ends a run of unquoted field bytes
+	specialQuotedByte             // ends a run of quoted field bytes
+	nonASCIIByte                  // part of a multi-byte rune
+)
+
+// byteClassesKey holds the options of r the classes of the input bytes
+// depend on.
+type byteClassesKey struct {
+	special [3]rune // Comma, InlineComment and Continuation
+	nuls    NULPolicy
+}
+
+// byteClasses returns the classes of the input bytes for r.Comma,
+// r.InlineComment, r.Continuation and r.NULs.
+func (r *Reader) byteClasses() *[256]uint8 {
+	key := byteClassesKey{[3]rune{r.Comma, r.InlineComment, r.Continuation}, r.NULs}
+	if r.classes['\n'] != 0 && r.classesFor == key {
+		return &r.classes
+	}
+	for i := range r.classes {
+		r.classes[i] = 0
+		if i >= utf8.RuneSelf {
+			r.classes[i] = nonASCIIByte
+		}
+	}
+	for _, b := range [...]byte{'"', '\n', '\r'} {
+		r.classes[b] = specialByte | specialQuotedByte
+	}
+	var b [utf8.UTFMax]byte
+	for _, r1 := range key.special {
+		if r1 != 0 {
+			utf8.EncodeRune(b[:], r1)
+			r.classes[b[0]] |= specialByte
+		}
+	}
+	if r.NULs != KeepNULs {
+		r.classes[0] = specialByte | specialQuotedByte
+	}
+	r.classesFor = key
+	return &r.classes
+}
+
+// readPlain adds to the field the buffered input up to the next quote, line
+// end or, unless quoted is true, delimiter.  It saves reading the runes that
+// need no handling one at a time; invalid UTF-8 is left to readRune, which
+// replaces it.
+func (r *Reader) readPlain(quoted bool) {
+	buf, _ := r.r.Peek(r.r.Buffered())
+	classes := r.byteClasses()
+	special := uint8(specialByte)
+	if quoted {
+		special = specialQuotedByte
+	}
+	var seen uint8
+	end := 0
+	for end < len(buf) {
+		class := classes[buf[end]]
+		if class&special != 0 {
+			break
+		}
+		seen |= class
+		end++
+	}
+	plain := buf[:end]
+	runes := len(plain)
+	if seen&nonASCIIByte != 0 {
+		if !utf8.Valid(plain) {
+			n := 0
+			for n < len(plain) {
+				r1, size := utf8.DecodeRune(plain[n:])
+				if r1 == utf8.RuneError && size <= 1 {
+					break
+				}
+				n += size
 			}
+			plain = plain[:n]
+		}
+		runes = utf8.RuneCount(plain)
+	}
+	if len(plain) == 0 {
+		return
+	}
+	r.field.Write(plain)
+	r.column += runes
+	r.offset += int64(len(plain))
+	r.r.Discard(len(plain))
+}
+
+// skipLine reads the bytes up to and including the next '\n', without
+// decoding them.
+func (r *Reader) skipLine() {
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return
+		}
+		r.offset++
+		if b == '\n' {
+			return
 		}
 	}
-	r.column++
-	return r1, err
 }
 
 // skip reads runes up to and including the rune delim or until error.
@@ -336,48 +1197,305 @@ func (r *Reader) skip(delim rune) error {
 
 // parseRecord reads and parses a single csv record from r.
 func (r *Reader) parseRecord() (fields []string, err error) {
+	if skip, err := r.startRecord(); skip {
+		return nil, err
+	}
+	if r.copyFormat != 0 && r.atCopyEnd() {
+		r.release()
+		return nil, io.EOF
+	}
+	r.quotes = r.quotes[:0]
+	// Escaped and COPY formats write a record of one empty field as an
+	// empty line, which is not a blank line for them.
+	if (r.copyFormat != 0 || r.BackslashEscapes) && r.atEmptyLine() {
+		return r.emptyLineField(), nil
+	}
+	if blank, err := r.readBlankLine(); blank {
+		if err != nil || r.BlankLines == SkipBlankLines {
+			return nil, err
+		}
+		return []string{}, nil
+	}
+	if r.FixedColumns != nil {
+		return r.parseFixed()
+	}
+	if r.BackslashEscapes {
+		return r.parseEscaped()
+	}
+	err = r.parseFields()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return r.splitFields(), err
+}
+
+// readBlankLine reads the line at the start of the input if it is a blank
+// line that BlankLines or WhitespaceBlank require to be handled rather than
+// parsed, and reports whether it did.  With ErrorBlankLines, err is the
+// error of the blank line.
+func (r *Reader) readBlankLine() (blank bool, err error) {
+	if r.BlankLines == SkipBlankLines && !r.WhitespaceBlank || !r.atBlankLine() {
+		return false, nil
+	}
+	if err := r.skip('\n'); err != nil && err != io.EOF {
+		return true, err
+	}
+	if r.BlankLines == ErrorBlankLines {
+		r.column = 0 // report at start of line
+		return true, r.error(ErrBlankLine)
+	}
+	return true, nil
+}
+
+// atBlankLine reports whether the input is at a blank line, or at a line of
+// spaces and tabs if WhitespaceBlank is true.
+func (r *Reader) atBlankLine() bool {
+	for i := 0; ; i++ {
+		b, _ := r.r.Peek(i + 1)
+		if len(b) <= i {
+			return i > 0 && len(b) == i // white space up to the end of input
+		}
+		switch b[i] {
+		case '\n':
+			return true
+		case '\r':
+			next, _ := r.r.Peek(i + 2)
+			return len(next) == i+2 && next[i+1] == '\n'
+		case ' ', '\t':
+			if !r.WhitespaceBlank {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+}
+
+// startRecord starts reading a record.  It reports whether the line must be
+// skipped, either because it is a comment or because of err.
+func (r *Reader) startRecord() (skip bool, err error) {
 	// Each record starts on a new line.  We increment our line
 	// number (lines start at 1, not 0) and set column to -1
 	// so as we increment in readRune it points to the character we read.
 	r.line++
+	r.recordLine = r.line
+	r.recordPos = r.offset
 	r.column = -1
+	if r.r == nil {
+		return true, io.EOF
+	}
+	if r.detect {
+		if err := r.detectCompression(); err != nil {
+			return true, err
+		}
+	}
 
 	// Peek at the first rune.  If it is an error we are done.
 	// If we are support comments and it is the comment character
 	// then skip to the end of line.
 
-	r1, _, err := r.r.ReadRune()
+	b, err := r.r.Peek(1)
+	if err == io.EOF {
+		r.release()
+	}
 	if err != nil {
-		return nil, err
+		return true, err
+	}
+	if prefix := r.commentPrefix(b[0]); prefix != "" {
+		for range utf8.RuneCountInString(prefix) {
+			r.readRune() // the comment prefix
+		}
+		if r.KeepComments {
+			return true, r.readComment()
+		}
+		return true, r.skip('\n')
 	}
+	return false, nil
+}
 
-	if r.Comment != 0 && r1 == r.Comment {
-		return nil, r.skip('\n')
+// commentPrefix returns the comment prefix the input, whose next byte is b,
+// starts with: Comment or the first of CommentPrefixes it starts with, or ""
+// if it does not start a comment.
+func (r *Reader) commentPrefix(b byte) string {
+	if r.Comment != 0 && r.startsComment(b) {
+		return string(r.Comment)
+	}
+	for _, prefix := range r.CommentPrefixes {
+		if prefix == "" || prefix[0] != b {
+			continue
+		}
+		if next, _ := r.r.Peek(len(prefix)); string(next) == prefix {
+			return prefix
+		}
 	}
-	r.r.UnreadRune()
+	return ""
+}
 
-	// At this point we have at least one field.
+// startsComment reports whether the input, whose next byte is b, starts
+// with r.Comment.
+func (r *Reader) startsComment(b byte) bool {
+	if r.Comment < utf8.RuneSelf {
+		return rune(b) == r.Comment
+	}
+	next, _ := r.r.Peek(utf8.UTFMax)
+	r1, _ := utf8.DecodeRune(next)
+	return r1 == r.Comment
+}
+
+// parseFields reads the fields of a delimited record.  The fields are read
+// one after the other into r.field, ending at r.fieldEnds, and converted
+// to strings by splitFields once the record is complete.
+func (r *Reader) parseFields() error {
+	r.field.Reset()
+	r.fieldEnds = r.fieldEnds[:0]
+	r.nulls = r.nulls[:0]
 	for {
+		start := r.field.Len()
 		haveField, delim, err := r.parseField()
 		if haveField {
-			fields = append(fields, r.field.String())
+			r.fieldEnds = append(r.fieldEnds, r.field.Len())
+			r.quotes = append(r.quotes, r.quoted)
+			if r.copyFormat != 0 || r.BareEmptyNull {
+				// Unquoted empty fields are the NULL values of CopyCSV and
+				// of BareEmptyNull.
+				r.nulls = append(r.nulls, !r.quoted && r.field.Len() == start)
+			}
 		}
-		if delim == '\n' || err == io.EOF {
-			return fields, err
-		} else if err != nil {
-			return nil, err
+		if delim == '\n' || err != nil {
+			return err
+		}
+	}
+}
+
+// recordSlice returns a slice of n fields, which is *reused if ReuseRecord
+// applies and it is large enough.
+func (r *Reader) recordSlice(reused *[]string, n int) []string {
+	if !r.reuse {
+		return make([]string, n)
+	}
+	if cap(*reused) < n {
+		*reused = make([]string, n)
+	}
+	return (*reused)[:n]
+}
+
+// keepRecord returns a copy of record that is not changed by reading the
+// next records, even if ReuseRecord or ZeroCopy applies.
+func (r *Reader) keepRecord(record []string) []string {
+	kept := slices.Clone(record)
+	if r.alias {
+		for i, field := range kept {
+			kept[i] = strings.Clone(field)
+		}
+	}
+	return kept
+}
+
+// splitFields returns the fields read into r.field.
+func (r *Reader) splitFields() []string {
+	if len(r.fieldEnds) == 0 {
+		return nil
+	}
+	var line string
+	if r.alias {
+		line = unsafe.String(unsafe.SliceData(r.field.Bytes()), r.field.Len())
+	} else {
+		line = r.field.String()
+	}
+	fields := r.recordSlice(&r.record, len(r.fieldEnds))
+	start := 0
+	for i, end := range r.fieldEnds {
+		fields[i] = line[start:end]
+		start = end
+	}
+	return fields
+}
+
+// atLimit reports whether the reading methods return io.EOF because Limit
+// records were read, or because a record past MaxRows was.
+func (r *Reader) atLimit() bool {
+	return r.Limit > 0 && r.returned > r.Limit || r.pastMaxRows()
+}
+
+// pastMaxRows reports whether the records returned, including the header
+// row, are more than MaxRows records after the header row.
+func (r *Reader) pastMaxRows() bool {
+	return r.MaxRows > 0 && r.returned > r.MaxRows+1
+}
+
+// skipError reports whether the reading methods skip the record of err,
+// because SkipLineOnErr is true and err is not ErrMaxRows.
+func (r *Reader) skipError(err error) bool {
+	return r.SkipLineOnErr && err != ErrMaxRows
+}
+
+// trimLeading reports whether leading white space in a field is ignored.
+func (r *Reader) trimLeading() bool {
+	return r.TrimLeadingSpace || r.TrimFields
+}
+
+// trimTrailing reports whether trailing white space in a field is ignored.
+func (r *Reader) trimTrailing() bool {
+	return r.TrimTrailingSpace || r.TrimFields
+}
+
+// continuesLine reports whether r1, read outside quotes, is a Continuation
+// at the end of its line, and if so skips the line end, so that the record
+// goes on with the next line.
+func (r *Reader) continuesLine(r1 rune) bool {
+	if r.Continuation == 0 || r1 != r.Continuation {
+		return false
+	}
+	b, _ := r.r.Peek(2)
+	switch {
+	case len(b) > 0 && b[0] == '\n':
+		r.r.Discard(1)
+		r.offset++
+	case string(b) == "\r\n":
+		r.r.Discard(2)
+		r.offset += 2
+	default:
+		return false
+	}
+	r.line++
+	r.column = -1
+	return true
+}
+
+// startsInlineComment reports whether r1, read outside quotes, starts an
+// InlineComment.
+func (r *Reader) startsInlineComment(r1 rune) bool {
+	return r.InlineComment != 0 && r1 == r.InlineComment
+}
+
+// skipInlineComment skips the InlineComment that was just read, up to the
+// end of the line, and ends the field starting at start in r.field, whose
+// trailing white space is trimmed unless quoted is true.  A comment taking
+// up the whole line leaves no field, like a blank line.
+func (r *Reader) skipInlineComment(start int, quoted bool) (haveField bool, delim rune, err error) {
+	if !quoted {
+		trimmed := bytes.TrimRightFunc(r.field.Bytes()[start:], unicode.IsSpace)
+		r.field.Truncate(start + len(trimmed))
+	}
+	haveField = quoted || len(r.fieldEnds) > 0 || r.field.Len() > start
+	if err = r.skip('\n'); err != nil {
+		if err == io.EOF && haveField {
+			return true, 0, err
 		}
+		return false, 0, err
 	}
+	return haveField, '\n', nil
 }
 
 // parseField parses the next field in the record.  The read field is
-// located in r.field.  Delim is the first character not part of the field
+// appended to r.field.  Delim is the first character not part of the field
 // (r.Comma or '\n').
 func (r *Reader) parseField() (haveField bool, delim rune, err error) {
-	r.field.Reset()
+	start := r.field.Len()
+	r.quoted = false
 
 	r1, err := r.readRune()
-	for err == nil && r.TrimLeadingSpace && r1 != '\n' && unicode.IsSpace(r1) {
+	for err == nil && (r.continuesLine(r1) || r.trimLeading() && r1 != '\n' && unicode.IsSpace(r1)) {
 		r1, err = r.readRune()
 	}
 
@@ -387,6 +1505,9 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 	if err != nil {
 		return false, 0, err
 	}
+	if r.startsInlineComment(r1) {
+		return r.skipInlineComment(start, false)
+	}
 
 	switch r1 {
 	case r.Comma:
@@ -401,12 +1522,17 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 
 	case '"':
 		// quoted field
+		r.quoted = true
 	Quoted:
 		for {
+			r.readPlain(true)
 			r1, err = r.readRune()
 			if err != nil {
 				if err == io.EOF {
 					if r.LazyQuotes {
+						if r.Logger != nil {
+							r.debug("accepted unterminated quoted field")
+						}
 						return true, 0, err
 					}
 					return false, 0, r.error(ErrQuote)
@@ -422,6 +1548,51 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 				if r1 == '\n' {
 					return true, r1, nil
 				}
+				if r.startsInlineComment(r1) {
+					return r.skipInlineComment(start, true)
+				}
+				if r1 != '"' && (r.trimTrailing() || r.InlineComment != 0) && unicode.IsSpace(r1) {
+					// Skip white space between the closing quote
+					// and the end of the field or an inline comment.
+					var spaces bytes.Buffer
+					for err == nil && r1 != '\n' && r1 != r.Comma && unicode.IsSpace(r1) {
+						spaces.WriteRune(r1)
+						r1, err = r.readRune()
+					}
+					if err == nil && r.startsInlineComment(r1) {
+						return r.skipInlineComment(start, true)
+					}
+					if r.trimTrailing() {
+						if err != nil || r1 == r.Comma {
+							break Quoted
+						}
+						if r1 == '\n' {
+							return true, r1, nil
+						}
+					}
+					if !r.LazyQuotes {
+						r.column--
+						if r.SkipLineOnErr {
+							r.skip('\n')
+						}
+						return false, 0, r.error(ErrQuote)
+					}
+					// accept the bare quote and the white space after it
+					if r.Logger != nil {
+						r.debug("accepted bare quote", "column", r.column)
+					}
+					r.field.WriteRune('"')
+					r.field.Write(spaces.Bytes())
+					if err != nil {
+						break Quoted
+					}
+					if r1 == '\n' {
+						r.line++
+						r.column = -1
+					}
+					r.field.WriteRune(r1)
+					continue
+				}
 				if r1 != '"' {
 					if !r.LazyQuotes {
 						r.column--
@@ -431,6 +1602,9 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 						return false, 0, r.error(ErrQuote)
 					}
 					// accept the bare quote
+					if r.Logger != nil {
+						r.debug("accepted bare quote", "column", r.column)
+					}
 					r.field.WriteRune('"')
 				}
 			case '\n':
@@ -444,12 +1618,16 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 		// unquoted field
 		for {
 			r.field.WriteRune(r1)
+			r.readPlain(false)
 			r1, err = r.readRune()
-			if err != nil || r1 == r.Comma {
+			for err == nil && r.continuesLine(r1) {
+				r1, err = r.readRune()
+			}
+			if err != nil || r1 == r.Comma || r1 == '\n' {
 				break
 			}
-			if r1 == '\n' {
-				return true, r1, nil
+			if r.startsInlineComment(r1) {
+				return r.skipInlineComment(start, false)
 			}
 			if !r.LazyQuotes && r1 == '"' {
 				if r.SkipLineOnErr {
@@ -457,6 +1635,16 @@ func (r *Reader) parseField() (haveField bool, delim rune, err error) {
 				}
 				return false, 0, r.error(ErrBareQuote)
 			}
+			if r1 == '"' && r.Logger != nil {
+				r.debug("accepted bare quote", "column", r.column)
+			}
+		}
+		if r.trimTrailing() {
+			trimmed := bytes.TrimRightFunc(r.field.Bytes()[start:], unicode.IsSpace)
+			r.field.Truncate(start + len(trimmed))
+		}
+		if err == nil && r1 == '\n' {
+			return true, r1, nil
 		}
 	}
 
@@ -5,6 +5,9 @@
 package bettercsv
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
 	"reflect"
 	"strings"
 	"testing"
@@ -25,19 +28,38 @@ type Test struct {
 	Input              string
 	Output             [][]string
 	OutputMap          []map[string]string
+	OutputNullableMap  []map[string]*string
 	Headers            []string
 	UseFieldsPerRecord bool // false (default) means FieldsPerRecord is -1
 	UseHeaders         bool // true means use Headers methods for reading
 	UseHeadersAndErrs  bool // true means use HeadersAndErrors methods for reading
+	UseNullable        bool // true means use nullable map methods for reading
+	Select             []string
+	SelectIndexes      []int
 
 	// These fields are copied into the Reader
-	Comma            rune
-	Comment          rune
-	FieldsPerRecord  int
-	LazyQuotes       bool
-	TrailingComma    bool
-	TrimLeadingSpace bool
-	SkipLineOnErr    bool
+	Comma             rune
+	Comment           rune
+	InlineComment     rune
+	CommentPrefixes   []string
+	BlankLines        BlankPolicy
+	WhitespaceBlank   bool
+	Continuation      rune
+	NULs              NULPolicy
+	FieldsPerRecord   int
+	LazyQuotes        bool
+	TrailingComma     bool
+	TrimLeadingSpace  bool
+	TrimTrailingSpace bool
+	TrimFields        bool
+	SkipLineOnErr     bool
+	ColumnMapping     []ColumnMap
+	NullValues        []string
+	BareEmptyNull     bool
+	Filter            func([]string) bool
+	FilterMap         func(map[string]string) bool
+	Transforms        map[string]TransformFunc
+	IndexTransforms   map[int]TransformFunc
 
 	Error  string
 	Line   int // Expected error line if != 0
@@ -121,6 +143,127 @@ field"`,
 		Input:   "#1,2,3\na,b,c\n#comment",
 		Output:  [][]string{{"a", "b", "c"}},
 	},
+	{
+		Name:          "InlineComment",
+		InlineComment: '#',
+		Input:         "a,b,c  # note\n# whole line\n\"#1\",\"x # y\" # z\nd,e#f,g\n\"h\",#\n\ti,j #",
+		Output:        [][]string{{"a", "b", "c"}, {"#1", "x # y"}, {"d", "e"}, {"h", ""}, {"\ti", "j"}},
+	},
+	{
+		Name:          "InlineCommentAfterQuote",
+		InlineComment: '#',
+		Input:         `"a" ,b`,
+		Error:         `extraneous " in field`, Line: 1, Column: 3,
+	},
+	{
+		Name:          "InlineAndLineComment",
+		Comment:       ';',
+		InlineComment: '#',
+		TrimFields:    true,
+		Input:         "; header comment\na, \"b\" # note\n  # indented\nc,d",
+		Output:        [][]string{{"a", "b"}, {"c", "d"}},
+	},
+	{
+		Name:          "InlineCommentMultiByte",
+		InlineComment: '→',
+		Input:         "a,bc→ note\nd,e",
+		Output:        [][]string{{"a", "bc"}, {"d", "e"}},
+	},
+	{
+		Name:            "CommentPrefixes",
+		Comment:         '#',
+		CommentPrefixes: []string{"//", "--", "", "→→"},
+		Input:           "// a,b\n-- c\n#d\n-e,/f\n→→g\n→h",
+		Output:          [][]string{{"-e", "/f"}, {"→h"}},
+	},
+	{
+		Name:               "EmptyBlankLines",
+		BlankLines:         EmptyBlankLines,
+		UseFieldsPerRecord: true,
+		Input:              "a,b\n\n1,2\r\n\r\n\n",
+		Output:             [][]string{{"a", "b"}, {}, {"1", "2"}, {}, {}},
+	},
+	{
+		Name:       "ErrorBlankLines",
+		BlankLines: ErrorBlankLines,
+		Input:      "a,b\n1,2\n\n3,4\n",
+		Error:      "blank line", Line: 3, Column: 0,
+	},
+	{
+		Name:          "ErrorBlankLinesSkipped",
+		BlankLines:    ErrorBlankLines,
+		SkipLineOnErr: true,
+		Comment:       '#',
+		Input:         "a,b\n#\n\r\n3,4\n\n",
+		Output:        [][]string{{"a", "b"}, {"3", "4"}},
+		Errors:        []string{"line 3, column 0: blank line", "line 5, column 0: blank line"},
+	},
+	{
+		Name:               "WhitespaceBlank",
+		WhitespaceBlank:    true,
+		UseFieldsPerRecord: true,
+		Input:              "a,b\n  \n\t \r\n1,2\n \t",
+		Output:             [][]string{{"a", "b"}, {"1", "2"}},
+	},
+	{
+		Name:               "WhitespaceBlankEmpty",
+		WhitespaceBlank:    true,
+		BlankLines:         EmptyBlankLines,
+		UseFieldsPerRecord: true,
+		Input:              "a,b\n  \n1,2\n\t\t\n",
+		Output:             [][]string{{"a", "b"}, {}, {"1", "2"}, {}},
+	},
+	{
+		Name:            "WhitespaceBlankError",
+		WhitespaceBlank: true,
+		BlankLines:      ErrorBlankLines,
+		Input:           "a,b\n1,2\n\t\n",
+		Error:           "blank line", Line: 3, Column: 0,
+	},
+	{
+		Name:   "WhitespaceLine",
+		Input:  "a\n \n",
+		Output: [][]string{{"a"}, {" "}},
+	},
+	{
+		Name:         "Continuation",
+		Continuation: '\\',
+		Input:        "a,b\\\nc,d\\\r\n,e\nf\\g,\\\n\\\nh\n\"i\\\nj\",k\\",
+		Output:       [][]string{{"a", "bc", "d", "e"}, {"f\\g", "h"}, {"i\\\nj", "k\\"}},
+	},
+	{
+		Name:               "ContinuationFieldCount",
+		Continuation:       '\\',
+		UseFieldsPerRecord: true,
+		Input:              "a,b\n1,\\\n2\n3\n",
+		Output:             [][]string{{"a", "b"}, {"1", "2"}},
+		Error:              "wrong number of fields", Line: 4, Column: 0,
+	},
+	{
+		Name:   "KeepNULs",
+		Input:  "a\x00,b\n",
+		Output: [][]string{{"a\x00", "b"}},
+	},
+	{
+		Name:   "StripNULs",
+		NULs:   StripNULs,
+		Input:  "\x00a\x00\x00b,\"c\x00\n\x00d\"\x00,\x00\n",
+		Output: [][]string{{"ab", "c\nd", ""}},
+	},
+	{
+		Name:  "ErrorNULs",
+		NULs:  ErrorNULs,
+		Input: "a,b\nc,\"d\ne\x00\"\n",
+		Error: "NUL byte in field", Line: 3, Column: 1,
+	},
+	{
+		Name:          "ErrorNULsSkipped",
+		NULs:          ErrorNULs,
+		SkipLineOnErr: true,
+		Input:         "a,b\n\x00,\x00\nc,d\ne,f\x00",
+		Output:        [][]string{{"a", "b"}, {"c", "d"}},
+		Errors:        []string{"line 2, column 0: NUL byte in field", "line 4, column 3: NUL byte in field"},
+	},
 	{
 		Name:   "NoComment",
 		Input:  "#1,2,3\na,b,c",
@@ -332,6 +475,213 @@ x,,,
 			{"a": "4", "b": "5", "c": "6"},
 			{"a": "11", "b": "12", "c": "13"}},
 	},
+	{
+		Name:   "SelectNames",
+		Select: []string{"c", "a"},
+		Input:  "a,b,c\n1,2,3\n4,5,6",
+		Output: [][]string{{"c", "a"}, {"3", "1"}, {"6", "4"}},
+	},
+	{
+		Name:          "SelectIndexes",
+		SelectIndexes: []int{2, 0},
+		Input:         "a,b,c\n1,2,3",
+		Output:        [][]string{{"c", "a"}, {"3", "1"}},
+	},
+	{
+		Name:       "SelectToMaps",
+		UseHeaders: true,
+		Select:     []string{"b"},
+		Input:      "a,b,c\n1,2,3",
+		OutputMap: []map[string]string{
+			{"b": "b"},
+			{"b": "2"}},
+	},
+	{
+		Name:   "SelectUnknownColumn",
+		Select: []string{"d"},
+		Input:  "a,b,c\n1,2,3",
+		Error:  `unknown column "d"`, Line: 1,
+	},
+	{
+		Name:          "SelectIndexOutOfRange",
+		SelectIndexes: []int{3},
+		Input:         "a,b,c\n1,2,3",
+		Error:         "wrong number of fields", Line: 1,
+	},
+	{
+		Name:          "ColumnMapping",
+		ColumnMapping: []ColumnMap{{From: "c", To: "z"}, {From: "a", To: "x"}},
+		Input:         "a,b,c\n1,2,3",
+		Output:        [][]string{{"z", "x"}, {"3", "1"}},
+	},
+	{
+		Name:          "ColumnMappingToMaps",
+		UseHeaders:    true,
+		ColumnMapping: []ColumnMap{{From: "b", To: "y"}, {From: "a", To: "x"}},
+		Input:         "b,c,a\n2,3,1",
+		OutputMap: []map[string]string{
+			{"y": "y", "x": "x"},
+			{"y": "2", "x": "1"}},
+	},
+	{
+		Name:          "ColumnMappingUnknownColumn",
+		ColumnMapping: []ColumnMap{{From: "d", To: "x"}},
+		Input:         "a,b,c\n1,2,3",
+		Error:         `unknown column "d"`, Line: 1,
+	},
+	{
+		Name:   "Filter",
+		Filter: func(record []string) bool { return record[1] != "cancelled" },
+		Input:  "1,open\n2,cancelled\n3,closed",
+		Output: [][]string{{"1", "open"}, {"3", "closed"}},
+	},
+	{
+		Name:       "FilterMap",
+		UseHeaders: true,
+		FilterMap:  func(record map[string]string) bool { return record["status"] != "cancelled" },
+		Input:      "id,status\n1,open\n2,cancelled",
+		OutputMap: []map[string]string{
+			{"id": "id", "status": "status"},
+			{"id": "1", "status": "open"}},
+	},
+	{
+		Name:   "FilterFirstRecord",
+		Filter: func(record []string) bool { return strings.Trim(record[0], "0123456789") == "" },
+		Input:  "x,ann\n1,bob\ny,cat\n2,dan",
+		Output: [][]string{{"1", "bob"}, {"2", "dan"}},
+	},
+	{
+		Name:          "FilterWithErrors",
+		SkipLineOnErr: true,
+		Filter:        func(record []string) bool { return record[0] != "b" },
+		Input:         "a\nb\nc\"\nd",
+		Output:        [][]string{{"a"}, {"d"}},
+		Errors:        []string{"line 3, column 2: bare \" in non-quoted-field"},
+	},
+	{
+		Name:       "Transform",
+		Transforms: map[string]TransformFunc{"name": strings.ToUpper},
+		Input:      "id,name\n1,ann\n2,bob",
+		Output:     [][]string{{"id", "name"}, {"1", "ANN"}, {"2", "BOB"}},
+	},
+	{
+		Name:            "TransformIndex",
+		IndexTransforms: map[int]TransformFunc{1: func(field string) string { return strings.TrimPrefix(field, "$") }},
+		Input:           "a,$1.50\nb,$2",
+		Output:          [][]string{{"a", "1.50"}, {"b", "2"}},
+	},
+	{
+		Name:       "TransformBeforeFilter",
+		Transforms: map[string]TransformFunc{"status": strings.TrimSpace},
+		Filter:     func(record []string) bool { return record[1] != "cancelled" },
+		Input:      "id,status\n1, cancelled \n2,open",
+		Output:     [][]string{{"id", "status"}, {"2", "open"}},
+	},
+	{
+		Name:              "TrimTrailingSpace",
+		TrimTrailingSpace: true,
+		Input:             " a  , b\t,c \nd ,e,f  ",
+		Output:            [][]string{{" a", " b", "c"}, {"d", "e", "f"}},
+	},
+	{
+		Name:       "TrimFields",
+		TrimFields: true,
+		Input:      " a  , b ,c \n",
+		Output:     [][]string{{"a", "b", "c"}},
+	},
+	{
+		Name:       "TrimFieldsQuoted",
+		TrimFields: true,
+		Input:      ` " a " , "b"  ,c`,
+		Output:     [][]string{{" a ", "b", "c"}},
+	},
+	{
+		Name:              "TrimTrailingSpaceAfterQuoteEOL",
+		TrimTrailingSpace: true,
+		Input:             "\"a\"  \n\"b\"",
+		Output:            [][]string{{"a"}, {"b"}},
+	},
+	{
+		Name:              "TrimTrailingSpaceExtraneousQuote",
+		TrimTrailingSpace: true,
+		Input:             `"a" b,c`,
+		Error:             `extraneous " in field`, Line: 1, Column: 3,
+	},
+	{
+		Name:              "TrimTrailingSpaceExtraneousQuoteColumn",
+		TrimTrailingSpace: true,
+		Input:             "\"a\"  \tb,c",
+		Error:             `extraneous " in field`, Line: 1, Column: 5,
+	},
+	{
+		Name:              "TrimTrailingSpaceLazyQuotes",
+		TrimTrailingSpace: true,
+		LazyQuotes:        true,
+		Input:             `"a" b",c`,
+		Output:            [][]string{{`a" b`, "c"}},
+	},
+	{
+		Name:        "NullableMaps",
+		UseNullable: true,
+		NullValues:  []string{"NULL", `\N`},
+		Input:       "a,b,c\n1,,NULL\n\\N,2",
+		OutputNullableMap: []map[string]*string{
+			{"a": stringPtr("a"), "b": stringPtr("b"), "c": stringPtr("c")},
+			{"a": stringPtr("1"), "b": stringPtr(""), "c": nil},
+			{"a": nil, "b": stringPtr("2"), "c": nil}},
+	},
+	{
+		Name:          "BareEmptyNull",
+		UseNullable:   true,
+		BareEmptyNull: true,
+		NullValues:    []string{"NULL"},
+		Input:         "a,b,c\n,\"\",NULL\n\"\",x,\n\"\",,\"\"",
+		OutputNullableMap: []map[string]*string{
+			{"a": stringPtr("a"), "b": stringPtr("b"), "c": stringPtr("c")},
+			{"a": nil, "b": stringPtr(""), "c": nil},
+			{"a": stringPtr(""), "b": stringPtr("x"), "c": nil},
+			{"a": stringPtr(""), "b": nil, "c": stringPtr("")}},
+	},
+	{
+		Name:          "BareEmptyNullSelect",
+		UseNullable:   true,
+		BareEmptyNull: true,
+		Select:        []string{"c", "a"},
+		Input:         "a,b,c\n,x,\"\"",
+		OutputNullableMap: []map[string]*string{
+			{"c": stringPtr("c"), "a": stringPtr("a")},
+			{"c": stringPtr(""), "a": nil}},
+	},
+	{
+		Name:   "InvalidUTF8",
+		Input:  "a\xffb,\"c\xe2\x82d\"\n",
+		Output: [][]string{{"a\ufffdb", "c\ufffd\ufffdd"}},
+	},
+	{
+		Name:   "MultiByteComma",
+		Comma:  '→',
+		Input:  "a←b→c\n",
+		Output: [][]string{{"a←b", "c"}},
+	},
+	{
+		Name:   "LongFields",
+		Input:  strings.Repeat("x", 5000) + ",\"" + strings.Repeat("y\n", 5000) + "\"\n",
+		Output: [][]string{{strings.Repeat("x", 5000), strings.Repeat("y\n", 5000)}},
+	},
+	{
+		Name:  "BareQuoteAfterMultiByte",
+		Input: "é,ñoño\"",
+		Error: `bare " in non-quoted-field`, Line: 1, Column: 6,
+	},
+	{
+		Name:  "ExtraneousQuoteAfterNewline",
+		Input: "\"a\nbé\"c\"",
+		Error: `extraneous " in field`, Line: 2, Column: 2,
+	},
+}
+
+func stringPtr(s string) *string {
+	return &s
 }
 
 func (t *BetterCsvTesting) DeepCompareAllAndPrint(out [][]string, test Test) {
@@ -361,6 +711,12 @@ func TestRead(t *testing.T) {
 	for _, tt := range readTests {
 		r := NewReader(strings.NewReader(tt.Input))
 		r.Comment = tt.Comment
+		r.InlineComment = tt.InlineComment
+		r.CommentPrefixes = tt.CommentPrefixes
+		r.BlankLines = tt.BlankLines
+		r.WhitespaceBlank = tt.WhitespaceBlank
+		r.Continuation = tt.Continuation
+		r.NULs = tt.NULs
 		if tt.UseFieldsPerRecord {
 			r.FieldsPerRecord = tt.FieldsPerRecord
 		} else {
@@ -369,10 +725,29 @@ func TestRead(t *testing.T) {
 		r.LazyQuotes = tt.LazyQuotes
 		r.TrailingComma = tt.TrailingComma
 		r.TrimLeadingSpace = tt.TrimLeadingSpace
+		r.TrimTrailingSpace = tt.TrimTrailingSpace
+		r.TrimFields = tt.TrimFields
 		r.SkipLineOnErr = tt.SkipLineOnErr
+		r.ColumnMapping = tt.ColumnMapping
+		r.NullValues = tt.NullValues
+		r.BareEmptyNull = tt.BareEmptyNull
+		r.Filter = tt.Filter
+		r.FilterMap = tt.FilterMap
+		for name, fn := range tt.Transforms {
+			r.Transform(name, fn)
+		}
+		for index, fn := range tt.IndexTransforms {
+			r.TransformIndex(index, fn)
+		}
 		if tt.Comma != 0 {
 			r.Comma = tt.Comma
 		}
+		if tt.Select != nil {
+			r.Select(tt.Select...)
+		}
+		if tt.SelectIndexes != nil {
+			r.SelectIndexes(tt.SelectIndexes...)
+		}
 		if tt.Name == "GetHeaders" {
 			headers, err := r.Headers()
 			if err != nil {
@@ -391,6 +766,13 @@ func TestRead(t *testing.T) {
 			} else {
 				betterCsvTests.DeepCompareMapAndPrint(out, tt)
 			}
+		} else if tt.UseNullable {
+			out, err := r.ReadAllToNullableMaps()
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.Name, err)
+			} else if !reflect.DeepEqual(out, tt.OutputNullableMap) {
+				t.Errorf("%s: out=%v want %v", tt.Name, out, tt.OutputNullableMap)
+			}
 		} else if tt.UseHeadersAndErrs {
 			out, errs := r.ReadAllToMapsWithErrors()
 			betterCsvTests.DeepCompareMapAndPrint(out, tt)
@@ -412,3 +794,308 @@ func TestRead(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkRead(b *testing.B) {
+	for _, mode := range []struct{ reuse, zeroCopy bool }{{false, false}, {true, false}, {true, true}} {
+		b.Run(fmt.Sprintf("ReuseRecord=%v/ZeroCopy=%v", mode.reuse, mode.zeroCopy), func(b *testing.B) {
+			input := strings.Repeat("xxxxxxxxxx,yyyyyyyyyyyyyyyyyyyy,\"zzzzz\nzzzzz\",12345,67890\n", 1000)
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for b.Loop() {
+				r := NewReader(strings.NewReader(input))
+				r.ReuseRecord = mode.reuse
+				r.ZeroCopy = mode.zeroCopy
+				for {
+					_, err := r.Read()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReadN(t *testing.T) {
+	r := NewReader(strings.NewReader("a\n1\n2\n3\n4\n"))
+	var batches [][][]string
+	for {
+		records, err := r.ReadN(2)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		batches = append(batches, records)
+	}
+	want := [][][]string{{{"a"}, {"1"}}, {{"2"}, {"3"}}, {{"4"}}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("batches=%q want %q", batches, want)
+	}
+}
+
+func TestReuseRecord(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b,c\n1,2,3\n4,5,6\n"))
+	r.ReuseRecord = true
+	r.Select("c", "a")
+	first, _ := r.Read()
+	if !reflect.DeepEqual(first, []string{"c", "a"}) {
+		t.Errorf("first=%q", first)
+	}
+	r.Read()
+	last, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if &first[0] != &last[0] {
+		t.Errorf("records do not share a slice")
+	}
+	if !reflect.DeepEqual(last, []string{"6", "4"}) {
+		t.Errorf("last=%q", last)
+	}
+	if headers, _ := r.Headers(); !reflect.DeepEqual(headers, []string{"a", "b", "c"}) {
+		t.Errorf("headers=%q", headers)
+	}
+
+	r = NewReader(strings.NewReader("a,b\n1,2\n3,4\n"))
+	r.ReuseRecord = true
+	records, err := r.ReadAll()
+	if want := [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}}; err != nil || !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q, err=%v, want %q", records, err, want)
+	}
+}
+
+func TestZeroCopy(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,2\n3,4\n"))
+	r.ZeroCopy = true
+	r.Select("b")
+	if header, _ := r.Read(); header[0] != "b" {
+		t.Errorf("header=%q", header)
+	}
+	first, _ := r.Read()
+	kept := strings.Clone(first[0])
+	second, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if kept != "2" || second[0] != "4" {
+		t.Errorf("first=%q, second=%q", kept, second)
+	}
+	if first[0] != "4" {
+		t.Errorf("first=%q, want it overwritten by the second record", first)
+	}
+	if headers, _ := r.Headers(); !reflect.DeepEqual(headers, []string{"a", "b"}) {
+		t.Errorf("headers=%q", headers)
+	}
+}
+
+func TestReadColumns(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b,c\n1,2,3\n4,5\n6,7,8\n"))
+	r.FieldsPerRecord = -1
+	columns, err := r.ReadColumns()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := map[string][]string{"a": {"1", "4", "6"}, "b": {"2", "5", "7"}, "c": {"3", "", "8"}}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns=%q want %q", columns, want)
+	}
+
+	r = NewReader(strings.NewReader("a,b,c\n1,2,3\n4,5,6\n"))
+	r.Select("c", "a")
+	columns, err = r.ReadColumns()
+	if want := map[string][]string{"c": {"3", "6"}, "a": {"1", "4"}}; err != nil || !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns=%q, err=%v, want %q", columns, err, want)
+	}
+
+	columns, err = NewReader(strings.NewReader("a,b\n")).ReadColumns()
+	if want := map[string][]string{"a": nil, "b": nil}; err != nil || !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns=%q, err=%v, want %q", columns, err, want)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	r := NewReader(strings.NewReader("a\n1\n2\n3\n"))
+	r.Limit = 2
+	records, err := r.ReadAllToMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []map[string]string{{"a": "a"}, {"a": "1"}, {"a": "2"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+}
+
+func TestMaxRows(t *testing.T) {
+	r := NewReader(strings.NewReader("a\n1\n2\n"))
+	r.MaxRows = 2
+	records, err := r.ReadAllToMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("records=%q", records)
+	}
+
+	r = NewReader(strings.NewReader("a\n1\n2\n3\n4\n"))
+	r.MaxRows = 2
+	r.SkipLineOnErr = true
+	records, err = r.ReadAllToMaps()
+	if err != ErrMaxRows {
+		t.Errorf("error %v, want ErrMaxRows", err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("error %v after ErrMaxRows, want io.EOF", err)
+	}
+
+	r = NewReader(strings.NewReader("a\n1\n2\n3\n4\n"))
+	r.MaxRows = 2
+	r.Filter = func(record []string) bool { return record[0] != "1" }
+	rows, errs := r.ReadAllWithErrors()
+	want := [][]string{{"a"}, {"2"}}
+	if !reflect.DeepEqual(rows, want) || len(errs) != 1 || errs[0] != ErrMaxRows {
+		t.Errorf("records=%q, errors=%v, want %q, [ErrMaxRows]", rows, errs, want)
+	}
+
+	// SkipLineOnErr does not skip ErrMaxRows in the methods reading the
+	// whole input either.
+	readAll := map[string]func(r *Reader) error{
+		"ReadAllToRecords": func(r *Reader) error { _, err := r.ReadAllToRecords(); return err },
+		"CollectStats":     func(r *Reader) error { _, err := r.CollectStats(); return err },
+		"ContentHash":      func(r *Reader) error { _, err := r.ContentHash(); return err },
+	}
+	for name, read := range readAll {
+		r = NewReader(strings.NewReader("a\n1\n2\n3\n4\n"))
+		r.MaxRows = 2
+		r.SkipLineOnErr = true
+		if err := read(r); err != ErrMaxRows {
+			t.Errorf("%s: error %v, want ErrMaxRows", name, err)
+		}
+	}
+}
+
+func TestExtraFields(t *testing.T) {
+	const input = "a,b\n1,2,3,4\n5\n"
+	tests := []struct {
+		Name   string
+		Policy ExtraPolicy
+		Key    string
+		Maps   []map[string]string
+		Error  error
+	}{{
+		Name:   "Drop",
+		Policy: DropExtraFields,
+		Maps:   []map[string]string{{"a": "a", "b": "b"}, {"a": "1", "b": "2"}, {"a": "5"}},
+	}, {
+		Name:   "Error",
+		Policy: ErrorExtraFields,
+		Error:  &ParseError{Line: 2, Err: ErrExtraFields},
+	}, {
+		Name:   "Join",
+		Policy: JoinExtraFields,
+		Maps:   []map[string]string{{"a": "a", "b": "b"}, {"a": "1", "b": "2", "_extra": "3,4"}, {"a": "5"}},
+	}, {
+		Name:   "JoinKey",
+		Policy: JoinExtraFields,
+		Key:    "rest",
+		Maps:   []map[string]string{{"a": "a", "b": "b"}, {"a": "1", "b": "2", "rest": "3,4"}, {"a": "5"}},
+	}}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(input))
+		r.FieldsPerRecord = -1
+		r.ExtraFields = tt.Policy
+		r.ExtraKey = tt.Key
+		maps, err := r.ReadAllToMaps()
+		if !reflect.DeepEqual(err, tt.Error) {
+			t.Errorf("%s: error %v, want %v", tt.Name, err, tt.Error)
+		}
+		if !reflect.DeepEqual(maps, tt.Maps) {
+			t.Errorf("%s: maps=%q, want %q", tt.Name, maps, tt.Maps)
+		}
+	}
+
+	r := NewReader(strings.NewReader(input))
+	r.FieldsPerRecord = -1
+	r.ExtraFields = JoinExtraFields
+	maps, err := r.ReadAllToNullableMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if extra := maps[1]["_extra"]; extra == nil || *extra != "3,4" || maps[2]["b"] != nil {
+		t.Errorf("nullable maps=%v", maps)
+	}
+}
+
+func TestEachMap(t *testing.T) {
+	const input = "a,b\n1,2\n3\n4,5\n6,7\n"
+	var maps []map[string]string
+	var errs []*ParseError
+	r := NewReader(strings.NewReader(input))
+	r.SkipLineOnErr = true
+	err := r.EachMap(func(recordMap map[string]string, err *ParseError) bool {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			maps = append(maps, recordMap)
+		}
+		return len(maps) < 3
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []map[string]string{{"a": "a", "b": "b"}, {"a": "1", "b": "2"}, {"a": "4", "b": "5"}}
+	if !reflect.DeepEqual(maps, want) {
+		t.Errorf("maps=%q, want %q", maps, want)
+	}
+	wantErrs := []*ParseError{{Line: 3, Err: ErrFieldCount}}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("errors=%v, want %v", errs, wantErrs)
+	}
+
+	r = NewReader(strings.NewReader(input))
+	calls := 0
+	err = r.EachMap(func(map[string]string, *ParseError) bool {
+		calls++
+		return true
+	})
+	if !reflect.DeepEqual(err, &ParseError{Line: 3, Err: ErrFieldCount}) || calls != 2 {
+		t.Errorf("error %v after %d calls, want line 3 field count error after 2", err, calls)
+	}
+}
+
+// newTestLogger returns a Logger writing debug messages to b without times.
+func newTestLogger(b *strings.Builder) *slog.Logger {
+	return slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func TestLogger(t *testing.T) {
+	var b strings.Builder
+	r := NewReader(strings.NewReader("a,b\n1,x\"y\n2\n3,4\n5,6\n"))
+	r.Logger = newTestLogger(&b)
+	r.LazyQuotes = true
+	r.SkipLineOnErr = true
+	r.Filter = func(record []string) bool { return record[0] != "3" }
+	r.ReadAllToMaps()
+	want := `level=DEBUG msg="read header row" line=1 headers="[a b]"
+level=DEBUG msg="set field count from first record" line=1 fields=2
+level=DEBUG msg="accepted bare quote" line=2 column=3
+level=DEBUG msg="skipped record" line=3 error="line 3, column 0: wrong number of fields in line"
+level=DEBUG msg="filtered record" line=4
+`
+	if b.String() != want {
+		t.Errorf("log=\n%s\nwant\n%s", b.String(), want)
+	}
+}
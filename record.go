@@ -0,0 +1,288 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// A FieldError is returned when a field of a Record cannot be converted.
+// The first line is 1.  The first column is 0.
+type FieldError struct {
+	Line   int    // Line where the record started
+	Column int    // Index of the column, or -1 if there is no such column
+	Name   string // Header of the column
+	Err    error  // The actual error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("line %d, column %d (%s): %s", e.Line, e.Column, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// A Record is a record read with ReadRecord.  Its fields can be looked up by
+// header and converted with the typed accessors, which return a *FieldError
+// carrying the position of the field on failure.
+type Record struct {
+	Fields []string // the fields of the record
+	Line   int      // line where the record started
+	Quoted []bool   // whether each field was quoted in the input
+
+	headers []string
+	index   map[string]int
+	layouts map[string][]string
+}
+
+// Headers returns the names of the fields of the record.
+func (rec *Record) Headers() []string {
+	return rec.headers
+}
+
+// Extra returns the fields of the record past its headers, which a Reader
+// reads if its FieldsPerRecord is negative.
+func (rec *Record) Extra() []string {
+	if len(rec.Fields) <= len(rec.headers) {
+		return nil
+	}
+	return rec.Fields[len(rec.headers):]
+}
+
+// Get returns the field of the named column, or "" if there is no such
+// column.
+func (rec *Record) Get(name string) string {
+	field, _ := rec.lookup(name)
+	return field
+}
+
+// GetInt returns the field of the named column as an int.
+func (rec *Record) GetInt(name string) (int, error) {
+	field, err := rec.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, rec.error(name, err)
+	}
+	return i, nil
+}
+
+// GetFloat returns the field of the named column as a float64.
+func (rec *Record) GetFloat(name string) (float64, error) {
+	field, err := rec.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		return 0, rec.error(name, err)
+	}
+	return f, nil
+}
+
+// GetBool returns the field of the named column as a bool.  It accepts the
+// values accepted by strconv.ParseBool.
+func (rec *Record) GetBool(name string) (bool, error) {
+	field, err := rec.lookup(name)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(field)
+	if err != nil {
+		return false, rec.error(name, err)
+	}
+	return b, nil
+}
+
+// GetDecimal returns the field of the named column as an exact decimal, so
+// that values such as monetary amounts are never rounded through float64.
+func (rec *Record) GetDecimal(name string) (*big.Rat, error) {
+	d := new(big.Rat)
+	if err := rec.GetDecimalInto(name, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetDecimalInto parses the field of the named column into d.  Any decimal
+// type implementing encoding.TextUnmarshaler can be used, such as *big.Rat,
+// *big.Float or a third-party decimal type.
+func (rec *Record) GetDecimalInto(name string, d encoding.TextUnmarshaler) error {
+	field, err := rec.lookup(name)
+	if err != nil {
+		return err
+	}
+	if err := d.UnmarshalText([]byte(field)); err != nil {
+		return rec.error(name, err)
+	}
+	return nil
+}
+
+// GetTime returns the field of the named column as a time.Time.  The layouts
+// are tried in order until one succeeds.  If no layouts are given, the layouts
+// set for the column with Reader.TimeLayout are used, or time.RFC3339 if there
+// are none.
+func (rec *Record) GetTime(name string, layouts ...string) (time.Time, error) {
+	field, err := rec.lookup(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(layouts) == 0 {
+		layouts = rec.layouts[name]
+	}
+	t, err := parseTime(field, layouts)
+	if err != nil {
+		return time.Time{}, rec.error(name, err)
+	}
+	return t, nil
+}
+
+// parseTime parses value with the first of layouts that succeeds, or with
+// time.RFC3339 if layouts is empty.  It returns the error of the last layout
+// tried.
+func parseTime(value string, layouts []string) (t time.Time, err error) {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	for _, layout := range layouts {
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// lookup returns the field of the named column.
+func (rec *Record) lookup(name string) (string, error) {
+	column, ok := rec.index[name]
+	if !ok || column >= len(rec.Fields) {
+		return "", rec.error(name, ErrUnknownColumn)
+	}
+	return rec.Fields[column], nil
+}
+
+// error creates a new FieldError for the named column based on err.
+func (rec *Record) error(name string, err error) error {
+	column, ok := rec.index[name]
+	if !ok {
+		column = -1
+	}
+	return &FieldError{
+		Line:   rec.Line,
+		Column: column,
+		Name:   name,
+		Err:    err,
+	}
+}
+
+// TimeLayout sets the layouts used to parse times in the named column.  The
+// layouts are tried in order until one succeeds.
+func (r *Reader) TimeLayout(name string, layouts ...string) {
+	if r.timeLayouts == nil {
+		r.timeLayouts = make(map[string][]string)
+	}
+	r.timeLayouts[name] = layouts
+}
+
+// ReadRecord reads one record from r as a Record.  The first record is read
+// as the headers and is not returned.
+func (r *Reader) ReadRecord() (record *Record, err error) {
+	for {
+		fields, isHeader, err := r.read(true)
+		if err != nil {
+			return nil, err
+		}
+		if isHeader {
+			continue
+		}
+		if r.FilterMap != nil {
+			// Records keep their extra fields, returned by Extra, whatever
+			// the ExtraFields of r.
+			recordMap, _ := r.recordToMap(fields)
+			if !r.keepMap(recordMap) {
+				continue
+			}
+		}
+		return r.newRecord(fields), nil
+	}
+}
+
+// ReadAllToRecords reads all the remaining records from r using ReadRecord.
+// A successful call returns err == nil, not err == EOF. Because
+// ReadAllToRecords is defined to read until EOF, it does not treat end of file
+// as an error to be reported.
+func (r *Reader) ReadAllToRecords() (records []*Record, err error) {
+	for {
+		record, err := r.ReadRecord()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// WriteRecord writes the fields of rec like Write, quoting them as they were
+// quoted in the input: the fields whose Quoted is true are quoted, and the
+// others only if they must be, whatever the QuoteMode, so that files can be
+// edited without changing the quoting style their consumers depend on.
+// The columns are named by Headers, or by the headers of rec if Headers is
+// nil.
+func (w *Writer) WriteRecord(rec *Record) error {
+	headers := w.Headers
+	if headers == nil {
+		headers = rec.headers
+	}
+	w.quotes = rec.Quoted
+	defer func() { w.quotes = nil }()
+	return w.writeRecord(rec.Fields, headers, nil)
+}
+
+// fieldsQuoted returns whether each of the n fields of the last record read,
+// after the column selection, was quoted in the input.
+func (r *Reader) fieldsQuoted(n int) []bool {
+	quoted := make([]bool, n)
+	for i := range quoted {
+		index := i
+		if r.columns != nil {
+			index = r.columns[i]
+		}
+		quoted[i] = index < len(r.quotes) && r.quotes[index]
+	}
+	return quoted
+}
+
+// newRecord creates a Record holding fields.
+func (r *Reader) newRecord(fields []string) *Record {
+	if r.index == nil {
+		headers := r.outputHeaders()
+		r.index = make(map[string]int, len(headers))
+		for i, header := range headers {
+			r.index[header] = i
+		}
+	}
+	return &Record{
+		Fields:  fields,
+		Line:    r.recordLine,
+		Quoted:  r.fieldsQuoted(len(fields)),
+		headers: r.outputHeaders(),
+		index:   r.index,
+		layouts: r.timeLayouts,
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadRecord(t *testing.T) {
+	r := NewReader(strings.NewReader("id,price,active,date\n1,2.50,true,2014-06-01\nx,1e,maybe,06/01/2014"))
+	records, err := r.ReadAllToRecords()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	rec := records[0]
+	if rec.Get("id") != "1" || rec.Get("missing") != "" {
+		t.Errorf("Get: id=%q missing=%q", rec.Get("id"), rec.Get("missing"))
+	}
+	if i, err := rec.GetInt("id"); err != nil || i != 1 {
+		t.Errorf("GetInt: %d, %v", i, err)
+	}
+	if f, err := rec.GetFloat("price"); err != nil || f != 2.5 {
+		t.Errorf("GetFloat: %v, %v", f, err)
+	}
+	if b, err := rec.GetBool("active"); err != nil || !b {
+		t.Errorf("GetBool: %v, %v", b, err)
+	}
+	want := time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC)
+	if d, err := rec.GetTime("date", "2006-01-02"); err != nil || !d.Equal(want) {
+		t.Errorf("GetTime: %v, %v", d, err)
+	}
+
+	rec = records[1]
+	errorTests := []struct {
+		Name  string
+		Get   func() error
+		Error string
+	}{
+		{"id", func() error { _, err := rec.GetInt("id"); return err }, `line 3, column 0 (id): strconv.Atoi: parsing "x": invalid syntax`},
+		{"price", func() error { _, err := rec.GetFloat("price"); return err }, `line 3, column 1 (price): strconv.ParseFloat: parsing "1e": invalid syntax`},
+		{"active", func() error { _, err := rec.GetBool("active"); return err }, `line 3, column 2 (active): strconv.ParseBool: parsing "maybe": invalid syntax`},
+		{"missing", func() error { _, err := rec.GetInt("missing"); return err }, `line 3, column -1 (missing): unknown column`},
+	}
+	for _, tt := range errorTests {
+		err := tt.Get()
+		if err == nil || err.Error() != tt.Error {
+			t.Errorf("%s: error %v, want %q", tt.Name, err, tt.Error)
+		}
+	}
+	if _, err := rec.GetTime("date", "2006-01-02"); err == nil {
+		t.Errorf("date: expected error")
+	} else if ferr, ok := err.(*FieldError); !ok || ferr.Line != 3 || ferr.Column != 3 {
+		t.Errorf("date: error %v, want FieldError at 3:3", err)
+	}
+	if _, err := rec.GetInt("missing"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("missing: error %v, want ErrUnknownColumn", err)
+	}
+}
+
+var writeRecordTests = []struct {
+	Name      string
+	Input     string
+	Select    []string
+	QuoteMode QuoteMode
+	Edit      func(rec *Record)
+	Quoted    []bool
+	Output    string
+}{
+	{
+		Name:   "Unchanged",
+		Input:  "id,name,note\n\"1\",ann,\n2,\"bob\",\"\"\n",
+		Quoted: []bool{true, false, false},
+		Output: "\"1\",ann,\n2,\"bob\",\"\"\n",
+	},
+	{
+		Name:      "QuoteMode",
+		Input:     "id,name\n1,\"ann\"\n",
+		QuoteMode: QuoteAlways,
+		Quoted:    []bool{false, true},
+		Output:    "1,\"ann\"\n",
+	},
+	{
+		Name:   "EditNeedsQuotes",
+		Input:  "id,name\n1,ann\n",
+		Edit:   func(rec *Record) { rec.Fields[1] = "ann, jr" },
+		Quoted: []bool{false, false},
+		Output: "1,\"ann, jr\"\n",
+	},
+	{
+		Name:   "Select",
+		Input:  "id,name,note\n1,\"ann\",x\n",
+		Select: []string{"name", "id"},
+		Quoted: []bool{true, false},
+		Output: "\"ann\",1\n",
+	},
+}
+
+func TestRecordExtra(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,2,3,4\n5,6\n"))
+	r.FieldsPerRecord = -1
+	r.ExtraFields = ErrorExtraFields
+	records, err := r.ReadAllToRecords()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if extra := records[0].Extra(); !reflect.DeepEqual(extra, []string{"3", "4"}) {
+		t.Errorf("Extra()=%q, want [3 4]", extra)
+	}
+	if extra := records[1].Extra(); extra != nil {
+		t.Errorf("Extra()=%q, want nil", extra)
+	}
+}
+
+func TestWriteRecord(t *testing.T) {
+	for _, tt := range writeRecordTests {
+		r := NewReader(strings.NewReader(tt.Input))
+		r.Select(tt.Select...)
+		b := &bytes.Buffer{}
+		w := NewWriter(b)
+		w.QuoteMode = tt.QuoteMode
+		records, err := r.ReadAllToRecords()
+		if err != nil {
+			t.Errorf("%s: %v", tt.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(records[0].Quoted, tt.Quoted) {
+			t.Errorf("%s: quoted=%v want %v", tt.Name, records[0].Quoted, tt.Quoted)
+		}
+		for _, rec := range records {
+			if tt.Edit != nil {
+				tt.Edit(rec)
+			}
+			if err := w.WriteRecord(rec); err != nil {
+				t.Errorf("%s: %v", tt.Name, err)
+			}
+		}
+		w.Flush()
+		if b.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, b.String(), tt.Output)
+		}
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	r := NewReader(strings.NewReader("start,end\n06/01/2014,2014-06-02T10:00:00Z\n2014-06-03,2014-06-04T10:00:00Z"))
+	r.TimeLayout("start", "01/02/2006", "2006-01-02")
+	records, err := r.ReadAllToRecords()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	starts := []time.Time{
+		time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2014, 6, 3, 0, 0, 0, 0, time.UTC),
+	}
+	for i, rec := range records {
+		if start, err := rec.GetTime("start"); err != nil || !start.Equal(starts[i]) {
+			t.Errorf("#%d: start=%v, %v want %v", i, start, err, starts[i])
+		}
+		if _, err := rec.GetTime("end"); err != nil {
+			t.Errorf("#%d: end: unexpected error %v", i, err)
+		}
+	}
+	if _, err := records[0].GetTime("end", "01/02/2006"); err == nil {
+		t.Errorf("end: expected error with explicit layout")
+	}
+}
+
+func TestGetDecimal(t *testing.T) {
+	r := NewReader(strings.NewReader("amount\n19.99\n$5"))
+	records, err := r.ReadAllToRecords()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	d, err := records[0].GetDecimal("amount")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := big.NewRat(1999, 100); d.Cmp(want) != 0 {
+		t.Errorf("amount=%v want %v", d, want)
+	}
+	f := new(big.Float)
+	if err := records[0].GetDecimalInto("amount", f); err != nil || f.Text('f', 2) != "19.99" {
+		t.Errorf("GetDecimalInto: %v, %v", f, err)
+	}
+	if _, err := records[1].GetDecimal("amount"); err == nil {
+		t.Errorf("expected error for %q", records[1].Get("amount"))
+	} else if _, ok := err.(*FieldError); !ok {
+		t.Errorf("error %T, want *FieldError", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// These are the block size and number of cached blocks of a RemoteFile
+// whose BlockSize and CacheBlocks are 0.
+const (
+	DefaultBlockSize   = 1 << 20
+	DefaultCacheBlocks = 16
+)
+
+// ErrRangeNotSupported is returned when reading a RemoteFile of a server
+// that ignores HTTP Range requests.
+var ErrRangeNotSupported = errors.New("range requests not supported")
+
+// A RangeFunc returns the length bytes at offset of a remote object, such as
+// the body of an HTTP Range request or of an object storage GET with a
+// range.
+type RangeFunc func(offset, length int64) (io.ReadCloser, error)
+
+// A RemoteFile is an io.ReaderAt of a remote object, fetched in blocks of
+// BlockSize bytes of which the CacheBlocks most recently used are cached,
+// so that parsing does not make a request per buffered read.  It can be
+// read by a seekable Reader, for SeekTo, BuildIndex and ReadAt, or passed to
+// the ReadAll method of a ParallelReader, without downloading the whole
+// object first.  Its methods can be called from several goroutines.
+type RemoteFile struct {
+	BlockSize   int64 // bytes fetched per request; DefaultBlockSize if 0
+	CacheBlocks int   // blocks cached; DefaultCacheBlocks if 0
+
+	fetch  RangeFunc
+	size   int64
+	mu     sync.Mutex
+	blocks map[int64]*remoteBlock
+	recent []int64 // cached blocks, least recently used first
+}
+
+// A remoteBlock is a block of a RemoteFile, ready once done is closed.
+type remoteBlock struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// NewRemoteReader returns a Reader of the size bytes of the remote object
+// read by fetch, with the RemoteFile it reads from.  The Reader is
+// seekable, so SeekTo, BuildIndex and ReadAt can be used.
+func NewRemoteReader(fetch RangeFunc, size int64) (*Reader, *RemoteFile) {
+	file := &RemoteFile{fetch: fetch, size: size}
+	return NewReader(io.NewSectionReader(file, 0, size)), file
+}
+
+// NewURLReader returns a Reader of the file at url, read with HTTP Range
+// requests sent by client, or http.DefaultClient if it is nil, with the
+// RemoteFile it reads from.  The size of the file is requested first with a
+// HEAD request.
+func NewURLReader(client *http.Client, url string) (*Reader, *RemoteFile, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Ranges are of the uncompressed file.
+	req.Header.Set("Accept-Encoding", "identity")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%w %s", ErrHTTPStatus, resp.Status)
+	}
+	if resp.ContentLength < 0 || resp.Header.Get("Accept-Ranges") == "none" {
+		return nil, nil, ErrRangeNotSupported
+	}
+	r, file := NewRemoteReader(func(offset, length int64) (io.ReadCloser, error) {
+		return fetchRange(client, url, offset, length)
+	}, resp.ContentLength)
+	return r, file, nil
+}
+
+// fetchRange returns the length bytes at offset of the file at url.
+func fetchRange(client *http.Client, url string, offset, length int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		return resp.Body, nil
+	case resp.StatusCode == http.StatusOK && offset == 0:
+		// The whole file, of which the block is the start.
+		return resp.Body, nil
+	case resp.StatusCode == http.StatusOK:
+		resp.Body.Close()
+		return nil, ErrRangeNotSupported
+	}
+	resp.Body.Close()
+	return nil, fmt.Errorf("%w %s", ErrHTTPStatus, resp.Status)
+}
+
+// Size returns the size of f in bytes.
+func (f *RemoteFile) Size() int64 {
+	return f.size
+}
+
+// ReadAt reads len(p) bytes of f at off, from the cached blocks or else
+// fetching them.
+func (f *RemoteFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	blockSize := f.BlockSize
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+	n := 0
+	for n < len(p) && off < f.size {
+		data, err := f.block(off/blockSize, blockSize)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], data[off%blockSize:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// block returns the data of the block i of blockSize bytes, fetching it if
+// it is not cached, or waiting for it if another goroutine is fetching it.
+func (f *RemoteFile) block(i, blockSize int64) ([]byte, error) {
+	f.mu.Lock()
+	if b := f.blocks[i]; b != nil {
+		j := slices.Index(f.recent, i)
+		f.recent = append(slices.Delete(f.recent, j, j+1), i)
+		f.mu.Unlock()
+		<-b.done
+		return b.data, b.err
+	}
+	b := &remoteBlock{done: make(chan struct{})}
+	if f.blocks == nil {
+		f.blocks = make(map[int64]*remoteBlock)
+	}
+	f.blocks[i] = b
+	f.recent = append(f.recent, i)
+	cacheBlocks := f.CacheBlocks
+	if cacheBlocks <= 0 {
+		cacheBlocks = DefaultCacheBlocks
+	}
+	for len(f.recent) > cacheBlocks {
+		f.forget(f.recent[0])
+	}
+	f.mu.Unlock()
+
+	b.data, b.err = f.load(i*blockSize, min(blockSize, f.size-i*blockSize))
+	close(b.done)
+	if b.err != nil {
+		// Failed fetches are retried by the next read.
+		f.mu.Lock()
+		if f.blocks[i] == b {
+			f.forget(i)
+		}
+		f.mu.Unlock()
+	}
+	return b.data, b.err
+}
+
+// forget removes the block i from the cache.
+func (f *RemoteFile) forget(i int64) {
+	delete(f.blocks, i)
+	if j := slices.Index(f.recent, i); j >= 0 {
+		f.recent = slices.Delete(f.recent, j, j+1)
+	}
+}
+
+// load fetches the length bytes of f at offset.
+func (f *RemoteFile) load(offset, length int64) ([]byte, error) {
+	body, err := f.fetch(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data := make([]byte, length)
+	if _, err := io.ReadFull(body, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// remoteInput returns the input of the RemoteFile tests, of n records.
+func remoteInput(n int) string {
+	var b strings.Builder
+	b.WriteString("id,note\n")
+	for i := range n {
+		fmt.Fprintf(&b, "%d,\"line\n%d\"\n", i, i)
+	}
+	return b.String()
+}
+
+func TestRemoteFile(t *testing.T) {
+	input := remoteInput(100)
+	var fetches int
+	fetch := func(offset, length int64) (io.ReadCloser, error) {
+		fetches++
+		return io.NopCloser(strings.NewReader(input[offset : offset+length])), nil
+	}
+
+	r, file := NewRemoteReader(fetch, int64(len(input)))
+	file.BlockSize = 64
+	file.CacheBlocks = 2
+	want, _ := NewReader(strings.NewReader(input)).ReadAll()
+	out, err := r.ReadAll()
+	if err != nil || !reflect.DeepEqual(out, want) {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if blocks := (len(input) + 63) / 64; fetches != blocks {
+		t.Errorf("fetches=%d want %d", fetches, blocks)
+	}
+
+	p := make([]byte, 100)
+	if n, err := file.ReadAt(p, int64(len(input))-50); n != 50 || err != io.EOF || string(p[:n]) != input[len(input)-50:] {
+		t.Errorf("ReadAt at the end: n=%d err=%v", n, err)
+	}
+	fetches = 0
+	file.ReadAt(p[:10], int64(len(input))-20)
+	if fetches != 0 {
+		t.Errorf("cached block fetched again")
+	}
+
+	if _, err := r.BuildIndex(10); err != nil {
+		t.Fatalf("BuildIndex: unexpected error %v", err)
+	}
+	record, err := r.ReadAt(42)
+	if err != nil || !reflect.DeepEqual(record, []string{"42", "line\n42"}) {
+		t.Errorf("ReadAt(42)=%q err=%v", record, err)
+	}
+
+	p2 := &ParallelReader{ChunkSize: 200}
+	out, err = p2.ReadAll(file, file.Size())
+	if err != nil || !reflect.DeepEqual(out, want) {
+		t.Errorf("ParallelReader: out=%q err=%v", out, err)
+	}
+}
+
+func TestURLReader(t *testing.T) {
+	input := remoteInput(50)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		switch req.URL.Path {
+		case "/data.csv":
+			http.ServeContent(w, req, "data.csv", time.Time{}, strings.NewReader(input))
+		case "/norange.csv":
+			w.Header().Set("Content-Length", fmt.Sprint(len(input)))
+			io.WriteString(w, input)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	r, file, err := NewURLReader(server.Client(), server.URL+"/data.csv")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	file.BlockSize = 128
+	if file.Size() != int64(len(input)) {
+		t.Errorf("size=%d want %d", file.Size(), len(input))
+	}
+	if err := r.SeekTo(int64(len(input)) - 13); err != nil {
+		t.Fatalf("SeekTo: unexpected error %v", err)
+	}
+	record, err := r.Read()
+	if err != nil || !reflect.DeepEqual(record, []string{"49", "line\n49"}) {
+		t.Errorf("record=%q err=%v", record, err)
+	}
+	if n, blocks := requests.Load(), int32(len(input)+127)/128+1; n > blocks {
+		t.Errorf("requests=%d, want at most %d", n, blocks)
+	}
+
+	r, file, err = NewURLReader(server.Client(), server.URL+"/norange.csv")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	file.BlockSize = 128
+	if _, err := r.ReadAll(); !errors.Is(err, ErrRangeNotSupported) {
+		t.Errorf("without ranges: error %v, want %v", err, ErrRangeNotSupported)
+	}
+
+	if _, _, err := NewURLReader(server.Client(), server.URL+"/missing.csv"); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// A ValidationReport collects the violations found while reading a file.
+type ValidationReport struct {
+	Violations []*Violation
+}
+
+// Add adds violations to the report.
+func (rep *ValidationReport) Add(violations ...*Violation) {
+	rep.Violations = append(rep.Violations, violations...)
+}
+
+// AddError adds err to the report.  A *Violation is added as is; any other
+// error, such as a *ParseError, is added as a violation of RuleParse.
+func (rep *ValidationReport) AddError(err error) {
+	switch err := err.(type) {
+	case *Violation:
+		rep.Add(err)
+	case *ParseError:
+		rep.Add(&Violation{Line: err.Line, Rule: RuleParse, Err: err})
+	default:
+		rep.Add(&Violation{Rule: RuleParse, Err: err})
+	}
+}
+
+// Valid reports whether the report has no violations.
+func (rep *ValidationReport) Valid() bool {
+	return len(rep.Violations) == 0
+}
+
+// Column returns the violations of the named column.
+func (rep *ValidationReport) Column(name string) (violations []*Violation) {
+	for _, v := range rep.Violations {
+		if v.Column == name {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// CountByColumn returns the number of violations of each column.  Violations
+// that are not about a column are counted under "".
+func (rep *ValidationReport) CountByColumn() map[string]int {
+	counts := make(map[string]int)
+	for _, v := range rep.Violations {
+		counts[v.Column]++
+	}
+	return counts
+}
+
+// CountByRule returns the number of violations of each rule.
+func (rep *ValidationReport) CountByRule() map[string]int {
+	counts := make(map[string]int)
+	for _, v := range rep.Violations {
+		counts[v.Rule]++
+	}
+	return counts
+}
+
+// Summary returns one line per column, sorted by column name, with its number
+// of violations of each rule, such as
+//
+//	email: 3 (regexp: 2, required: 1)
+func (rep *ValidationReport) Summary() string {
+	rules := make(map[string]map[string]int)
+	for _, v := range rep.Violations {
+		if rules[v.Column] == nil {
+			rules[v.Column] = make(map[string]int)
+		}
+		rules[v.Column][v.Rule]++
+	}
+	var b bytes.Buffer
+	for _, column := range sortedKeys(rules) {
+		total := 0
+		var counts []string
+		for _, rule := range sortedKeys(rules[column]) {
+			total += rules[column][rule]
+			counts = append(counts, fmt.Sprintf("%s: %d", rule, rules[column][rule]))
+		}
+		if column == "" {
+			column = "(record)"
+		}
+		fmt.Fprintf(&b, "%s: %d (", column, total)
+		for i, count := range counts {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(count)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ReadAllWithReport reads all the remaining records from r like
+// ReadAllWithErrors, collecting the errors in a ValidationReport.
+func (r *Reader) ReadAllWithReport() (records [][]string, report *ValidationReport) {
+	report = new(ValidationReport)
+	records, errs := r.ReadAllWithErrors()
+	for _, err := range errs {
+		report.AddError(err)
+	}
+	return records, report
+}
+
+// ReadAllToMapsWithReport reads all the remaining records from r like
+// ReadAllToMapsWithErrors, collecting the errors in a ValidationReport.
+func (r *Reader) ReadAllToMapsWithReport() (records []map[string]string, report *ValidationReport) {
+	report = new(ValidationReport)
+	records, errs := r.ReadAllToMapsWithErrors()
+	for _, err := range errs {
+		report.AddError(err)
+	}
+	return records, report
+}
@@ -0,0 +1,39 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidationReport(t *testing.T) {
+	r := NewReader(strings.NewReader("id,email\n1,a@b.c\n1,nope\n2,\n3,\"x\"y\n4,d@e.f"))
+	r.SkipLineOnErr = true
+	r.Validate("id", Unique())
+	r.Validate("email", Required(), Regexp(regexp.MustCompile(`@`)))
+	records, report := r.ReadAllWithReport()
+	if len(records) != 3 {
+		t.Errorf("records=%q", records)
+	}
+	if report.Valid() {
+		t.Fatalf("report has no violations")
+	}
+	if counts, want := report.CountByColumn(), map[string]int{"id": 1, "email": 1, "": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountByColumn=%v want %v", counts, want)
+	}
+	if counts, want := report.CountByRule(), map[string]int{RuleUnique: 1, RuleRequired: 1, RuleParse: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountByRule=%v want %v", counts, want)
+	}
+	if v := report.Column("email"); len(v) != 1 || v[0].Line != 4 || v[0].Value != "" {
+		t.Errorf("Column(email)=%v", v)
+	}
+	want := "(record): 1 (parse: 1)\nemail: 1 (required: 1)\nid: 1 (unique: 1)\n"
+	if summary := report.Summary(); summary != want {
+		t.Errorf("Summary=%q want %q", summary, want)
+	}
+}
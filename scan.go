@@ -0,0 +1,95 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import "unicode/utf8"
+
+// A recordScanner follows the quoting and escaping of the records of a
+// Reader byte by byte, to find where records end without parsing their
+// fields.  Comment lines must be skipped by the caller.
+type recordScanner struct {
+	comma       []byte
+	quotes      bool // fields may be quoted
+	escapes     bool // fields are escaped with backslashes
+	lazyQuotes  bool
+	trimLeading bool
+
+	commas     int  // delimiters outside quotes in the record
+	matched    int  // bytes of comma matched
+	inQuotes   bool // within a quoted field
+	afterQuote bool // the last byte closed a quoted field
+	escaped    bool // the last byte was a backslash
+	fieldStart bool // no byte of the field was read
+}
+
+// newRecordScanner returns a recordScanner of the records of r.
+func newRecordScanner(r *Reader) *recordScanner {
+	s := &recordScanner{
+		comma:       utf8.AppendRune(nil, r.Comma),
+		quotes:      r.FixedColumns == nil && !r.BackslashEscapes,
+		escapes:     r.BackslashEscapes,
+		lazyQuotes:  r.LazyQuotes,
+		trimLeading: r.trimLeading(),
+	}
+	s.start()
+	return s
+}
+
+// start resets s for a new record.
+func (s *recordScanner) start() {
+	s.commas, s.matched = 0, 0
+	s.inQuotes, s.afterQuote, s.escaped = false, false, false
+	s.fieldStart = true
+}
+
+// scan reads the next byte of the record and reports whether it ends the
+// record, in which case start must be called before the next record.
+func (s *recordScanner) scan(b byte) (end bool) {
+	switch {
+	case s.escaped && b != '\n':
+		s.escaped = false
+		return false
+	case s.escapes && b == '\\':
+		s.escaped = true
+		return false
+	case !s.quotes:
+	case s.inQuotes:
+		if b == '"' {
+			s.inQuotes = false
+			s.afterQuote = true
+		}
+		return false
+	case s.afterQuote && s.lazyQuotes && b != '"' && b != s.comma[0] && b != '\r' && b != '\n':
+		// a lazy quote within a quoted field
+		s.inQuotes = true
+		s.afterQuote = false
+		return false
+	case b == '"' && (s.fieldStart || s.afterQuote):
+		s.inQuotes = true
+		s.afterQuote = false
+		return false
+	}
+	s.afterQuote = false
+
+	if b == '\n' {
+		return true
+	}
+	if b != s.comma[s.matched] {
+		s.matched = 0
+	}
+	switch {
+	case b == s.comma[s.matched]:
+		s.matched++
+		if s.fieldStart = s.matched == len(s.comma); s.fieldStart {
+			s.matched = 0
+			s.commas++
+		}
+	case (b == ' ' || b == '\t' || b == '\r' || b == '\v' || b == '\f') && s.trimLeading:
+		// a quote may follow leading space
+	default:
+		s.fieldStart = false
+	}
+	return false
+}
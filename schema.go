@@ -0,0 +1,233 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// These are the errors that can be returned in Violation.Err
+var (
+	ErrMissingColumn = errors.New("missing column")
+	ErrNull          = errors.New("null value in non-nullable column")
+	ErrType          = errors.New("invalid value for column type")
+)
+
+// These are the rules reported in Violation.Rule
+const (
+	RuleParse    = "parse"    // the line could not be parsed
+	RuleOptional = "optional" // a column that is not Optional is missing
+	RuleNullable = "nullable" // a null value in a column that is not Nullable
+	RuleType     = "type"     // a value that cannot be converted to the column Type
+)
+
+// A Violation reports a value breaking a rule of a Schema.
+// The first line is 1.
+type Violation struct {
+	Line   int    // Line where the record started
+	Column string // Name of the column, or "" if the rule is not about a column
+	Value  string // The offending value
+	Rule   string // The rule that was broken
+	Err    error  // The actual error
+}
+
+func (v *Violation) Error() string {
+	if v.Rule == RuleParse {
+		return v.Err.Error()
+	}
+	if v.Column == "" {
+		return fmt.Sprintf("line %d: %s", v.Line, v.Err)
+	}
+	return fmt.Sprintf("line %d, column %q: %s", v.Line, v.Column, v.Err)
+}
+
+// Unwrap returns the underlying error.
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// A Column declares a column of a Schema.
+//
+// Values are converted according to Type: TypeInt to int64, TypeFloat to
+// float64, TypeBool to bool, TypeDate to time.Time using Layouts, or
+// DateLayouts if Layouts is empty, and any other type to string.
+//
+// Empty fields and fields matching one of the NullValues of the Reader are
+// null, which is converted to nil.  Null values are a violation unless
+// Nullable is true.
+//
+// If Optional is true, the column may be missing from the file, in which case
+// its value is nil.
+//
+// Validators are run on the values that are not null.
+type Column struct {
+	Name       string      // header of the column
+	Type       ColumnType  // type values are converted to
+	Layouts    []string    // layouts of TypeDate values
+	Nullable   bool        // allow null values
+	Optional   bool        // allow the column to be missing
+	Validators []Validator // validators of the values
+}
+
+// A Schema declares the columns of a CSV file.  Columns of the file that are
+// not in the Schema are ignored.
+type Schema struct {
+	Columns []Column
+}
+
+// A SchemaReader reads records from a Reader, validates them against a Schema
+// and converts their values to the column types.
+type SchemaReader struct {
+	Schema  *Schema
+	r       *Reader
+	columns []int // index in the record of each schema column, or -1
+}
+
+// NewSchemaReader returns a new SchemaReader that reads from r.
+func NewSchemaReader(r *Reader, schema *Schema) *SchemaReader {
+	return &SchemaReader{
+		Schema: schema,
+		r:      r,
+	}
+}
+
+// Read reads one record from the Reader.  The record maps the name of each
+// schema column to its converted value.  If the record breaks a rule of the
+// Schema, record is nil and violations lists every rule broken.
+//
+// The first call reads the headers.  If a column that is not Optional is
+// missing, Read returns a *Violation as err.  If the Reader has SkipLineOnErr
+// set, lines that cannot be parsed are reported as violations.
+func (s *SchemaReader) Read() (record map[string]interface{}, violations []*Violation, err error) {
+	if s.columns == nil {
+		if err = s.readHeaders(); err != nil {
+			return nil, nil, err
+		}
+	}
+	fields, _, err := s.r.read(true)
+	if err != nil {
+		if v, ok := err.(*Violation); ok {
+			return nil, []*Violation{v}, nil
+		}
+		if perr, ok := err.(*ParseError); ok && s.r.skipError(err) {
+			return nil, []*Violation{{Line: perr.Line, Rule: RuleParse, Err: perr}}, nil
+		}
+		return nil, nil, err
+	}
+	record = make(map[string]interface{}, len(s.columns))
+	for i, column := range s.Schema.Columns {
+		index := s.columns[i]
+		if index < 0 {
+			record[column.Name] = nil
+			continue
+		}
+		value := ""
+		if index < len(fields) {
+			value = fields[index]
+		}
+		converted, rule, err := s.convert(column, value)
+		if err != nil {
+			violations = append(violations, &Violation{
+				Line:   s.r.recordLine,
+				Column: column.Name,
+				Value:  value,
+				Rule:   rule,
+				Err:    err,
+			})
+			continue
+		}
+		if converted != nil {
+			if v := validateField(column.Validators, s.r.recordLine, column.Name, value); v != nil {
+				violations = append(violations, v)
+				continue
+			}
+		}
+		record[column.Name] = converted
+	}
+	if violations != nil {
+		return nil, violations, nil
+	}
+	return record, nil, nil
+}
+
+// ReadAll reads all the remaining records from the Reader.  It returns the
+// records that follow the Schema and a report of the violations of the
+// others.
+// A successful call returns err == nil, not err == EOF. Because ReadAll is
+// defined to read until EOF, it does not treat end of file as an error to be
+// reported.
+func (s *SchemaReader) ReadAll() (records []map[string]interface{}, report *ValidationReport, err error) {
+	report = new(ValidationReport)
+	for {
+		record, violations, err := s.Read()
+		if err == io.EOF {
+			return records, report, nil
+		}
+		if err != nil {
+			return nil, report, err
+		}
+		report.Add(violations...)
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+}
+
+// readHeaders reads the headers and finds the schema columns in them.
+func (s *SchemaReader) readHeaders() error {
+	if _, err := s.r.Headers(); err != nil {
+		return err
+	}
+	headers := s.r.outputHeaders()
+	s.columns = make([]int, len(s.Schema.Columns))
+	for i, column := range s.Schema.Columns {
+		s.columns[i] = -1
+		for index, header := range headers {
+			if header == column.Name {
+				s.columns[i] = index
+				break
+			}
+		}
+		if s.columns[i] < 0 && !column.Optional {
+			s.columns = nil
+			return &Violation{Line: 1, Column: column.Name, Rule: RuleOptional, Err: ErrMissingColumn}
+		}
+	}
+	return nil
+}
+
+// convert converts value to the type of column.  On failure it returns the
+// rule that was broken.
+func (s *SchemaReader) convert(column Column, value string) (converted interface{}, rule string, err error) {
+	if value == "" || s.r.isNull(value) {
+		if !column.Nullable {
+			return nil, RuleNullable, ErrNull
+		}
+		return nil, "", nil
+	}
+	switch column.Type {
+	case TypeInt:
+		converted, err = strconv.ParseInt(value, 10, 64)
+	case TypeFloat:
+		converted, err = strconv.ParseFloat(value, 64)
+	case TypeBool:
+		converted, err = strconv.ParseBool(value)
+	case TypeDate:
+		layouts := column.Layouts
+		if len(layouts) == 0 {
+			layouts = DateLayouts
+		}
+		converted, err = parseTime(value, layouts)
+	default:
+		converted = value
+	}
+	if err != nil {
+		return nil, RuleType, fmt.Errorf("%w %s: %q", ErrType, column.Type, value)
+	}
+	return converted, "", nil
+}
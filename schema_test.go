@@ -0,0 +1,99 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSchema = &Schema{
+	Columns: []Column{
+		{Name: "id", Type: TypeInt},
+		{Name: "price", Type: TypeFloat, Nullable: true},
+		{Name: "active", Type: TypeBool},
+		{Name: "joined", Type: TypeDate, Layouts: []string{"01/02/2006"}},
+		{Name: "name", Type: TypeString},
+		{Name: "note", Type: TypeString, Optional: true, Nullable: true},
+	},
+}
+
+func TestSchemaReader(t *testing.T) {
+	input := `name,id,price,active,joined,extra
+ann,1,2.5,true,06/01/2014,x
+bob,x,,yes,06/02/2014,y
+cat,2,NULL,false,06/03/2014,z
+dan,3,,,2014-06-04,w
+`
+	r := NewReader(strings.NewReader(input))
+	r.NullValues = []string{"NULL"}
+	records, report, err := NewSchemaReader(r, testSchema).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	violations := report.Violations
+	wantRecords := []map[string]interface{}{{
+		"id":     int64(1),
+		"price":  2.5,
+		"active": true,
+		"joined": time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC),
+		"name":   "ann",
+		"note":   nil,
+	}, {
+		"id":     int64(2),
+		"price":  nil,
+		"active": false,
+		"joined": time.Date(2014, 6, 3, 0, 0, 0, 0, time.UTC),
+		"name":   "cat",
+		"note":   nil,
+	}}
+	if !reflect.DeepEqual(records, wantRecords) {
+		t.Errorf("records=%v\nwant %v", records, wantRecords)
+	}
+	wantViolations := []string{
+		`line 3, column "id": invalid value for column type int: "x"`,
+		`line 3, column "active": invalid value for column type bool: "yes"`,
+		`line 5, column "active": null value in non-nullable column`,
+		`line 5, column "joined": invalid value for column type date: "2014-06-04"`,
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Error())
+	}
+	if !reflect.DeepEqual(got, wantViolations) {
+		t.Errorf("violations=%q\nwant %q", got, wantViolations)
+	}
+	if violations[0].Rule != RuleType || violations[0].Value != "x" || violations[2].Rule != RuleNullable {
+		t.Errorf("violations=%+v", violations)
+	}
+}
+
+func TestSchemaReaderParseErrors(t *testing.T) {
+	r := NewReader(strings.NewReader("id,name,active,joined,price\n1,a\"b,true,06/01/2014,\n2,b,false,06/02/2014,1"))
+	r.SkipLineOnErr = true
+	records, report, err := NewSchemaReader(r, testSchema).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	violations := report.Violations
+	if len(records) != 1 || records[0]["id"] != int64(2) {
+		t.Errorf("records=%v", records)
+	}
+	if len(violations) != 1 || violations[0].Rule != RuleParse || violations[0].Line != 2 {
+		t.Errorf("violations=%v", violations)
+	}
+}
+
+func TestSchemaReaderMissingColumn(t *testing.T) {
+	r := NewReader(strings.NewReader("id,price\n1,2"))
+	_, _, err := NewSchemaReader(r, testSchema).Read()
+	var v *Violation
+	if !errors.As(err, &v) || v.Column != "active" || !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("error %v, want missing column active", err)
+	}
+}
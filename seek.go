@@ -0,0 +1,146 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotSeekable is returned by SeekTo when the input of the Reader is not
+// an uncompressed io.ReadSeeker.
+var ErrNotSeekable = errors.New("input is not seekable")
+
+// SeekTo moves r to the first record starting at or after offset, in bytes
+// from the start of the input.  The input passed to NewReader must be an
+// uncompressed io.ReadSeeker.
+//
+// As quoted fields may contain newlines, a line is only taken as the start
+// of a record if it parses without error into the expected number of
+// fields: FieldsPerRecord, or the number of headers.  Lines that do not are
+// skipped.  If the headers have not been read yet, they are read first, so
+// that the map methods keep working.  Line numbers count from the record
+// SeekTo moved to.
+func (r *Reader) SeekTo(offset int64) error {
+	if err := r.checkSeekable(); err != nil {
+		return err
+	}
+	if r.headers == nil && offset > 0 {
+		if err := r.seekHeaders(); err != nil {
+			return err
+		}
+	}
+	if offset <= r.dataStart {
+		return r.reset(r.dataStart)
+	}
+	// Skip to the start of the next line, unless offset is one already.
+	if err := r.reset(offset - 1); err != nil {
+		return err
+	}
+	if err := r.skip('\n'); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return r.resync()
+}
+
+// checkSeekable returns ErrNotSeekable unless the input of r is an
+// uncompressed io.ReadSeeker.
+func (r *Reader) checkSeekable() error {
+	if r.seeker == nil || r.sources != nil {
+		return ErrNotSeekable
+	}
+	if err := r.reset(0); err != nil {
+		return err
+	}
+	br := getBufioReader(r.seeker)
+	defer putBufioReader(br)
+	d, _, err := decompress(br)
+	if err != nil {
+		return err
+	}
+	if d != io.Reader(br) {
+		return ErrNotSeekable
+	}
+	return nil
+}
+
+// seekHeaders reads the header row from the start of the input.
+func (r *Reader) seekHeaders() error {
+	if err := r.reset(0); err != nil {
+		return err
+	}
+	for {
+		record, err := r.parseRecord()
+		if record != nil {
+			r.headers = record
+			r.dataStart = r.offset
+			if r.FieldsPerRecord == 0 {
+				r.FieldsPerRecord, r.inferred = len(record), true
+			}
+			r.records++
+			r.returned++
+			return nil
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// resync moves r from the start of a line to the start of the first record
+// that parses into the expected number of fields.
+func (r *Reader) resync() error {
+	want := r.FieldsPerRecord
+	if want <= 0 {
+		want = len(r.headers)
+	}
+	keepComments := r.KeepComments
+	r.KeepComments = false
+	defer func() { r.KeepComments = keepComments }()
+
+	for {
+		start := r.offset
+		record, err := r.parseRecord()
+		if record == nil && err == nil {
+			continue // comment line
+		}
+		if err == io.EOF && record == nil {
+			return r.reset(start)
+		}
+		if (err == nil || err == io.EOF) && (want == 0 || len(record) == want) {
+			return r.reset(start)
+		}
+		if err := r.reset(start); err != nil {
+			return err
+		}
+		if err := r.skip('\n'); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// reset moves r to offset in its input, counting lines from there.
+func (r *Reader) reset(offset int64) error {
+	if _, err := r.seeker.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if r.r == nil {
+		r.r = getBufioReader(r.seeker)
+	} else {
+		r.r.Reset(r.seeker)
+	}
+	r.detect = false // the input is not compressed
+	r.offset = offset
+	r.headerRead = offset != 0
+	r.line = 0
+	r.column = 0
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var seekTests = []struct {
+	Name   string
+	Offset int64
+	Output []map[string]string
+}{
+	{
+		Name:   "Start",
+		Offset: 0,
+		Output: []map[string]string{{"id": "id", "note": "note"}, {"id": "1", "note": "a,b\n2,c"}, {"id": "3", "note": "d"}, {"id": "4", "note": "e"}},
+	},
+	{
+		Name:   "HeaderRow",
+		Offset: 3,
+		Output: []map[string]string{{"id": "1", "note": "a,b\n2,c"}, {"id": "3", "note": "d"}, {"id": "4", "note": "e"}},
+	},
+	{
+		Name:   "RecordStart",
+		Offset: 8,
+		Output: []map[string]string{{"id": "1", "note": "a,b\n2,c"}, {"id": "3", "note": "d"}, {"id": "4", "note": "e"}},
+	},
+	{
+		Name:   "InQuotedField",
+		Offset: 12,
+		Output: []map[string]string{{"id": "3", "note": "d"}, {"id": "4", "note": "e"}},
+	},
+	{
+		Name:   "AfterQuotedNewline",
+		Offset: 16,
+		Output: []map[string]string{{"id": "3", "note": "d"}, {"id": "4", "note": "e"}},
+	},
+	{
+		Name:   "LastRecord",
+		Offset: 25,
+		Output: []map[string]string{{"id": "4", "note": "e"}},
+	},
+	{
+		Name:   "End",
+		Offset: 100,
+	},
+}
+
+func TestSeekTo(t *testing.T) {
+	input := "id,note\r\n1,\"a,b\n2,c\"\n3,d\n4,e\n"
+	for _, tt := range seekTests {
+		r := NewReader(strings.NewReader(input))
+		if err := r.SeekTo(tt.Offset); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+			continue
+		}
+		out, err := r.ReadAllToMaps()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, tt.Output)
+		}
+	}
+}
+
+func TestSeekToAfterRead(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,2\n3,4\n"))
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := r.SeekTo(0); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	record, err := r.Read()
+	if err != nil || !reflect.DeepEqual(record, []string{"a", "b"}) {
+		t.Errorf("record=%q, err=%v", record, err)
+	}
+	if err := r.SeekTo(5); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	record, err = r.Read()
+	if err != nil || !reflect.DeepEqual(record, []string{"3", "4"}) {
+		t.Errorf("record=%q, err=%v", record, err)
+	}
+}
+
+func TestSeekToNotSeekable(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.WriteString(zw, "a,b\n1,2\n")
+	zw.Close()
+
+	inputs := []io.Reader{
+		io.MultiReader(strings.NewReader("a,b\n1,2\n")),
+		bytes.NewReader(buf.Bytes()),
+	}
+	for i, input := range inputs {
+		if err := NewReader(input).SeekTo(4); err != ErrNotSeekable {
+			t.Errorf("%d: error %v, want %v", i, err, ErrNotSeekable)
+		}
+	}
+}
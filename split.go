@@ -0,0 +1,136 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// A SplitWriter writes records to a sequence of chunks, such as files of
+// limited size, starting a new chunk every MaxRows records or before a record
+// that would make the chunk larger than MaxBytes.  Each chunk starts with the
+// header row when the Headers of Format are set.
+//
+// Format formats the records: its options, such as Comma, QuoteMode or
+// transforms, and its Headers can be set before the first write.  Its
+// AutoHeader is ignored.
+type SplitWriter struct {
+	Format   *Writer // formats the records (set by NewSplitWriter)
+	MaxRows  int     // records per chunk, not counting the header row; 0 for no limit
+	MaxBytes int     // bytes per chunk; 0 for no limit
+
+	create func(chunk int) (io.WriteCloser, error)
+	buf    bytes.Buffer // output of Format
+	header []byte       // formatted header row
+
+	chunks int            // chunks created
+	out    io.WriteCloser // current chunk
+	bw     *bufio.Writer  // buffers out
+	rows   int            // records in out
+	size   int            // bytes in out
+}
+
+// NewSplitWriter returns a new SplitWriter that calls create for each chunk,
+// numbered from 0, and closes it once it is full.
+func NewSplitWriter(create func(chunk int) (io.WriteCloser, error)) *SplitWriter {
+	s := &SplitWriter{create: create}
+	s.Format = NewWriter(&s.buf)
+	return s
+}
+
+// Write writes a single record to the current chunk, or to a new one.
+func (s *SplitWriter) Write(record []string) error {
+	data, err := s.format(func() error { return s.Format.Write(record) })
+	if err != nil {
+		return err
+	}
+	if s.out != nil && (s.MaxRows > 0 && s.rows >= s.MaxRows || s.MaxBytes > 0 && s.rows > 0 && s.size+len(data) > s.MaxBytes) {
+		if err := s.closeChunk(); err != nil {
+			return err
+		}
+	}
+	if s.out == nil {
+		if err := s.openChunk(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.bw.Write(data); err != nil {
+		return err
+	}
+	s.rows++
+	s.size += len(data)
+	return nil
+}
+
+// WriteMap writes a single record with the values of recordMap in the order
+// of the Headers of Format, like Writer.WriteMap.
+func (s *SplitWriter) WriteMap(recordMap map[string]string) error {
+	if s.Format.Headers == nil {
+		return ErrNoHeaders
+	}
+	record := make([]string, len(s.Format.Headers))
+	for i, header := range s.Format.Headers {
+		record[i] = recordMap[header]
+	}
+	return s.Write(record)
+}
+
+// Chunks returns the number of chunks created.
+func (s *SplitWriter) Chunks() int {
+	return s.chunks
+}
+
+// Close flushes and closes the current chunk.
+func (s *SplitWriter) Close() error {
+	if s.out == nil {
+		return nil
+	}
+	return s.closeChunk()
+}
+
+// format returns the output of Format for the write function.
+func (s *SplitWriter) format(write func() error) ([]byte, error) {
+	s.buf.Reset()
+	if err := write(); err != nil {
+		return nil, err
+	}
+	s.Format.Flush()
+	if err := s.Format.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(s.buf.Bytes()), nil
+}
+
+// openChunk creates a new chunk and writes the header row to it.
+func (s *SplitWriter) openChunk() error {
+	if s.header == nil && s.Format.Headers != nil {
+		header, err := s.format(func() error { return s.Format.writeHeader(s.Format.Headers) })
+		if err != nil {
+			return err
+		}
+		s.header = header
+	}
+	out, err := s.create(s.chunks)
+	if err != nil {
+		return err
+	}
+	s.chunks++
+	s.out, s.bw = out, bufio.NewWriter(out)
+	s.rows, s.size = 0, len(s.header)
+	_, err = s.bw.Write(s.header)
+	return err
+}
+
+// closeChunk flushes and closes the current chunk.
+func (s *SplitWriter) closeChunk() error {
+	err := s.bw.Flush()
+	if cerr := s.out.Close(); err == nil {
+		err = cerr
+	}
+	s.out, s.bw = nil, nil
+	return err
+}
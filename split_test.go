@@ -0,0 +1,70 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// chunkBuffer is a chunk of a SplitWriter.
+type chunkBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *chunkBuffer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSplitWriter(t *testing.T) {
+	tests := []struct {
+		Name     string
+		MaxRows  int
+		MaxBytes int
+		Chunks   []string
+	}{
+		{"Rows", 2, 0, []string{"id;n\n0;a\n1;b\n", "id;n\n2;c\n3;d\n", "id;n\n4;e\n"}},
+		{"Bytes", 0, 14, []string{"id;n\n0;a\n1;b\n", "id;n\n2;c\n3;d\n", "id;n\n4;e\n"}},
+		{"SmallBytes", 0, 5, []string{"id;n\n0;a\n", "id;n\n1;b\n", "id;n\n2;c\n", "id;n\n3;d\n", "id;n\n4;e\n"}},
+		{"NoLimit", 0, 0, []string{"id;n\n0;a\n1;b\n2;c\n3;d\n4;e\n"}},
+	}
+	for _, tt := range tests {
+		var chunks []*chunkBuffer
+		s := NewSplitWriter(func(chunk int) (io.WriteCloser, error) {
+			if chunk != len(chunks) {
+				t.Errorf("%s: chunk %d, want %d", tt.Name, chunk, len(chunks))
+			}
+			c := &chunkBuffer{}
+			chunks = append(chunks, c)
+			return c, nil
+		})
+		s.Format.Comma = ';'
+		s.Format.Headers = []string{"id", "n"}
+		s.MaxRows, s.MaxBytes = tt.MaxRows, tt.MaxBytes
+		for i, n := range []string{"a", "b", "c", "d", "e"} {
+			if err := s.WriteMap(map[string]string{"id": strconv.Itoa(i), "n": n}); err != nil {
+				t.Fatalf("%s: unexpected error %v", tt.Name, err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.Name, err)
+		}
+		var got []string
+		for _, c := range chunks {
+			if !c.closed {
+				t.Errorf("%s: chunk not closed", tt.Name)
+			}
+			got = append(got, c.String())
+		}
+		if !reflect.DeepEqual(got, tt.Chunks) || s.Chunks() != len(tt.Chunks) {
+			t.Errorf("%s: chunks=%q want %q", tt.Name, got, tt.Chunks)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultBatchSize is the number of rows inserted by each statement of an
+// SQLLoader whose BatchSize is 0.
+const DefaultBatchSize = 100
+
+// An SQLDB is the database an SQLLoader inserts into, such as a *sql.DB, a
+// *sql.Tx or a *sql.Conn.
+type SQLDB interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// A LoadError is returned by SQLLoader.Load for a record that could not be
+// inserted.
+type LoadError struct {
+	Line int   // Line where the record started
+	Err  error // The actual error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// An SQLLoader inserts the records of a Reader into the Table of DB with
+// prepared multi-row INSERT statements of BatchSize rows.
+//
+// The fields of a record are inserted into Columns, in order, or into the
+// columns named by the headers of the Reader if Columns is nil.  Fields
+// matching one of the NullValues of the Reader are inserted as NULL.  The
+// column names are quoted with QuoteName, and Table is used as is.
+//
+// Placeholder returns the placeholder of the nth argument of a statement,
+// starting at 1; "?" is used if it is nil, as for MySQL and SQLite, and
+// DollarPlaceholder can be used for PostgreSQL.  BatchSize times the number
+// of columns must not exceed the number of arguments the database allows
+// in a statement.
+type SQLLoader struct {
+	DB          SQLDB                    // database inserted into
+	Table       string                   // table inserted into
+	Columns     []string                 // columns of the fields; the headers if nil
+	BatchSize   int                      // rows inserted by each statement; DefaultBatchSize if 0
+	Placeholder func(n int) string       // placeholder of the nth argument; "?" if nil
+	QuoteName   func(name string) string // quotes column names; QuoteName if nil
+}
+
+// DollarPlaceholder returns the placeholder $n of the PostgreSQL drivers.
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// QuoteName quotes name as an SQL identifier, enclosing it in double quotes
+// and doubling the double quotes it contains.
+func QuoteName(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// loadRow is a record waiting to be inserted.
+type loadRow struct {
+	line int
+	args []any
+}
+
+// Load reads all the remaining records of r and inserts them, returning the
+// number of rows inserted.  As with ReadAllWithErrors, records with errors
+// are skipped and their errors returned in errs, along with a *LoadError
+// for each record the database rejected: when a statement fails, its rows
+// are inserted one by one to find them.  Databases aborting the current
+// transaction on errors, such as PostgreSQL, fail the rows following the
+// first rejected one.
+//
+// Errors preparing statements or reading the input, other than parse
+// errors, stop the loading and are returned in err.
+func (l *SQLLoader) Load(ctx context.Context, r *Reader) (n int, errs []error, err error) {
+	if _, err := r.Headers(); err != nil {
+		if err == io.EOF {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+	columns := l.Columns
+	if columns == nil {
+		columns = r.outputHeaders()
+	}
+	batchSize := l.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	stmts := make(map[int]*sql.Stmt) // statements by number of rows
+	defer func() {
+		for _, stmt := range stmts {
+			stmt.Close()
+		}
+	}()
+	// insert inserts rows, counting them in n and adding a *LoadError to
+	// errs for each rejected row.  Only the errors stopping the loading are
+	// returned.
+	var insert func(rows []loadRow) error
+	insert = func(rows []loadRow) error {
+		stmt := stmts[len(rows)]
+		if stmt == nil {
+			var err error
+			if stmt, err = l.DB.PrepareContext(ctx, l.insertQuery(columns, len(rows))); err != nil {
+				return err
+			}
+			stmts[len(rows)] = stmt
+		}
+		var args []any
+		for _, row := range rows {
+			args = append(args, row.args...)
+		}
+		_, err := stmt.ExecContext(ctx, args...)
+		switch {
+		case err == nil:
+			n += len(rows)
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case len(rows) == 1:
+			errs = append(errs, &LoadError{Line: rows[0].line, Err: err})
+		default:
+			for i := range rows {
+				if err := insert(rows[i : i+1]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	skipLine := r.SkipLineOnErr
+	r.SkipLineOnErr = true
+	defer func() { r.SkipLineOnErr = skipLine }()
+	rows := make([]loadRow, 0, batchSize)
+	for {
+		record, err := r.readNew()
+		if err == io.EOF {
+			break
+		}
+		switch err.(type) {
+		case nil:
+		case *ParseError, *Violation:
+			errs = append(errs, err)
+			continue
+		default:
+			return n, errs, err
+		}
+		if len(record) != len(columns) {
+			errs = append(errs, &LoadError{Line: r.recordLine, Err: ErrFieldCount})
+			continue
+		}
+		row := loadRow{line: r.recordLine, args: make([]any, len(record))}
+		for i, field := range record {
+			if !r.fieldIsNull(i, field) {
+				row.args[i] = field
+			}
+		}
+		rows = append(rows, row)
+		if len(rows) == batchSize {
+			if err := insert(rows); err != nil {
+				return n, errs, err
+			}
+			rows = rows[:0]
+		}
+	}
+	if len(rows) > 0 {
+		if err := insert(rows); err != nil {
+			return n, errs, err
+		}
+	}
+	return n, errs, nil
+}
+
+// insertQuery returns the INSERT statement of rows rows into columns.
+func (l *SQLLoader) insertQuery(columns []string, rows int) string {
+	quote := l.QuoteName
+	if quote == nil {
+		quote = QuoteName
+	}
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(l.Table)
+	b.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(quote(column))
+	}
+	b.WriteString(") VALUES ")
+	arg := 0
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			arg++
+			if l.Placeholder != nil {
+				b.WriteString(l.Placeholder(arg))
+			} else {
+				b.WriteByte('?')
+			}
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
@@ -0,0 +1,205 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testDriver is a database/sql driver recording the statements executed and
+// returning testQueryRows for queries.  Statements with an argument "reject"
+// fail.
+type testDriver struct {
+	mu    sync.Mutex
+	execs []string // queries and arguments executed successfully
+}
+
+type testConn struct{ d *testDriver }
+type testStmt struct {
+	d     *testDriver
+	query string
+}
+
+var errRejected = errors.New("rejected")
+
+func (d *testDriver) Open(name string) (driver.Conn, error) { return &testConn{d}, nil }
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{c.d, query}, nil
+}
+func (c *testConn) Close() error              { return nil }
+func (c *testConn) Begin() (driver.Tx, error) { return nil, errors.ErrUnsupported }
+func (s *testStmt) Close() error              { return nil }
+func (s *testStmt) NumInput() int             { return -1 }
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testRows{columns: testQueryColumns, rows: testQueryRows}, nil
+}
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	exec := s.query
+	for _, arg := range args {
+		if arg == "reject" {
+			return nil, errRejected
+		}
+		if arg == nil {
+			exec += " NULL"
+		} else {
+			exec += " " + arg.(string)
+		}
+	}
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs = append(s.d.execs, exec)
+	return driver.RowsAffected(len(args)), nil
+}
+
+// testQueryColumns and testQueryRows are the result of the queries of
+// testDriver.
+var (
+	testQueryColumns []string
+	testQueryRows    [][]driver.Value
+)
+
+type testRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.columns }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var testSQLDriver = new(testDriver)
+
+func init() {
+	sql.Register("bettercsvtest", testSQLDriver)
+}
+
+var sqlLoaderTests = []struct {
+	Name      string
+	Input     string
+	Loader    SQLLoader
+	Configure func(*Reader)
+	N         int
+	Errors    []error
+	Execs     []string
+}{
+	{
+		Name:   "Batches",
+		Input:  "a,b\n1,2\n3,4\n5,6\n",
+		Loader: SQLLoader{Table: "t", BatchSize: 2},
+		N:      3,
+		Execs: []string{
+			`INSERT INTO t ("a", "b") VALUES (?, ?), (?, ?) 1 2 3 4`,
+			`INSERT INTO t ("a", "b") VALUES (?, ?) 5 6`,
+		},
+	},
+	{
+		Name:   "Placeholder",
+		Input:  "a,b\n1,2\n3,4\n",
+		Loader: SQLLoader{Table: "t", Columns: []string{"x", `y"z`}, Placeholder: DollarPlaceholder},
+		N:      2,
+		Execs:  []string{`INSERT INTO t ("x", "y""z") VALUES ($1, $2), ($3, $4) 1 2 3 4`},
+	},
+	{
+		Name:      "Nulls",
+		Input:     "a,b\n1,NA\n",
+		Loader:    SQLLoader{Table: "t"},
+		Configure: func(r *Reader) { r.NullValues = []string{"NA"} },
+		N:         1,
+		Execs:     []string{`INSERT INTO t ("a", "b") VALUES (?, ?) 1 NULL`},
+	},
+	{
+		Name:   "Rejected",
+		Input:  "a,b\n1,2\n3,reject\n5,6\n7,8\n",
+		Loader: SQLLoader{Table: "t", BatchSize: 3},
+		N:      3,
+		Errors: []error{&LoadError{Line: 3, Err: errRejected}},
+		Execs: []string{
+			`INSERT INTO t ("a", "b") VALUES (?, ?) 1 2`,
+			`INSERT INTO t ("a", "b") VALUES (?, ?) 5 6`,
+			`INSERT INTO t ("a", "b") VALUES (?, ?) 7 8`,
+		},
+	},
+	{
+		Name:   "ParseErrors",
+		Input:  "a,b\n1,2\n3\n4,\"5\"x\n6,7\n",
+		Loader: SQLLoader{Table: "t"},
+		N:      2,
+		Errors: []error{
+			&ParseError{Line: 3, Column: 0, Err: ErrFieldCount},
+			&ParseError{Line: 4, Column: 5, Err: ErrQuote},
+		},
+		Execs: []string{`INSERT INTO t ("a", "b") VALUES (?, ?), (?, ?) 1 2 6 7`},
+	},
+	{
+		Name:   "Empty",
+		Input:  "",
+		Loader: SQLLoader{Table: "t"},
+	},
+}
+
+func TestSQLLoader(t *testing.T) {
+	db, err := sql.Open("bettercsvtest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, tt := range sqlLoaderTests {
+		testSQLDriver.execs = nil
+		r := NewReader(strings.NewReader(tt.Input))
+		if tt.Configure != nil {
+			tt.Configure(r)
+		}
+		l := tt.Loader
+		l.DB = db
+		n, errs, err := l.Load(context.Background(), r)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+			continue
+		}
+		if n != tt.N {
+			t.Errorf("%s: n=%d want %d", tt.Name, n, tt.N)
+		}
+		if !reflect.DeepEqual(errs, tt.Errors) {
+			t.Errorf("%s: errs=%v want %v", tt.Name, errs, tt.Errors)
+		}
+		if !reflect.DeepEqual(testSQLDriver.execs, tt.Execs) {
+			t.Errorf("%s: execs=%q want %q", tt.Name, testSQLDriver.execs, tt.Execs)
+		}
+		if r.SkipLineOnErr {
+			t.Errorf("%s: SkipLineOnErr left set", tt.Name)
+		}
+	}
+}
+
+func TestSQLLoaderCanceled(t *testing.T) {
+	db, err := sql.Open("bettercsvtest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l := &SQLLoader{DB: db, Table: "t"}
+	_, _, err = l.Load(ctx, NewReader(strings.NewReader("a\n1\n")))
+	if err != context.Canceled {
+		t.Errorf("error %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// WriteRows writes the rows of a database query to w, after a header row
+// of their column names unless a record was already written, and flushes
+// w.  NULL values are written as NullValue, times are formatted with
+// time.RFC3339Nano and other values as with WriteStruct.  Transforms are
+// looked up by column name.  The caller must close rows.
+func (w *Writer) WriteRows(rows *sql.Rows) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if !w.started {
+		if err := w.writeHeader(columns); err != nil {
+			return err
+		}
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	nulls := make([]bool, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			record[i], nulls[i] = "", false
+			switch value := value.(type) {
+			case nil:
+				record[i], nulls[i] = w.NullValue, true
+			case []byte:
+				record[i] = string(value)
+			default:
+				if record[i], err = formatValue(reflect.ValueOf(value)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := w.writeRecord(record, columns, nulls); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
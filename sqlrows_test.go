@@ -0,0 +1,46 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRows(t *testing.T) {
+	db, err := sql.Open("bettercsvtest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	testQueryColumns = []string{"id", "name", "score", "active", "at", "note"}
+	testQueryRows = [][]driver.Value{
+		{int64(1), "a,b", 1.5, true, time.Date(2014, 3, 1, 12, 0, 0, 123456000, time.UTC), []byte("x")},
+		{int64(2), "c", nil, false, nil, nil},
+	}
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.NullValue = `\N`
+	w.Transform("name", strings.ToUpper)
+	if err := w.WriteRows(rows); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := "id,name,score,active,at,note\n" +
+		"1,\"A,B\",1.5,true,2014-03-01T12:00:00.123456Z,x\n" +
+		"2,C,\\N,false,\\N,\\N\n"
+	if b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ColumnStats holds the statistics of a column collected by Stats.
+//
+// Min and Max are compared numerically if every non-null value of the column
+// is a number, in which case Numeric is true and Mean is their mean.
+// Otherwise they are compared as strings and Mean is 0.
+type ColumnStats struct {
+	Name      string  // header of the column
+	Count     int     // number of non-null values
+	NullCount int     // number of empty or null values
+	Distinct  int     // number of distinct non-null values
+	MaxLength int     // length in runes of the longest value
+	Min       string  // smallest non-null value
+	Max       string  // largest non-null value
+	Numeric   bool    // true if every non-null value is a number
+	Mean      float64 // mean of the values if Numeric
+}
+
+// A Stats collects per column statistics from a stream of records.  Empty
+// fields and fields matching one of the NullValues are counted as null.
+//
+// A Stats can be filled by setting it as the Stats of a Reader, so that it
+// collects while records are read, by calling CollectStats, or by calling Add.
+type Stats struct {
+	NullValues []string // values counted as null
+	headers    []string
+	columns    []*columnStats
+}
+
+// columnStats is the running state of a column.
+type columnStats struct {
+	ColumnStats
+	values         map[string]struct{}
+	sum            float64
+	minNum, maxNum float64
+	minNumStr      string
+	maxNumStr      string
+}
+
+// NewStats returns a new Stats for records with the given headers.
+func NewStats(headers ...string) *Stats {
+	return &Stats{headers: headers}
+}
+
+// Add adds the fields of record to the statistics.
+func (s *Stats) Add(record []string) {
+	for len(s.columns) < len(record) {
+		c := &columnStats{values: make(map[string]struct{})}
+		c.Numeric = true
+		if len(s.columns) < len(s.headers) {
+			c.Name = s.headers[len(s.columns)]
+		}
+		s.columns = append(s.columns, c)
+	}
+	for i, field := range record {
+		s.columns[i].add(field, s.isNull(field))
+	}
+}
+
+// Columns returns the statistics collected so far, one per column.
+func (s *Stats) Columns() []ColumnStats {
+	columns := make([]ColumnStats, len(s.columns))
+	for i, c := range s.columns {
+		columns[i] = c.ColumnStats
+		columns[i].Distinct = len(c.values)
+		if c.Count == 0 {
+			columns[i].Numeric = false
+		} else if c.Numeric {
+			columns[i].Min = c.minNumStr
+			columns[i].Max = c.maxNumStr
+			columns[i].Mean = c.sum / float64(c.Count)
+		}
+	}
+	return columns
+}
+
+// isNull reports whether field is counted as null.
+func (s *Stats) isNull(field string) bool {
+	if field == "" {
+		return true
+	}
+	for _, null := range s.NullValues {
+		if field == null {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds field to the statistics of the column.
+func (c *columnStats) add(field string, null bool) {
+	if null {
+		c.NullCount++
+		return
+	}
+	if c.Count == 0 || field < c.Min {
+		c.Min = field
+	}
+	if c.Count == 0 || field > c.Max {
+		c.Max = field
+	}
+	if n := utf8.RuneCountInString(field); n > c.MaxLength {
+		c.MaxLength = n
+	}
+	c.values[field] = struct{}{}
+	if c.Numeric {
+		if f, err := strconv.ParseFloat(field, 64); err != nil {
+			c.Numeric = false
+		} else {
+			if c.Count == 0 || f < c.minNum {
+				c.minNum, c.minNumStr = f, field
+			}
+			if c.Count == 0 || f > c.maxNum {
+				c.maxNum, c.maxNumStr = f, field
+			}
+			c.sum += f
+		}
+	}
+	c.Count++
+}
+
+// CollectStats reads all the remaining records from r and returns their
+// statistics.  The NullValues of r are counted as null.
+// A successful call returns err == nil, not err == EOF. Because CollectStats
+// is defined to read until EOF, it does not treat end of file as an error to
+// be reported.
+func (r *Reader) CollectStats() (stats *Stats, err error) {
+	if _, err = r.Headers(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	stats = NewStats(r.outputHeaders()...)
+	stats.NullValues = r.NullValues
+	for {
+		record, _, err := r.read(true)
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			if r.skipError(err) {
+				continue
+			}
+			return nil, err
+		}
+		stats.Add(record)
+	}
+}
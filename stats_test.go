@@ -0,0 +1,52 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const statsInput = `id,name,score
+1,ann,10
+2,bob,
+3,ann,2.5
+10,chloé,NULL
+`
+
+var wantStats = []ColumnStats{
+	{Name: "id", Count: 4, Distinct: 4, MaxLength: 2, Min: "1", Max: "10", Numeric: true, Mean: 4},
+	{Name: "name", Count: 4, Distinct: 3, MaxLength: 5, Min: "ann", Max: "chloé"},
+	{Name: "score", Count: 2, NullCount: 2, Distinct: 2, MaxLength: 3, Min: "2.5", Max: "10", Numeric: true, Mean: 6.25},
+}
+
+func TestCollectStats(t *testing.T) {
+	r := NewReader(strings.NewReader(statsInput))
+	r.NullValues = []string{"NULL"}
+	stats, err := r.CollectStats()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if columns := stats.Columns(); !reflect.DeepEqual(columns, wantStats) {
+		t.Errorf("columns=%+v\nwant %+v", columns, wantStats)
+	}
+}
+
+func TestReaderStats(t *testing.T) {
+	r := NewReader(strings.NewReader(statsInput))
+	r.Stats = NewStats()
+	r.Stats.NullValues = []string{"NULL"}
+	records, err := r.ReadAllToMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 5 {
+		t.Errorf("got %d records, want 5", len(records))
+	}
+	if columns := r.Stats.Columns(); !reflect.DeepEqual(columns, wantStats) {
+		t.Errorf("columns=%+v\nwant %+v", columns, wantStats)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// A Decoder reads and decodes records from an input stream, in the manner
+// of the Decoder of encoding/json.
+type Decoder struct {
+	r        *Reader
+	decoders map[reflect.Type]*structDecoder
+}
+
+// NewDecoder returns a new Decoder that reads from r with a Reader returned
+// by NewReader.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: NewReader(r)}
+}
+
+// Reader returns the Reader of dec, whose options can be set before the
+// first call to Decode.
+func (dec *Decoder) Reader() *Reader {
+	return dec.r
+}
+
+// DisallowUnknownFields causes Decode to return an error wrapping
+// ErrUnknownColumn when decoding into a struct a record with a column that
+// is not stored in any field.
+func (dec *Decoder) DisallowUnknownFields() {
+	dec.r.DisallowUnknownColumns = true
+}
+
+// InputOffset returns the number of bytes of input consumed, after
+// decompression.
+func (dec *Decoder) InputOffset() int64 {
+	return dec.r.offset
+}
+
+// Decode reads the next record and stores it in the value pointed to by v,
+// which is a pointer to a struct, as decoded by ReadAllInto, to a
+// map[string]string, as read by ReadToMap, or to a []string, as read by
+// Read.  Structs and maps are keyed by the header row, which is read by the
+// first call and not decoded, while slices of strings get the header row as
+// their first record.  At the end of the input, Decode returns io.EOF.
+func (dec *Decoder) Decode(v any) error {
+	switch v := v.(type) {
+	case *[]string:
+		record, err := dec.r.readNew()
+		if err != nil {
+			return err
+		}
+		*v = record
+		return nil
+	case *map[string]string:
+		for {
+			record, isHeader, err := dec.r.read(true)
+			if err != nil {
+				return err
+			}
+			recordMap, err := dec.r.recordToMap(record)
+			if err != nil {
+				return err
+			}
+			if !isHeader && (dec.r.keepMap(recordMap)) {
+				*v = recordMap
+				return nil
+			}
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w %T", ErrUnsupportedType, v)
+	}
+	d, err := dec.structDecoder(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	for {
+		record, isHeader, err := dec.r.read(true)
+		if err != nil {
+			return err
+		}
+		if isHeader {
+			continue
+		}
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+		return d.decode(record, rv.Elem(), dec.r.recordLine)
+	}
+}
+
+// structDecoder returns the structDecoder of the struct type t, reading the
+// header row if needed.
+func (dec *Decoder) structDecoder(t reflect.Type) (*structDecoder, error) {
+	if d := dec.decoders[t]; d != nil {
+		return d, nil
+	}
+	if _, err := dec.r.Headers(); err != nil {
+		return nil, err
+	}
+	d := dec.r.newStructDecoder(t)
+	if dec.r.DisallowUnknownColumns {
+		for i, field := range d.fields {
+			if field == nil {
+				return nil, &FieldError{Line: 1, Column: i, Name: d.headers[i], Err: ErrUnknownColumn}
+			}
+		}
+	}
+	if dec.decoders == nil {
+		dec.decoders = make(map[reflect.Type]*structDecoder)
+	}
+	dec.decoders[t] = d
+	return d, nil
+}
+
+// An Encoder writes records to an output stream, in the manner of the
+// Encoder of encoding/json.
+type Encoder struct {
+	w *Writer
+}
+
+// NewEncoder returns a new Encoder that writes to w with a Writer returned
+// by NewWriter, whose AutoHeader is set.
+func NewEncoder(w io.Writer) *Encoder {
+	writer := NewWriter(w)
+	writer.AutoHeader = true
+	return &Encoder{w: writer}
+}
+
+// Writer returns the Writer of enc, whose options, such as Headers, can be
+// set before the first call to Encode.
+func (enc *Encoder) Writer() *Writer {
+	return enc.w
+}
+
+// Encode writes v as a record and flushes it.  v is a struct or a pointer to
+// one, as written by WriteStruct, a map[string]string or map[string]*string,
+// as written by WriteMap and WriteNullableMap, or a []string, as written by
+// Write.  The header row is written before the first struct or map: the
+// Headers of the Writer, the StructHeaders of the struct, or else the sorted
+// keys of the map.
+func (enc *Encoder) Encode(v any) error {
+	var err error
+	switch v := v.(type) {
+	case []string:
+		err = enc.w.Write(v)
+	case map[string]string:
+		if enc.w.Headers == nil {
+			enc.w.Headers = sortedKeys(v)
+		}
+		err = enc.w.WriteMap(v)
+	case map[string]*string:
+		if enc.w.Headers == nil {
+			enc.w.Headers = sortedKeys(v)
+		}
+		err = enc.w.WriteNullableMap(v)
+	default:
+		if enc.w.Headers == nil {
+			// The maps encoded next follow the columns of the struct.
+			if enc.w.Headers, err = StructHeaders(v); err != nil {
+				return err
+			}
+		}
+		err = enc.w.WriteStruct(v)
+	}
+	if err != nil {
+		return err
+	}
+	enc.w.Flush()
+	return enc.w.Error()
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type streamPerson struct {
+	Name string `csv:"name"`
+	Age  int    `csv:"age"`
+}
+
+func TestDecoder(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("name,age\nann,31\nbob,x\ncid,7\ndee,9\n"))
+	var p streamPerson
+	if err := dec.Decode(&p); err != nil || p != (streamPerson{"ann", 31}) {
+		t.Errorf("Decode=%+v, %v", p, err)
+	}
+	var fe *FieldError
+	if err := dec.Decode(&p); !errors.As(err, &fe) || fe.Line != 3 || fe.Name != "age" {
+		t.Errorf("Decode error %v, want a FieldError for age on line 3", err)
+	}
+	var m map[string]string
+	if err := dec.Decode(&m); err != nil || !reflect.DeepEqual(m, map[string]string{"name": "cid", "age": "7"}) {
+		t.Errorf("Decode=%q, %v", m, err)
+	}
+	var record []string
+	if err := dec.Decode(&record); err != nil || !reflect.DeepEqual(record, []string{"dee", "9"}) {
+		t.Errorf("Decode=%q, %v", record, err)
+	}
+	if err := dec.Decode(&p); err != io.EOF {
+		t.Errorf("Decode error %v, want %v", err, io.EOF)
+	}
+	if dec.InputOffset() != 34 {
+		t.Errorf("InputOffset()=%d want 34", dec.InputOffset())
+	}
+}
+
+func TestDecoderSlices(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b\n1,2\n"))
+	var records [][]string
+	for {
+		var record []string
+		if err := dec.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if want := [][]string{{"a", "b"}, {"1", "2"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("name,age,extra\nann,31,x\n"))
+	dec.DisallowUnknownFields()
+	var p streamPerson
+	if err := dec.Decode(&p); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Decode error %v, want %v", err, ErrUnknownColumn)
+	}
+	var n int
+	if err := dec.Decode(&n); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Decode error %v, want %v", err, ErrUnsupportedType)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var b bytes.Buffer
+	enc := NewEncoder(&b)
+	values := []any{
+		streamPerson{"ann", 31},
+		&streamPerson{"bob", 42},
+		map[string]string{"name": "cid", "age": "7"},
+		[]string{"dee", "9"},
+	}
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			t.Fatalf("Encode(%v): unexpected error %v", v, err)
+		}
+	}
+	want := "name,age\nann,31\nbob,42\ncid,7\ndee,9\n"
+	if b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
+
+func TestEncoderMaps(t *testing.T) {
+	var b bytes.Buffer
+	enc := NewEncoder(&b)
+	enc.Writer().NullValue = "NULL"
+	enc.Encode(map[string]*string{"b": stringPtr("1"), "a": nil})
+	if want := "a,b\nNULL,1\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import "sync"
+
+// A SyncWriter is a Writer safe for concurrent use by multiple goroutines.
+// Each call holds a lock for its whole duration, so records written by
+// different goroutines are never interleaved and a header row written by
+// AutoHeader is always the first record.
+//
+// The exported fields of the Writer must not be changed once the SyncWriter
+// is in use.
+type SyncWriter struct {
+	mu sync.Mutex
+	w  *Writer
+}
+
+// NewSyncWriter returns a SyncWriter writing records with w.
+func NewSyncWriter(w *Writer) *SyncWriter {
+	return &SyncWriter{w: w}
+}
+
+// Write writes a single record like Writer.Write.
+func (s *SyncWriter) Write(record []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(record)
+}
+
+// WriteAll writes multiple records and then flushes like Writer.WriteAll.
+// The records are written together.
+func (s *SyncWriter) WriteAll(records [][]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.WriteAll(records)
+}
+
+// WriteMap writes a single record like Writer.WriteMap.
+func (s *SyncWriter) WriteMap(recordMap map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.WriteMap(recordMap)
+}
+
+// WriteNullableMap writes a single record like Writer.WriteNullableMap.
+func (s *SyncWriter) WriteNullableMap(recordMap map[string]*string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.WriteNullableMap(recordMap)
+}
+
+// WriteStruct writes a single record like Writer.WriteStruct.
+func (s *SyncWriter) WriteStruct(v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.WriteStruct(v)
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (s *SyncWriter) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous write or Flush.
+func (s *SyncWriter) Error() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Error()
+}
+
+// Close flushes and closes the Writer like Writer.Close.
+func (s *SyncWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Close()
+}
@@ -0,0 +1,49 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSyncWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Headers = []string{"producer", "n", "text"}
+	w.AutoHeader = true
+	s := NewSyncWriter(w)
+
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				s.WriteMap(map[string]string{"producer": strconv.Itoa(p), "n": strconv.Itoa(n), "text": "a, \"b\"\nc"})
+			}
+		}(p)
+	}
+	wg.Wait()
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	records, err := NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 401 || strings.Join(records[0], ",") != "producer,n,text" {
+		t.Fatalf("got %d records, first %q", len(records), records[0])
+	}
+	for _, record := range records[1:] {
+		if record[2] != "a, \"b\"\nc" {
+			t.Errorf("record=%q", record)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs are functions for the templates of a TemplateWriter, to be
+// added with the Funcs method of a template before it is parsed:
+//
+//	sqlString  quotes its argument as an SQL string literal: 'O''Brien'
+//	sqlName    quotes its argument as an SQL identifier, with QuoteName
+//	json       encodes its argument as JSON: "a \"b\""
+var TemplateFuncs = template.FuncMap{
+	"sqlString": func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" },
+	"sqlName":   QuoteName,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// A TemplateWriter writes each record by executing a text/template with
+// it, for output such as SQL INSERT scripts, configuration files or fixed
+// report formats.  The output is buffered and streamed as the records are
+// written; Flush writes what is left.
+//
+// The template of a record written by Write or WriteMap is executed with a
+// map[string]string of the fields keyed by their header, whose values are
+// used as {{.name}} or, for headers that are not identifiers,
+// {{index . "unit price"}}.  The template of a struct written by
+// WriteStruct is executed with the struct itself.
+type TemplateWriter struct {
+	Headers []string // headers of the records written by Write
+
+	tmpl *template.Template
+	w    *bufio.Writer
+}
+
+// NewTemplateWriter returns a new TemplateWriter that writes to w with
+// tmpl:
+//
+//	tmpl := template.Must(template.New("insert").Funcs(bettercsv.TemplateFuncs).Parse(
+//		"INSERT INTO users (name, email) VALUES ({{sqlString .name}}, {{sqlString .email}});\n"))
+//	w := bettercsv.NewTemplateWriter(os.Stdout, tmpl)
+func NewTemplateWriter(w io.Writer, tmpl *template.Template) *TemplateWriter {
+	return &TemplateWriter{
+		tmpl: tmpl,
+		w:    bufio.NewWriter(w),
+	}
+}
+
+// Write writes record, keyed by Headers.  Headers without a field are
+// empty, and fields without a header are ignored.
+func (t *TemplateWriter) Write(record []string) error {
+	if t.Headers == nil {
+		return ErrNoHeaders
+	}
+	recordMap := make(map[string]string, len(t.Headers))
+	for i, header := range t.Headers {
+		if i < len(record) {
+			recordMap[header] = record[i]
+		} else {
+			recordMap[header] = ""
+		}
+	}
+	return t.tmpl.Execute(t.w, recordMap)
+}
+
+// WriteMap writes recordMap.
+func (t *TemplateWriter) WriteMap(recordMap map[string]string) error {
+	return t.tmpl.Execute(t.w, recordMap)
+}
+
+// WriteStruct writes v, a struct or a pointer to one.
+func (t *TemplateWriter) WriteStruct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w %T", ErrUnsupportedType, v)
+	}
+	return t.tmpl.Execute(t.w, v)
+}
+
+// WriteReader writes all the remaining records of r, keyed by its headers,
+// skipping the header row and the records rejected by its FilterMap, and
+// flushes t.
+func (t *TemplateWriter) WriteReader(r *Reader) error {
+	for {
+		record, isHeader, err := r.read(true)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if isHeader {
+			continue
+		}
+		recordMap, err := r.recordToMap(record)
+		if err != nil {
+			return err
+		}
+		if !r.keepMap(recordMap) {
+			continue
+		}
+		if err := t.WriteMap(recordMap); err != nil {
+			return err
+		}
+	}
+	return t.Flush()
+}
+
+// Flush writes any buffered output to the underlying io.Writer.
+func (t *TemplateWriter) Flush() error {
+	return t.w.Flush()
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+var insertTemplate = template.Must(template.New("insert").Funcs(TemplateFuncs).Parse(
+	"INSERT INTO {{sqlName \"user list\"}} (name, note) VALUES ({{sqlString .name}}, {{sqlString (index . \"the note\")}});\n"))
+
+func TestTemplateWriter(t *testing.T) {
+	var b strings.Builder
+	w := NewTemplateWriter(&b, insertTemplate)
+	if err := w.Write([]string{"x"}); err != ErrNoHeaders {
+		t.Errorf("error %v, want %v", err, ErrNoHeaders)
+	}
+	w.Headers = []string{"name", "the note"}
+	if err := w.Write([]string{"O'Brien", "it's"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := w.WriteMap(map[string]string{"name": "ann"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.Flush()
+	want := `INSERT INTO "user list" (name, note) VALUES ('O''Brien', 'it''s');
+INSERT INTO "user list" (name, note) VALUES ('ann', '');
+`
+	if b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
+
+func TestTemplateWriterStruct(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+	var b strings.Builder
+	w := NewTemplateWriter(&b, template.Must(template.New("").Funcs(TemplateFuncs).Parse(
+		"{{.Name}}: {{json .Tags}}\n")))
+	if err := w.WriteStruct(&user{"ann", []string{"a", `"b"`}}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := w.WriteStruct("x"); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("error %v, want %v", err, ErrUnsupportedType)
+	}
+	w.Flush()
+	if want := "ann: [\"a\",\"\\\"b\\\"\"]\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
+
+func TestTemplateWriterReader(t *testing.T) {
+	r := NewReader(strings.NewReader("name,age\nann,30\nbob,17\ncat,45\n"))
+	r.FilterMap = func(record map[string]string) bool { return record["name"] != "bob" }
+	var b strings.Builder
+	w := NewTemplateWriter(&b, template.Must(template.New("").Parse("[{{.name}}]\nage = {{.age}}\n")))
+	if err := w.WriteReader(r); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := "[ann]\nage = 30\n[cat]\nage = 45\n"; b.String() != want {
+		t.Errorf("out=%q want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"io"
+	"strings"
+	"unicode"
+)
+
+// NewTSVReader returns a new Reader that reads tab-separated values from r.
+// Fields are separated by tabs and are not quoted: tabs, newlines, carriage
+// returns and backslashes within fields are escaped as \t, \n, \r and \\.
+// An empty line is a record of one empty field, as NewTSVWriter writes it.
+func NewTSVReader(r io.Reader) *Reader {
+	reader := NewReader(r)
+	reader.Comma = '\t'
+	reader.BackslashEscapes = true
+	return reader
+}
+
+// NewTSVWriter returns a new Writer that writes tab-separated values to w,
+// escaping fields as NewTSVReader expects.
+func NewTSVWriter(w io.Writer) *Writer {
+	writer := NewWriter(w)
+	writer.Comma = '\t'
+	writer.BackslashEscapes = true
+	return writer
+}
+
+// parseEscaped reads a line and splits it into fields at Comma, unescaping
+// them.  Other escape sequences, such as \N, are kept as they are, unless
+// the Reader reads CopyText.  Empty lines are read by parseRecord.
+func (r *Reader) parseEscaped() (fields []string, err error) {
+	var field strings.Builder
+	escaped, empty := false, true
+	copyText := r.copyFormat == CopyText
+	fieldRunes, null := 0, false // runes of the field and whether it is \N, for CopyText
+	column := 0                  // column of the last backslash, for errors
+	r.nulls = r.nulls[:0]
+	for {
+		var r1 rune
+		r1, err = r.readRune()
+		if err != nil || r1 == '\n' {
+			break
+		}
+		empty = false
+		fieldRunes++
+		switch {
+		case escaped && copyText:
+			null = r1 == 'N' && fieldRunes == 2
+			if r1 == r.Comma {
+				field.WriteRune(r1)
+			} else if escErr := r.unescapeCopy(&field, r1); escErr != nil {
+				r.column = column
+				err = r.error(escErr)
+				if r.SkipLineOnErr {
+					r.skipLine()
+				}
+				return nil, err
+			}
+			escaped = false
+		case escaped:
+			switch r1 {
+			case 't':
+				field.WriteRune('\t')
+			case 'n':
+				field.WriteRune('\n')
+			case 'r':
+				field.WriteRune('\r')
+			case '\\':
+				field.WriteRune('\\')
+			default:
+				if r1 != r.Comma {
+					field.WriteRune('\\')
+				}
+				field.WriteRune(r1)
+			}
+			escaped = false
+		case r1 == '\\':
+			escaped, column = true, r.column
+		case r1 == r.Comma:
+			fields = append(fields, r.endEscaped(&field, null && fieldRunes == 3))
+			field.Reset()
+			fieldRunes, null = 0, false
+		default:
+			field.WriteRune(r1)
+		}
+	}
+	if err != nil && err != io.EOF {
+		return nil, r.error(err)
+	}
+	if empty {
+		return nil, err
+	}
+	if escaped {
+		field.WriteRune('\\')
+	}
+	return append(fields, r.endEscaped(&field, null && fieldRunes == 2)), err
+}
+
+// endEscaped returns the escaped field read into field.  If null is true,
+// the field is the \N NULL value of CopyText, which is returned as is.
+func (r *Reader) endEscaped(field *strings.Builder, null bool) string {
+	if r.copyFormat != 0 {
+		r.nulls = append(r.nulls, null)
+		if null {
+			return `\N`
+		}
+	}
+	return r.trimField(field.String())
+}
+
+// trimField removes the white space of field ignored by the Reader.
+func (r *Reader) trimField(field string) string {
+	if r.trimLeading() {
+		field = strings.TrimLeftFunc(field, unicode.IsSpace)
+	}
+	if r.trimTrailing() {
+		field = strings.TrimRightFunc(field, unicode.IsSpace)
+	}
+	return field
+}
+
+// writeEscaped writes record with backslash escapes instead of quotes.  If
+// nulls is not nil, the fields for which it is true are written as
+// NullValue, without escapes.
+func (w *Writer) writeEscaped(record []string, nulls []bool) error {
+	w.started = true
+	for n, field := range record {
+		if n > 0 {
+			if _, err := w.w.WriteRune(w.Comma); err != nil {
+				return err
+			}
+		}
+		if nulls != nil && nulls[n] {
+			if _, err := w.w.WriteString(field); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, r1 := range field {
+			var err error
+			if escape := escapeCopy(r1); escape != "" && w.copyFormat == CopyText {
+				_, err = w.w.WriteString(escape)
+			} else {
+				switch r1 {
+				case '\\':
+					_, err = w.w.WriteString(`\\`)
+				case '\t':
+					_, err = w.w.WriteString(`\t`)
+				case '\n':
+					_, err = w.w.WriteString(`\n`)
+				case '\r':
+					_, err = w.w.WriteString(`\r`)
+				case w.Comma:
+					_, err = w.w.WriteString(`\` + string(r1))
+				default:
+					_, err = w.w.WriteRune(r1)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return w.endRecord()
+}
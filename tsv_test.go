@@ -0,0 +1,63 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTSV(t *testing.T) {
+	records := [][]string{
+		{"id", "note", "path"},
+		{"1", "say \"hi\"\tthen\nleave", `C:\temp`},
+		{"2", "", "a,b\r"},
+	}
+	var b bytes.Buffer
+	w := NewTSVWriter(&b)
+	if err := w.WriteAll(records); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	out := "id\tnote\tpath\n1\tsay \"hi\"\\tthen\\nleave\tC:\\\\temp\n2\t\ta,b\\r\n"
+	if b.String() != out {
+		t.Errorf("out=%q want %q", b.String(), out)
+	}
+
+	got, err := NewTSVReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("read back %q want %q", got, records)
+	}
+}
+
+func TestTSVEmptyLine(t *testing.T) {
+	records := [][]string{{"note"}, {""}, {"x"}}
+	var b bytes.Buffer
+	w := NewTSVWriter(&b)
+	if err := w.WriteAll(records); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got, err := NewTSVReader(&b).ReadAll()
+	if err != nil || !reflect.DeepEqual(got, records) {
+		t.Errorf("read back %q, %v, want %q", got, err, records)
+	}
+}
+
+func TestTSVReader(t *testing.T) {
+	r := NewTSVReader(strings.NewReader("a\tb\r\n\\N\t x\\q \nc\t\"d\\"))
+	r.TrimFields = true
+	r.NullValues = []string{`\N`}
+	records, err := r.ReadAllToNullableMaps()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 3 || records[1]["a"] != nil || *records[1]["b"] != `x\q` || *records[2]["b"] != `"d\` {
+		t.Errorf("records=%v", records)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxUploadSize is the largest file, in bytes, read by an Upload
+// whose MaxSize is 0.
+const DefaultMaxUploadSize = 32 << 20
+
+// These are the errors of Upload.Parse.
+var (
+	ErrNoUpload       = errors.New("no file uploaded")
+	ErrUploadTooLarge = errors.New("uploaded file too large")
+)
+
+// An Upload parses CSV files uploaded in multipart/form-data requests, such
+// as those of an HTML form with an <input type="file">.
+//
+// The file of the form field Field, or the first file of the request if
+// Field is "", is streamed from the request rather than stored, and is
+// decoded to UTF-8 from the charset of its Content-Type, as with
+// NewResponseReader.  Files longer than MaxSize bytes are rejected with
+// ErrUploadTooLarge, as are compressed files longer than MaxSize bytes once
+// decompressed.  If Configure is not nil, it is called with the
+// Reader of the file before the first record is read, to set options such
+// as Comma, Limit or validators.
+type Upload struct {
+	Field     string        // form field of the file; the first file if ""
+	MaxSize   int64         // largest file read; DefaultMaxUploadSize if 0
+	Configure func(*Reader) // configures the Reader of the file
+}
+
+// An UploadResult holds the records of an uploaded file.
+type UploadResult struct {
+	Filename string              // name of the file on the client
+	Headers  []string            // header row
+	Records  []map[string]string // records after the header row
+	Errors   []error             // errors of the records skipped
+}
+
+// Parse reads the file uploaded in req.  As with ReadAllToMapsWithErrors,
+// records with errors, such as parse errors and validation violations,
+// are skipped and their errors collected in the Errors of the result, so
+// that they can all be reported to the user at once.  Errors reading the
+// request, including ErrUploadTooLarge, stop the parsing and are returned
+// with the records read so far.
+func (u *Upload) Parse(req *http.Request) (*UploadResult, error) {
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, ErrNoUpload
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() == "" || u.Field != "" && part.FormName() != u.Field {
+			continue
+		}
+		return u.parse(part.FileName(), part, part.Header.Get("Content-Type"))
+	}
+}
+
+// parse reads the uploaded file named filename from body, with the
+// Content-Type contentType.
+func (u *Upload) parse(filename string, body io.Reader, contentType string) (*UploadResult, error) {
+	maxSize := u.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxUploadSize
+	}
+	// Compressed files are limited to MaxSize once decompressed too, so that
+	// a small upload cannot expand into a huge file.
+	br := bufio.NewReader(&uploadReader{r: body, n: maxSize})
+	src, _, err := decompress(br)
+	if err != nil {
+		return nil, err
+	}
+	if src != io.Reader(br) {
+		src = &uploadReader{r: src, n: maxSize}
+	}
+	decoded, _, err := decodeContentType(src, contentType)
+	if err != nil {
+		return nil, err
+	}
+	r := newPlainReader(decoded)
+	defer r.release()
+	if u.Configure != nil {
+		u.Configure(r)
+	}
+
+	result := &UploadResult{Filename: filename}
+	if result.Headers, err = r.Headers(); err != nil {
+		if err == io.EOF {
+			return result, nil
+		}
+		return result, err
+	}
+	skipLine := r.SkipLineOnErr
+	r.SkipLineOnErr = true
+	defer func() { r.SkipLineOnErr = skipLine }()
+	for {
+		record, isHeader, err := r.read(true)
+		if err == io.EOF {
+			return result, nil
+		}
+		switch err.(type) {
+		case nil:
+		case *ParseError, *Violation:
+			result.Errors = append(result.Errors, err)
+			continue
+		default:
+			return result, err
+		}
+		if isHeader {
+			continue
+		}
+		recordMap, err := r.recordToMap(record)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+		if r.keepMap(recordMap) {
+			result.Records = append(result.Records, recordMap)
+		}
+	}
+}
+
+// An uploadReader reads at most n bytes of an uploaded file, and fails with
+// ErrUploadTooLarge on longer files.
+type uploadReader struct {
+	r io.Reader
+	n int64 // bytes left
+}
+
+func (u *uploadReader) Read(p []byte) (int, error) {
+	if u.n < 0 {
+		return 0, ErrUploadTooLarge
+	}
+	// Read one more byte than allowed to tell files of n bytes from longer
+	// ones.
+	if int64(len(p)) > u.n+1 {
+		p = p[:u.n+1]
+	}
+	n, err := u.r.Read(p)
+	u.n -= int64(n)
+	if u.n < 0 {
+		return 0, ErrUploadTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,156 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// uploadFile is a part of a multipart/form-data request.
+type uploadFile struct {
+	Field, Filename, ContentType, Content string
+}
+
+var uploadTests = []struct {
+	Name   string
+	Upload Upload
+	Files  []uploadFile
+	Result *UploadResult
+	Error  error
+}{
+	{
+		Name:  "Records",
+		Files: []uploadFile{{Field: "file", Filename: "a.csv", Content: "\xef\xbb\xbfa,b\n1,2\n3\n4,5\n"}},
+		Result: &UploadResult{
+			Filename: "a.csv",
+			Headers:  []string{"a", "b"},
+			Records:  []map[string]string{{"a": "1", "b": "2"}, {"a": "4", "b": "5"}},
+			Errors:   []error{&ParseError{Line: 3, Column: 0, Err: ErrFieldCount}},
+		},
+	},
+	{
+		Name:   "Field",
+		Upload: Upload{Field: "data"},
+		Files: []uploadFile{
+			{Field: "name", Content: "ignored"},
+			{Field: "other", Filename: "b.csv", Content: "x\n1\n"},
+			{Field: "data", Filename: "c.csv", ContentType: "text/csv; charset=iso-8859-1", Content: "n\nJos\xe9\n"},
+		},
+		Result: &UploadResult{
+			Filename: "c.csv",
+			Headers:  []string{"n"},
+			Records:  []map[string]string{{"n": "José"}},
+		},
+	},
+	{
+		Name:   "Empty",
+		Files:  []uploadFile{{Field: "file", Filename: "empty.csv"}},
+		Result: &UploadResult{Filename: "empty.csv"},
+	},
+	{
+		Name:   "MaxSize",
+		Upload: Upload{MaxSize: 8},
+		Files:  []uploadFile{{Field: "file", Filename: "a.csv", Content: "a\n1\n2\n3\n4\n"}},
+		Error:  ErrUploadTooLarge,
+	},
+	{
+		Name:   "MaxSizeExact",
+		Upload: Upload{MaxSize: 6},
+		Files:  []uploadFile{{Field: "file", Filename: "a.csv", Content: "a\n1\n2\n"}},
+		Result: &UploadResult{
+			Filename: "a.csv",
+			Headers:  []string{"a"},
+			Records:  []map[string]string{{"a": "1"}, {"a": "2"}},
+		},
+	},
+	{
+		Name:   "Gzip",
+		Files:  []uploadFile{{Field: "file", Filename: "a.csv.gz", Content: gzipString("a\n1\n")}},
+		Result: &UploadResult{Filename: "a.csv.gz", Headers: []string{"a"}, Records: []map[string]string{{"a": "1"}}},
+	},
+	{
+		// 2 KB of gzip expanding to 1 MB.
+		Name:   "GzipBomb",
+		Upload: Upload{MaxSize: 64 << 10},
+		Files:  []uploadFile{{Field: "file", Filename: "a.csv.gz", Content: gzipString("a\n" + strings.Repeat("1\n", 1<<19))}},
+		Error:  ErrUploadTooLarge,
+	},
+	{
+		Name:   "NoUpload",
+		Upload: Upload{Field: "file"},
+		Files:  []uploadFile{{Field: "other", Filename: "a.csv", Content: "a\n"}},
+		Error:  ErrNoUpload,
+	},
+}
+
+func TestUpload(t *testing.T) {
+	for _, tt := range uploadTests {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for _, f := range tt.Files {
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", `form-data; name="`+f.Field+`"`)
+			if f.Filename != "" {
+				header.Set("Content-Disposition", `form-data; name="`+f.Field+`"; filename="`+f.Filename+`"`)
+			}
+			if f.ContentType != "" {
+				header.Set("Content-Type", f.ContentType)
+			}
+			part, _ := mw.CreatePart(header)
+			part.Write([]byte(f.Content))
+		}
+		mw.Close()
+		req := httptest.NewRequest("POST", "/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+
+		result, err := tt.Upload.Parse(req)
+		if tt.Error != nil {
+			if !errors.Is(err, tt.Error) {
+				t.Errorf("%s: error %v, want %v", tt.Name, err, tt.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if !reflect.DeepEqual(result, tt.Result) {
+			t.Errorf("%s: result=%+v want %+v", tt.Name, result, tt.Result)
+		}
+	}
+}
+
+func TestUploadRelease(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", "a.csv")
+	part.Write([]byte("a,b\n1,2\n"))
+	mw.Close()
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	// The file is too large for its header row to be read.
+	var r *Reader
+	u := &Upload{MaxSize: 2, Configure: func(reader *Reader) { r = reader }}
+	if _, err := u.Parse(req); !errors.Is(err, ErrUploadTooLarge) {
+		t.Fatalf("error %v, want ErrUploadTooLarge", err)
+	}
+	if r.r != nil {
+		t.Errorf("buffered reader not released after an error")
+	}
+}
+
+func TestUploadNotMultipart(t *testing.T) {
+	req := httptest.NewRequest("POST", "/upload", bytes.NewReader([]byte("a,b\n")))
+	req.Header.Set("Content-Type", "text/csv")
+	if _, err := new(Upload).Parse(req); err == nil {
+		t.Error("no error for a request that is not multipart")
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// These are the errors returned by the built-in validators
+var (
+	ErrRequired   = errors.New("empty value in required column")
+	ErrNoMatch    = errors.New("value does not match")
+	ErrNotAllowed = errors.New("value is not one of")
+	ErrOutOfRange = errors.New("value out of range")
+	ErrTooLong    = errors.New("value longer than")
+	ErrTooShort   = errors.New("value shorter than")
+	ErrDuplicate  = errors.New("duplicate value")
+)
+
+// These are the rules of the built-in validators
+const (
+	RuleRequired  = "required"
+	RuleRegexp    = "regexp"
+	RuleEnum      = "enum"
+	RuleRange     = "range"
+	RuleMinLength = "minlength"
+	RuleMaxLength = "maxlength"
+	RuleUnique    = "unique"
+)
+
+// A Validator checks the values of a column.  Validate returns an error
+// describing why value is invalid, which is reported in a Violation along
+// with the Rule.
+type Validator interface {
+	Rule() string
+	Validate(value string) error
+}
+
+// funcValidator is a Validator calling a function.
+type funcValidator struct {
+	rule string
+	fn   func(value string) error
+}
+
+func (v *funcValidator) Rule() string                { return v.rule }
+func (v *funcValidator) Validate(value string) error { return v.fn(value) }
+
+// NewValidator returns a Validator for rule that calls fn.
+func NewValidator(rule string, fn func(value string) error) Validator {
+	return &funcValidator{rule: rule, fn: fn}
+}
+
+// Required returns a Validator rejecting empty values and values made only of
+// white space.
+func Required() Validator {
+	return NewValidator(RuleRequired, func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return ErrRequired
+		}
+		return nil
+	})
+}
+
+// Regexp returns a Validator rejecting values that do not match re.
+func Regexp(re *regexp.Regexp) Validator {
+	return NewValidator(RuleRegexp, func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("%w %s: %q", ErrNoMatch, re, value)
+		}
+		return nil
+	})
+}
+
+// OneOf returns a Validator rejecting values that are not one of values.
+func OneOf(values ...string) Validator {
+	allowed := make(map[string]bool, len(values))
+	for _, value := range values {
+		allowed[value] = true
+	}
+	return NewValidator(RuleEnum, func(value string) error {
+		if !allowed[value] {
+			return fmt.Errorf("%w %q: %q", ErrNotAllowed, values, value)
+		}
+		return nil
+	})
+}
+
+// Range returns a Validator rejecting values that are not numbers between min
+// and max, inclusive.
+func Range(min, max float64) Validator {
+	return NewValidator(RuleRange, func(value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < min || f > max {
+			return fmt.Errorf("%w [%v, %v]: %q", ErrOutOfRange, min, max, value)
+		}
+		return nil
+	})
+}
+
+// MinLength returns a Validator rejecting values shorter than n runes.
+func MinLength(n int) Validator {
+	return NewValidator(RuleMinLength, func(value string) error {
+		if utf8.RuneCountInString(value) < n {
+			return fmt.Errorf("%w %d: %q", ErrTooShort, n, value)
+		}
+		return nil
+	})
+}
+
+// MaxLength returns a Validator rejecting values longer than n runes.
+func MaxLength(n int) Validator {
+	return NewValidator(RuleMaxLength, func(value string) error {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Errorf("%w %d: %q", ErrTooLong, n, value)
+		}
+		return nil
+	})
+}
+
+// Unique returns a Validator rejecting values it has already seen.  Because
+// it remembers the values, a new Unique validator must be used for each file.
+func Unique() Validator {
+	seen := make(map[string]bool)
+	return NewValidator(RuleUnique, func(value string) error {
+		if seen[value] {
+			return fmt.Errorf("%w: %q", ErrDuplicate, value)
+		}
+		seen[value] = true
+		return nil
+	})
+}
+
+// Validate registers validators for the named column.  Read returns a
+// *Violation for the first value rejected by a validator.  The header row is
+// not validated.
+func (r *Reader) Validate(name string, validators ...Validator) {
+	if r.validators == nil {
+		r.validators = make(map[string][]Validator)
+	}
+	r.validators[name] = append(r.validators[name], validators...)
+}
+
+// validate runs the registered validators on record.
+func (r *Reader) validate(record []string) error {
+	if r.validators == nil {
+		return nil
+	}
+	for index, field := range record {
+		if index >= len(r.headers) {
+			break
+		}
+		name := r.headers[index]
+		if v := validateField(r.validators[name], r.recordLine, name, field); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// validateField runs validators on value and returns a Violation for the
+// first one rejecting it.
+func validateField(validators []Validator, line int, name, value string) *Violation {
+	for _, validator := range validators {
+		if err := validator.Validate(value); err != nil {
+			return &Violation{
+				Line:   line,
+				Column: name,
+				Value:  value,
+				Rule:   validator.Rule(),
+				Err:    err,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var validatorTests = []struct {
+	Name      string
+	Validator Validator
+	Valid     []string
+	Invalid   []string
+}{
+	{"Required", Required(), []string{"a", " a "}, []string{"", "  "}},
+	{"Regexp", Regexp(regexp.MustCompile(`^\d{5}$`)), []string{"01234"}, []string{"1234", "abcde"}},
+	{"OneOf", OneOf("open", "closed"), []string{"open", "closed"}, []string{"Open", ""}},
+	{"Range", Range(0, 10), []string{"0", "5.5", "10"}, []string{"-1", "10.1", "x"}},
+	{"MinLength", MinLength(2), []string{"ab", "héé"}, []string{"", "é"}},
+	{"MaxLength", MaxLength(3), []string{"", "abc", "héé"}, []string{"abcd"}},
+	{"Unique", Unique(), []string{"a", "b"}, []string{"a", "b"}},
+}
+
+func TestValidators(t *testing.T) {
+	for _, tt := range validatorTests {
+		for _, value := range tt.Valid {
+			if err := tt.Validator.Validate(value); err != nil {
+				t.Errorf("%s: %q: unexpected error %v", tt.Name, value, err)
+			}
+		}
+		for _, value := range tt.Invalid {
+			if err := tt.Validator.Validate(value); err == nil {
+				t.Errorf("%s: %q: expected error", tt.Name, value)
+			}
+		}
+	}
+}
+
+func TestReaderValidate(t *testing.T) {
+	r := NewReader(strings.NewReader("id,status\n1,open\n2,bogus\n1,closed\n3,closed"))
+	r.Validate("id", Unique())
+	r.Validate("status", OneOf("open", "closed"))
+	records, errs := r.ReadAllWithErrors()
+	want := [][]string{{"id", "status"}, {"1", "open"}, {"3", "closed"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q want %q", records, want)
+	}
+	wantErrs := []string{
+		`line 3, column "status": value is not one of ["open" "closed"]: "bogus"`,
+		`line 4, column "id": duplicate value: "1"`,
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if !reflect.DeepEqual(got, wantErrs) {
+		t.Errorf("errors=%q want %q", got, wantErrs)
+	}
+}
+
+func TestSchemaValidators(t *testing.T) {
+	schema := &Schema{Columns: []Column{
+		{Name: "zip", Validators: []Validator{Regexp(regexp.MustCompile(`^\d{5}$`))}},
+		{Name: "age", Type: TypeInt, Nullable: true, Validators: []Validator{Range(0, 150)}},
+	}}
+	r := NewReader(strings.NewReader("zip,age\n01234,30\n1234,\n01234,200"))
+	records, report, err := NewSchemaReader(r, schema).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	violations := report.Violations
+	if len(records) != 1 {
+		t.Errorf("records=%v", records)
+	}
+	if len(violations) != 2 || violations[0].Rule != RuleRegexp || violations[0].Line != 3 ||
+		violations[1].Rule != RuleRange || violations[1].Column != "age" {
+		t.Errorf("violations=%v", violations)
+	}
+}
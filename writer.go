@@ -6,7 +6,10 @@ package bettercsv
 
 import (
 	"bufio"
+	"errors"
 	"io"
+	"log/slog"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -21,12 +24,104 @@ import (
 // Comma is the field delimiter.
 //
 // If UseCRLF is true, the Writer ends each record with \r\n instead of \n.
+//
+// Comment is the character starting the lines written by WriteComment.  It
+// must not be a letter, a digit, a quote, Comma, \r or \n.  Records whose
+// first field starts with Comment are quoted so that they are not read back
+// as comments.
+//
+// QuoteMode selects the fields enclosed in quotes.  With QuoteMinimal, the
+// default, only the fields that require quotes are quoted.  With QuoteNever,
+// Write returns ErrNeedsQuotes for records with fields containing Comma, a
+// quote or a newline.
+//
+// If ForceQuote is not nil, it is called with the index and value of each
+// field and the field is quoted whenever it returns true, whatever the
+// QuoteMode.  This keeps spreadsheets from stripping the leading zeros of
+// codes such as ZIP codes.
+//
+// If SanitizeFormulas is true, fields starting with '=', '+', '-', '@', a tab
+// or a carriage return are prefixed with a single quote and, unless the
+// QuoteMode is QuoteNever, enclosed in quotes so that spreadsheets do not
+// evaluate them as formulas, following the OWASP guidance on CSV injection.
+// Fields that are numbers, such as -1.5, are written unchanged.
+//
+// If NullValue is not empty, nil values written by WriteNullableMap and
+// WriteStruct are written as NullValue, without quotes, rather than as empty
+// fields.  Bulk loaders such as PostgreSQL's COPY read "\N" or an unquoted
+// token like NULL as null.  If BareEmptyNull is true, nil values are written
+// as unquoted empty fields instead, and empty strings as "", so that a Reader
+// with BareEmptyNull set reads them back apart.
+//
+// If FixedColumns is not nil, records are written as fixed-width lines
+// rather than delimited ones: each field is padded with FixedPad, or spaces,
+// to the width of its column and the quoting options are ignored.  Fields
+// longer than their column are truncated if TruncateFields is true and are
+// an error wrapping ErrFixedWidth otherwise.
+//
+// If BackslashEscapes is true, fields are never quoted: backslashes, tabs,
+// newlines, carriage returns and Comma are escaped with a backslash instead,
+// as in the files written by NewTSVWriter.
+//
+// Headers is the list of columns written by WriteMap, WriteNullableMap and
+// WriteStruct, in order.  Other map keys and struct fields are not written.
+//
+// If AutoHeader is true and the first record is written by WriteMap,
+// WriteNullableMap or WriteStruct, the header row is written before it:
+// Headers, or the StructHeaders of the struct for WriteStruct without Headers.
+//
+// If Logger is not nil, the fields changed by SanitizeFormulas and
+// TruncateFields are logged to it at debug level.
 type Writer struct {
-	Comma   rune // Field delimiter (set to ',' by NewWriter)
-	UseCRLF bool // True to use \r\n as the line terminator
-	w       *bufio.Writer
+	Comma            rune // Field delimiter (set to ',' by NewWriter)
+	UseCRLF          bool // True to use \r\n as the line terminator
+	Comment          rune // Comment character for WriteComment
+	QuoteMode        QuoteMode
+	ForceQuote       func(column int, field string) bool // Forces quotes around a field
+	SanitizeFormulas bool                                // True to neutralize fields spreadsheets evaluate as formulas
+	FixedColumns     []FixedColumn                       // Columns of fixed-width output
+	BackslashEscapes bool                                // True to escape fields instead of quoting them
+	FixedPad         rune                                // Padding of fixed-width fields (' ' if 0)
+	TruncateFields   bool                                // True to truncate fields longer than their fixed width
+	NullValue        string                              // Token written for nil values
+	BareEmptyNull    bool                                // True to write nil values as unquoted empty fields
+	Headers          []string                            // Columns written by WriteMap and WriteStruct
+	AutoHeader       bool                                // True to write the header row before the first record
+	Logger           *slog.Logger                        // Logs sanitized and truncated fields at debug level
+	w                *bufio.Writer
+	async            *asyncWriter   // set by NewAsyncWriter
+	compressor       io.WriteCloser // set by NewCompressedWriter
+	compressErr      error          // first error flushing or closing compressor
+
+	transforms      map[string][]TransformFunc // transforms by header
+	indexTransforms map[int][]TransformFunc    // transforms by column index
+	started         bool                       // a record has been written
+	copyFormat      CopyFormat                 // COPY format written, if not 0
+	http            *httpWriter                // set by HTTPExport.NewWriter
+	xlsx            *xlsxSheet                 // set by XLSXWriter.Sheet
+	html            *htmlTable                 // set by NewHTMLWriter
+	aligned         *alignedTable              // set by NewAlignedWriter
+	quotes          []bool                     // quoting of the record written by WriteRecord
 }
 
+// These are the errors that can be returned by the Writer
+var (
+	ErrNoHeaders   = errors.New("writer has no headers")
+	ErrNeedsQuotes = errors.New("field must be quoted")
+	ErrClosed      = errors.New("writer is closed")
+	ErrNoComment   = errors.New("writer has no valid comment character")
+)
+
+// A QuoteMode controls which fields a Writer encloses in quotes.
+type QuoteMode int
+
+const (
+	QuoteMinimal    QuoteMode = iota // quote fields only when required
+	QuoteAlways                      // quote every field
+	QuoteNonNumeric                  // quote every field that is not a number
+	QuoteNever                       // never quote; fields requiring quotes are an error
+)
+
 // NewWriter returns a new Writer that writes to w.
 func NewWriter(w io.Writer) *Writer {
 	return &Writer{
@@ -38,6 +133,95 @@ func NewWriter(w io.Writer) *Writer {
 // Writer writes a single CSV record to w along with any necessary quoting.
 // A record is a slice of strings with each string being one field.
 func (w *Writer) Write(record []string) (err error) {
+	return w.writeRecord(record, w.Headers, nil)
+}
+
+// Transform registers functions that are applied, in order, to each field of
+// the named column before it is quoted.  Names are looked up in Headers, or
+// in the StructHeaders of the struct written by WriteStruct without Headers.
+// Header rows and nil values are not transformed.
+func (w *Writer) Transform(name string, fns ...TransformFunc) {
+	if w.transforms == nil {
+		w.transforms = make(map[string][]TransformFunc)
+	}
+	w.transforms[name] = append(w.transforms[name], fns...)
+}
+
+// TransformIndex registers functions that are applied, in order, to each
+// field of the column at index before it is quoted.  The first column is 0.
+// Header rows and nil values are not transformed.
+func (w *Writer) TransformIndex(index int, fns ...TransformFunc) {
+	if w.indexTransforms == nil {
+		w.indexTransforms = make(map[int][]TransformFunc)
+	}
+	w.indexTransforms[index] = append(w.indexTransforms[index], fns...)
+}
+
+// writeHeader writes the header row, which is not transformed.
+func (w *Writer) writeHeader(headers []string) error {
+	if w.html != nil {
+		return w.writeHTMLRow(headers, true)
+	}
+	if w.aligned != nil {
+		return w.writeAligned(headers, true)
+	}
+	return w.write(headers, nil)
+}
+
+// writeRecord writes a copy of record with the registered transforms applied
+// to the fields that are not null.  The columns are named by headers.
+func (w *Writer) writeRecord(record, headers []string, nulls []bool) error {
+	if w.transforms != nil || w.indexTransforms != nil {
+		record = append([]string(nil), record...)
+		for index := range record {
+			if nulls != nil && nulls[index] {
+				continue
+			}
+			for _, fn := range w.indexTransforms[index] {
+				record[index] = fn(record[index])
+			}
+			if index < len(headers) {
+				for _, fn := range w.transforms[headers[index]] {
+					record[index] = fn(record[index])
+				}
+			}
+		}
+	}
+	if w.NullValue == "" && w.copyFormat != CopyCSV && !w.BareEmptyNull {
+		nulls = nil
+	}
+	return w.write(record, nulls)
+}
+
+// write writes record to w.  If nulls is not nil, the fields for which it is
+// true are written as NullValue and only quoted when they must be.
+func (w *Writer) write(record []string, nulls []bool) (err error) {
+	if w.async != nil && w.async.closed {
+		return ErrClosed
+	}
+	if w.xlsx != nil {
+		return w.writeCells(record, nulls)
+	}
+	if w.html != nil {
+		return w.writeHTMLRow(record, false)
+	}
+	if w.aligned != nil {
+		return w.writeAligned(record, false)
+	}
+	if w.FixedColumns != nil {
+		return w.writeFixed(record)
+	}
+	if w.BackslashEscapes {
+		return w.writeEscaped(record, nulls)
+	}
+	if w.QuoteMode == QuoteNever {
+		for n, field := range record {
+			if w.fieldContainsSpecial(field) || n == 0 && w.startsComment(field) {
+				return ErrNeedsQuotes
+			}
+		}
+	}
+	w.started = true
 	for n, field := range record {
 		if n > 0 {
 			if _, err = w.w.WriteRune(w.Comma); err != nil {
@@ -45,9 +229,30 @@ func (w *Writer) Write(record []string) (err error) {
 			}
 		}
 
+		var quoted bool
+		if nulls != nil && nulls[n] {
+			quoted = w.QuoteMode != QuoteNever && w.fieldContainsSpecial(field)
+		} else {
+			sanitized := w.SanitizeFormulas && isFormula(field)
+			if sanitized {
+				if w.Logger != nil {
+					w.Logger.Debug("sanitized formula", "column", n)
+				}
+				field = "'" + field
+			}
+			quoted = w.fieldQuoted(n, field) || (sanitized || n == 0 && w.startsComment(field)) && w.QuoteMode != QuoteNever
+			if n < len(w.quotes) {
+				quoted = w.quotes[n] || w.fieldContainsSpecial(field) || sanitized || n == 0 && w.startsComment(field)
+			}
+			if w.copyFormat == CopyCSV && (field == "" || field == copyEnd) {
+				// Quoted so that COPY does not read NULL or the end of the data.
+				quoted = true
+			}
+		}
+
 		// If we don't have to have a quoted field then just
 		// write out the field and continue to the next field.
-		if !w.fieldNeedsQuotes(field) {
+		if !quoted {
 			if _, err = w.w.WriteString(field); err != nil {
 				return
 			}
@@ -83,6 +288,64 @@ func (w *Writer) Write(record []string) (err error) {
 			return
 		}
 	}
+	return w.endRecord()
+}
+
+// endRecord terminates the record written.
+func (w *Writer) endRecord() error {
+	err := w.writeEOL()
+	if err == nil && w.async != nil {
+		err = w.async.record(w.w)
+	}
+	if err == nil && w.http != nil {
+		err = w.http.record(w)
+	}
+	return err
+}
+
+// WriteComment writes text as comment lines, one for each line of text,
+// starting with Comment and a space.  It returns ErrNoComment if Comment is
+// not set or could start a field.  Comments written before the first record
+// precede the header row written by AutoHeader.
+func (w *Writer) WriteComment(text string) error {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	return w.writeComments(lines, " ")
+}
+
+// writeComments writes each line as a comment line starting with Comment,
+// followed by sep and the line unless it is empty.
+func (w *Writer) writeComments(lines []string, sep string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	if w.Comment == 0 || w.xlsx != nil || w.html != nil || w.aligned != nil || w.Comment == '"' || w.Comment == w.Comma || w.Comment == '\r' || w.Comment == '\n' ||
+		unicode.IsLetter(w.Comment) || unicode.IsDigit(w.Comment) || !utf8.ValidRune(w.Comment) {
+		return ErrNoComment
+	}
+	if w.async != nil && w.async.closed {
+		return ErrClosed
+	}
+	for _, line := range lines {
+		if _, err := w.w.WriteRune(w.Comment); err != nil {
+			return err
+		}
+		if line != "" {
+			if _, err := w.w.WriteString(sep + line); err != nil {
+				return err
+			}
+		}
+		if err := w.writeEOL(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEOL terminates a line.
+func (w *Writer) writeEOL() (err error) {
 	if w.UseCRLF {
 		_, err = w.w.WriteString("\r\n")
 	} else {
@@ -94,15 +357,50 @@ func (w *Writer) Write(record []string) (err error) {
 // Flush writes any buffered data to the underlying io.Writer.
 // To check if an error occurred during the Flush, call Error.
 func (w *Writer) Flush() {
+	if w.async != nil {
+		if !w.async.closed {
+			w.async.flush(w.w)
+		}
+		return
+	}
+	if w.aligned != nil {
+		w.flushAligned()
+	}
 	w.w.Flush()
+	w.flushCompressor()
+	if w.http != nil {
+		w.http.flush()
+	}
 }
 
 // Error reports any error that has occurred during a previous Write or Flush.
 func (w *Writer) Error() error {
+	if w.async != nil {
+		return w.async.error()
+	}
 	_, err := w.w.Write(nil)
+	if err == nil {
+		err = w.compressErr
+	}
 	return err
 }
 
+// Close flushes the Writer and reports any error that has occurred.  For a
+// Writer returned by NewAsyncWriter, it waits for the buffered records to be
+// written and stops the background goroutine; for a Writer returned by
+// NewCompressedWriter, it ends the compressed stream; for a Writer returned
+// by NewHTMLWriter, it ends the table.  Close does not close the underlying
+// io.Writer.
+func (w *Writer) Close() error {
+	if w.async != nil {
+		return w.async.close(w.w)
+	}
+	w.endHTML()
+	w.Flush()
+	w.closeCompressor()
+	return w.Error()
+}
+
 // WriteAll writes multiple CSV records to w using Write and then calls Flush.
 func (w *Writer) WriteAll(records [][]string) (err error) {
 	for _, record := range records {
@@ -111,17 +409,114 @@ func (w *Writer) WriteAll(records [][]string) (err error) {
 			return err
 		}
 	}
-	return w.w.Flush()
+	w.Flush()
+	return w.Error()
+}
+
+// WriteMap writes a single CSV record to w with the values of recordMap in
+// the order of Headers.  Headers missing from recordMap are written as empty
+// fields and keys that are not in Headers are ignored.
+func (w *Writer) WriteMap(recordMap map[string]string) error {
+	if w.Headers == nil {
+		return ErrNoHeaders
+	}
+	if w.AutoHeader && !w.started {
+		if err := w.writeHeader(w.Headers); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(w.Headers))
+	for i, header := range w.Headers {
+		record[i] = recordMap[header]
+	}
+	return w.writeRecord(record, w.Headers, nil)
+}
+
+// WriteNullableMap writes a single CSV record to w with the values of
+// recordMap in the order of Headers, like WriteMap.  Nil values and headers
+// missing from recordMap are written as NullValue.
+func (w *Writer) WriteNullableMap(recordMap map[string]*string) error {
+	if w.Headers == nil {
+		return ErrNoHeaders
+	}
+	if w.AutoHeader && !w.started {
+		if err := w.writeHeader(w.Headers); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(w.Headers))
+	nulls := make([]bool, len(w.Headers))
+	for i, header := range w.Headers {
+		if value := recordMap[header]; value != nil {
+			record[i] = *value
+		} else {
+			record[i], nulls[i] = w.NullValue, true
+		}
+	}
+	return w.writeRecord(record, w.Headers, nulls)
+}
+
+// WriteAllMaps writes multiple CSV records to w using WriteMap and then calls
+// Flush.
+func (w *Writer) WriteAllMaps(records []map[string]string) (err error) {
+	for _, record := range records {
+		err = w.WriteMap(record)
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// fieldQuoted returns true if the field at column is enclosed in quotes
+// according to ForceQuote and the QuoteMode.
+func (w *Writer) fieldQuoted(column int, field string) bool {
+	if w.ForceQuote != nil && w.ForceQuote(column, field) {
+		return true
+	}
+	switch w.QuoteMode {
+	case QuoteAlways:
+		return true
+	case QuoteNonNumeric:
+		_, err := strconv.ParseFloat(field, 64)
+		return err != nil
+	case QuoteNever:
+		return false
+	}
+	return w.fieldNeedsQuotes(field)
+}
+
+// isFormula returns true if a spreadsheet may evaluate field as a formula.
+func isFormula(field string) bool {
+	if field == "" || strings.IndexByte("=+-@\t\r", field[0]) < 0 {
+		return false
+	}
+	_, err := strconv.ParseFloat(field, 64)
+	return err != nil
 }
 
 // fieldNeedsQuotes returns true if our field must be enclosed in quotes.
 // Empty fields, files with a Comma, fields with a quote or newline, and
 // fields which start with a space must be enclosed in quotes.
 func (w *Writer) fieldNeedsQuotes(field string) bool {
-	if len(field) == 0 || strings.IndexRune(field, w.Comma) >= 0 || strings.IndexAny(field, "\"\r\n") >= 0 {
+	if len(field) == 0 || w.fieldContainsSpecial(field) {
 		return true
 	}
 
 	r1, _ := utf8.DecodeRuneInString(field)
 	return unicode.IsSpace(r1)
 }
+
+// startsComment returns true if field starts with Comment, so that a record
+// starting with field would be read as a comment unless it is quoted.
+func (w *Writer) startsComment(field string) bool {
+	r1, _ := utf8.DecodeRuneInString(field)
+	return w.Comment != 0 && r1 == w.Comment
+}
+
+// fieldContainsSpecial returns true if field contains a Comma, a quote or a
+// newline, which cannot be written without quotes.
+func (w *Writer) fieldContainsSpecial(field string) bool {
+	return strings.IndexRune(field, w.Comma) >= 0 || strings.IndexAny(field, "\"\r\n") >= 0
+}
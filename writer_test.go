@@ -7,13 +7,15 @@ package bettercsv
 import (
 	"bytes"
 	"errors"
+	"strings"
 	"testing"
 )
 
 var writeTests = []struct {
-	Input   [][]string
-	Output  string
-	UseCRLF bool
+	Input     [][]string
+	Output    string
+	UseCRLF   bool
+	QuoteMode QuoteMode
 }{
 	{Input: [][]string{{"abc"}}, Output: "abc\n"},
 	{Input: [][]string{{"abc"}}, Output: "abc\r\n", UseCRLF: true},
@@ -28,6 +30,9 @@ var writeTests = []struct {
 	{Input: [][]string{{"abc\ndef"}}, Output: "\"abc\r\ndef\"\r\n", UseCRLF: true},
 	{Input: [][]string{{"abc\rdef"}}, Output: "\"abcdef\"\r\n", UseCRLF: true},
 	{Input: [][]string{{"abc\rdef"}}, Output: "\"abc\rdef\"\n", UseCRLF: false},
+	{Input: [][]string{{"abc", "1", ""}}, Output: `"abc","1",""` + "\n", QuoteMode: QuoteAlways},
+	{Input: [][]string{{"abc", "1.5", "-2", "", " 3"}}, Output: `"abc",1.5,-2,""," 3"` + "\n", QuoteMode: QuoteNonNumeric},
+	{Input: [][]string{{"abc", "", " def"}}, Output: "abc,, def\n", QuoteMode: QuoteNever},
 }
 
 func TestWrite(t *testing.T) {
@@ -35,6 +40,7 @@ func TestWrite(t *testing.T) {
 		b := &bytes.Buffer{}
 		f := NewWriter(b)
 		f.UseCRLF = tt.UseCRLF
+		f.QuoteMode = tt.QuoteMode
 		err := f.WriteAll(tt.Input)
 		if err != nil {
 			t.Errorf("Unexpected error: %s\n", err)
@@ -46,6 +52,224 @@ func TestWrite(t *testing.T) {
 	}
 }
 
+func TestWriteMap(t *testing.T) {
+	b := &bytes.Buffer{}
+	f := NewWriter(b)
+	if err := f.WriteMap(map[string]string{"a": "1"}); err != ErrNoHeaders {
+		t.Errorf("error %v, want ErrNoHeaders", err)
+	}
+	f.Headers = []string{"c", "a", "b"}
+	err := f.WriteAllMaps([]map[string]string{
+		{"a": "1", "b": "2", "c": "3"},
+		{"a": "4", "extra": "x"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s\n", err)
+	}
+	if out, want := b.String(), "3,1,2\n\"\",4,\"\"\n"; out != want {
+		t.Errorf("out=%q want %q", out, want)
+	}
+}
+
+func TestAutoHeader(t *testing.T) {
+	b := &bytes.Buffer{}
+	f := NewWriter(b)
+	f.Headers = []string{"b", "a"}
+	f.AutoHeader = true
+	f.WriteMap(map[string]string{"a": "1", "b": "2"})
+	f.WriteMap(map[string]string{"a": "3", "b": "4"})
+	f.Flush()
+	if out, want := b.String(), "b,a\n2,1\n4,3\n"; out != want {
+		t.Errorf("out=%q want %q", out, want)
+	}
+
+	b.Reset()
+	f = NewWriter(b)
+	f.AutoHeader = true
+	type row struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+	}
+	f.WriteStruct(row{1, "ann"})
+	f.WriteStruct(&row{2, "bob"})
+	f.Flush()
+	if out, want := b.String(), "id,name\n1,ann\n2,bob\n"; out != want {
+		t.Errorf("out=%q want %q", out, want)
+	}
+
+	b.Reset()
+	f = NewWriter(b)
+	f.Headers = []string{"a"}
+	f.AutoHeader = true
+	f.Write([]string{"A"})
+	f.WriteMap(map[string]string{"a": "1"})
+	f.Flush()
+	if out, want := b.String(), "A\n1\n"; out != want {
+		t.Errorf("out=%q want %q", out, want)
+	}
+}
+
+func TestQuoteNever(t *testing.T) {
+	b := &bytes.Buffer{}
+	f := NewWriter(b)
+	f.QuoteMode = QuoteNever
+	for _, field := range []string{"a,b", `a"b`, "a\nb"} {
+		if err := f.Write([]string{"ok", field}); err != ErrNeedsQuotes {
+			t.Errorf("%q: error %v, want ErrNeedsQuotes", field, err)
+		}
+	}
+	f.Flush()
+	if b.Len() != 0 {
+		t.Errorf("out=%q, want nothing written", b.String())
+	}
+}
+
+func TestForceQuote(t *testing.T) {
+	b := &bytes.Buffer{}
+	f := NewWriter(b)
+	f.ForceQuote = func(column int, field string) bool {
+		return column == 1 || strings.HasPrefix(field, "0")
+	}
+	f.WriteAll([][]string{
+		{"zip", "id", "name"},
+		{"02134", "17", "Jane"},
+		{"90210", "18", "0wen"},
+	})
+	out := "zip,\"id\",name\n\"02134\",\"17\",Jane\n90210,\"18\",\"0wen\"\n"
+	if b.String() != out {
+		t.Errorf("out=%q want %q", b.String(), out)
+	}
+}
+
+func TestSanitizeFormulas(t *testing.T) {
+	tests := []struct {
+		QuoteMode QuoteMode
+		Output    string
+	}{
+		{QuoteMinimal, `"'=SUM(A1:A2)","'+cmd","'@x",-1.5,"'-2+3",a=b,""` + "\n"},
+		{QuoteNever, `'=SUM(A1:A2),'+cmd,'@x,-1.5,'-2+3,a=b,` + "\n"},
+	}
+	for _, tt := range tests {
+		b := &bytes.Buffer{}
+		f := NewWriter(b)
+		f.SanitizeFormulas = true
+		f.QuoteMode = tt.QuoteMode
+		f.WriteAll([][]string{{"=SUM(A1:A2)", "+cmd", "@x", "-1.5", "-2+3", "a=b", ""}})
+		if b.String() != tt.Output {
+			t.Errorf("%d: out=%q want %q", tt.QuoteMode, b.String(), tt.Output)
+		}
+	}
+}
+
+func TestWriteNullableMap(t *testing.T) {
+	name, empty := "Jane", ""
+	records := []map[string]*string{
+		{"name": &name, "email": nil, "phone": &empty},
+		{"name": &name},
+	}
+	tests := []struct {
+		NullValue string
+		QuoteMode QuoteMode
+		Output    string
+	}{
+		{"", QuoteMinimal, "name,email,phone\nJane,\"\",\"\"\nJane,\"\",\"\"\n"},
+		{`\N`, QuoteMinimal, "name,email,phone\nJane,\\N,\"\"\nJane,\\N,\\N\n"},
+		{"NULL", QuoteAlways, "\"name\",\"email\",\"phone\"\n\"Jane\",NULL,\"\"\n\"Jane\",NULL,NULL\n"},
+	}
+	for _, tt := range tests {
+		b := &bytes.Buffer{}
+		f := NewWriter(b)
+		f.Headers = []string{"name", "email", "phone"}
+		f.AutoHeader = true
+		f.NullValue = tt.NullValue
+		f.QuoteMode = tt.QuoteMode
+		for _, record := range records {
+			if err := f.WriteNullableMap(record); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		}
+		f.Flush()
+		if b.String() != tt.Output {
+			t.Errorf("%q: out=%q want %q", tt.NullValue, b.String(), tt.Output)
+		}
+	}
+}
+
+func TestWriterTransform(t *testing.T) {
+	b := &bytes.Buffer{}
+	f := NewWriter(b)
+	f.Headers = []string{"name", "card", "note"}
+	f.AutoHeader = true
+	f.NullValue = "NULL"
+	f.Transform("card", func(s string) string { return "****" + s[len(s)-4:] })
+	f.Transform("name", strings.ToUpper)
+	f.TransformIndex(2, strings.TrimSpace)
+	card := "4111111111111111"
+	f.WriteMap(map[string]string{"name": "jane", "card": card, "note": " a, b "})
+	f.WriteNullableMap(map[string]*string{"card": &card})
+	record := []string{"bob", card, "c"}
+	f.Write(record)
+	f.Flush()
+	out := "name,card,note\nJANE,****1111,\"a, b\"\nNULL,****1111,NULL\nBOB,****1111,c\n"
+	if b.String() != out {
+		t.Errorf("out=%q want %q", b.String(), out)
+	}
+	if record[0] != "bob" {
+		t.Errorf("record modified to %q", record)
+	}
+}
+
+func TestWriterBareEmptyNull(t *testing.T) {
+	b := &bytes.Buffer{}
+	f := NewWriter(b)
+	f.Headers = []string{"a", "b", "c"}
+	f.BareEmptyNull = true
+	empty, x := "", "x"
+	f.WriteNullableMap(map[string]*string{"a": nil, "b": &empty, "c": &x})
+	f.Write([]string{"", "y", ""})
+	f.Flush()
+	out := ",\"\",x\n\"\",y,\"\"\n"
+	if b.String() != out {
+		t.Errorf("out=%q want %q", b.String(), out)
+	}
+
+	r := NewReader(strings.NewReader("a,b,c\n" + b.String()))
+	r.BareEmptyNull = true
+	records, err := r.ReadAllToNullableMaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[1]["a"] != nil || records[1]["b"] == nil || *records[1]["b"] != "" {
+		t.Errorf("read back %v", records[1])
+	}
+}
+
+func TestWriteComment(t *testing.T) {
+	b := &bytes.Buffer{}
+	f := NewWriter(b)
+	if err := f.WriteComment("x"); err != ErrNoComment {
+		t.Errorf("error %v, want ErrNoComment", err)
+	}
+	f.Comment = '#'
+	f.Headers = []string{"a", "b"}
+	f.AutoHeader = true
+	f.WriteComment("generated 2014-06-01 by exporter v2\r\n\nsource: db")
+	f.WriteMap(map[string]string{"a": "1", "b": "# not a comment"})
+	f.Write([]string{"# not a comment either", "2"})
+	f.Flush()
+	out := "# generated 2014-06-01 by exporter v2\n#\n# source: db\na,b\n1,# not a comment\n\"# not a comment either\",2\n"
+	if b.String() != out {
+		t.Errorf("out=%q want %q", b.String(), out)
+	}
+
+	r := NewReader(strings.NewReader(b.String()))
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil || len(records) != 3 || records[1][1] != "# not a comment" {
+		t.Errorf("read back %q, %v", records, err)
+	}
+}
+
 type errorWriter struct{}
 
 func (e errorWriter) Write(b []byte) (int, error) {
@@ -72,3 +296,17 @@ func TestError(t *testing.T) {
 		t.Error("Error should not be nil")
 	}
 }
+
+func TestWriterLogger(t *testing.T) {
+	var log strings.Builder
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	w.Logger = newTestLogger(&log)
+	w.SanitizeFormulas = true
+	w.Write([]string{"a", "=1+1"})
+	w.Flush()
+	want := "level=DEBUG msg=\"sanitized formula\" column=1\n"
+	if log.String() != want {
+		t.Errorf("log=%q want %q", log.String(), want)
+	}
+}
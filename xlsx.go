@@ -0,0 +1,525 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These are the errors of the XLSX workbooks.
+var (
+	ErrNoSheet   = errors.New("no such sheet")
+	ErrSheetName = errors.New("invalid sheet name")
+)
+
+// An XLSXFile is an Excel workbook opened with OpenXLSX, whose worksheets
+// can be read as CSV files.
+type XLSXFile struct {
+	zr         *zip.Reader
+	sheets     []string          // names of the sheets, in order
+	paths      map[string]string // path of the worksheet of each sheet
+	shared     []string          // shared strings
+	dateStyles []bool            // cell styles with a date format
+	date1904   bool              // dates count from 1904 rather than 1900
+}
+
+// xlsxText is a string of a workbook, as plain or rich text.
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t *xlsxText) String() string {
+	if t.R == nil {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// xlsxCell is a cell of a worksheet.
+type xlsxCell struct {
+	Ref   string   `xml:"r,attr"`
+	Type  string   `xml:"t,attr"`
+	Style int      `xml:"s,attr"`
+	V     string   `xml:"v"`
+	Is    xlsxText `xml:"is"`
+}
+
+// OpenXLSX opens the size bytes of ra as an Excel workbook in the Office
+// Open XML format, as saved by Excel as .xlsx.
+func OpenXLSX(ra io.ReaderAt, size int64) (*XLSXFile, error) {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	f := &XLSXFile{zr: zr, paths: make(map[string]string)}
+
+	var workbook struct {
+		Properties struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := f.decode("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := f.decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	f.date1904 = workbook.Properties.Date1904 == "1" || workbook.Properties.Date1904 == "true"
+	for _, sheet := range workbook.Sheets {
+		for _, rel := range rels.Rels {
+			if rel.ID == sheet.ID {
+				f.sheets = append(f.sheets, sheet.Name)
+				if strings.HasPrefix(rel.Target, "/") {
+					f.paths[sheet.Name] = rel.Target[1:]
+				} else {
+					f.paths[sheet.Name] = path.Join("xl", rel.Target)
+				}
+			}
+		}
+	}
+
+	if err := f.readSharedStrings(); err != nil {
+		return nil, err
+	}
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := f.decode("xl/styles.xml", &styles); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, xf := range styles.CellXfs {
+		code := ""
+		for _, numFmt := range styles.NumFmts {
+			if numFmt.ID == xf.NumFmtID {
+				code = numFmt.Code
+			}
+		}
+		f.dateStyles = append(f.dateStyles, isDateFormat(xf.NumFmtID, code))
+	}
+	return f, nil
+}
+
+// decode decodes the XML file name of the workbook into v.
+func (f *XLSXFile) decode(name string, v any) error {
+	rc, err := f.zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// readSharedStrings reads the shared strings of the workbook, if any.
+func (f *XLSXFile) readSharedStrings() error {
+	rc, err := f.zr.Open("xl/sharedStrings.xml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer rc.Close()
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "si" {
+			var text xlsxText
+			if err := d.DecodeElement(&text, &se); err != nil {
+				return err
+			}
+			f.shared = append(f.shared, text.String())
+		}
+	}
+}
+
+// isDateFormat reports whether the number format id, of format code if it
+// is not built in, formats dates or times.
+func isDateFormat(id int, code string) bool {
+	switch {
+	case 14 <= id && id <= 22, 45 <= id && id <= 47:
+		return true
+	case id < 164:
+		return false
+	}
+	// Elapsed times, such as [h]:mm, are durations rather than dates.
+	lower := strings.ToLower(code)
+	if strings.Contains(lower, "[h") || strings.Contains(lower, "[m") || strings.Contains(lower, "[s") {
+		return false
+	}
+	// Skip literal text and the bracketed colors and conditions.
+	var b strings.Builder
+	for i := 0; i < len(lower); i++ {
+		switch lower[i] {
+		case '"':
+			if j := strings.IndexByte(lower[i+1:], '"'); j >= 0 {
+				i += j + 1
+			}
+		case '[':
+			if j := strings.IndexByte(lower[i:], ']'); j >= 0 {
+				i += j
+			}
+		case '\\', '_', '*':
+			i++
+		default:
+			b.WriteByte(lower[i])
+		}
+	}
+	return strings.ContainsAny(b.String(), "ymdhs")
+}
+
+// Sheets returns the names of the sheets of f, in order.
+func (f *XLSXFile) Sheets() []string {
+	return f.sheets
+}
+
+// Reader returns a Reader of the named sheet, or of the first sheet if name
+// is "", whose records are the rows of the sheet.  Rows without cells are
+// skipped, and all the rows are as wide as the widest one, so that the first
+// row can be read as the header row.
+//
+// Cells are read as the text of their value: numbers as written in the
+// workbook, such as 1.5, booleans as true or false, and dates and times,
+// which are numbers with a date format, as 2006-01-02, 2006-01-02 15:04:05
+// or 15:04:05.  Formulas are read as their cached value.
+func (f *XLSXFile) Reader(name string) (*Reader, error) {
+	if name == "" && len(f.sheets) > 0 {
+		name = f.sheets[0]
+	}
+	records, err := f.readSheet(name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).WriteAll(records); err != nil {
+		return nil, err
+	}
+	return NewReader(&buf), nil
+}
+
+// readSheet returns the rows of the named sheet.
+func (f *XLSXFile) readSheet(name string) ([][]string, error) {
+	p, ok := f.paths[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrNoSheet, name)
+	}
+	rc, err := f.zr.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var (
+		records [][]string
+		record  []string
+		width   int
+	)
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Local != "c" {
+				continue
+			}
+			var c xlsxCell
+			if err := d.DecodeElement(&c, &tok); err != nil {
+				return nil, err
+			}
+			column := len(record)
+			if i := columnIndex(c.Ref); i >= 0 {
+				column = i
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+			record[column] = f.cellValue(&c)
+		case xml.EndElement:
+			if tok.Name.Local == "row" && record != nil {
+				records = append(records, record)
+				width = max(width, len(record))
+				record = nil
+			}
+		}
+	}
+	for i := range records {
+		for len(records[i]) < width {
+			records[i] = append(records[i], "")
+		}
+	}
+	return records, nil
+}
+
+// cellValue returns the text of the value of c.
+func (f *XLSXFile) cellValue(c *xlsxCell) string {
+	switch c.Type {
+	case "s":
+		if i, err := strconv.Atoi(c.V); err == nil && 0 <= i && i < len(f.shared) {
+			return f.shared[i]
+		}
+		return ""
+	case "inlineStr":
+		return c.Is.String()
+	case "b":
+		return strconv.FormatBool(c.V == "1")
+	case "", "n":
+		if c.V != "" && 0 <= c.Style && c.Style < len(f.dateStyles) && f.dateStyles[c.Style] {
+			if serial, err := strconv.ParseFloat(c.V, 64); err == nil {
+				return f.formatDate(serial)
+			}
+		}
+	}
+	return c.V
+}
+
+// formatDate formats the date of the serial number serial.
+func (f *XLSXFile) formatDate(serial float64) string {
+	// The 1900 date system counts the nonexistent February 29, 1900.
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if f.date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 60 {
+		base = base.AddDate(0, 0, 1)
+	}
+	days := math.Floor(serial)
+	ms := math.Round((serial - days) * 24 * 60 * 60 * 1000)
+	t := base.AddDate(0, 0, int(days)).Add(time.Duration(ms) * time.Millisecond)
+	switch {
+	case ms == 0:
+		return t.Format("2006-01-02")
+	case days == 0:
+		return t.Format("15:04:05")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// columnIndex returns the index of the column of the cell reference ref,
+// such as 2 for C5, or -1 if ref has no column.
+func columnIndex(ref string) int {
+	index := 0
+	i := 0
+	for ; i < len(ref) && 'A' <= ref[i] && ref[i] <= 'Z'; i++ {
+		index = index*26 + int(ref[i]-'A') + 1
+	}
+	if i == 0 {
+		return -1
+	}
+	return index - 1
+}
+
+// columnName returns the name of the column index, such as C for 2.
+func columnName(index int) string {
+	var name []byte
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = append([]byte{byte('A' + (index-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// An XLSXWriter writes an Excel workbook in the Office Open XML format, with
+// a worksheet for each sheet added with Sheet.
+type XLSXWriter struct {
+	zw      *zip.Writer
+	sheets  []string
+	current *Writer // Writer of the last sheet
+}
+
+// xlsxSheet is the worksheet written by a Writer returned by
+// XLSXWriter.Sheet.
+type xlsxSheet struct {
+	rows int // rows written
+}
+
+// NewXLSXWriter returns a new XLSXWriter that writes to w.
+func NewXLSXWriter(w io.Writer) *XLSXWriter {
+	return &XLSXWriter{zw: zip.NewWriter(w)}
+}
+
+// Sheet adds a sheet named name to the workbook and returns a Writer of its
+// rows, which can be used as any other Writer until the next call to Sheet
+// or Close; the quoting options do not apply, and WriteComment returns
+// ErrNoComment.  Fields that are numbers written exactly as Excel displays
+// them, such as 1.5 but not 1.50 or 007, are written as number cells, true
+// and false as boolean cells, and other fields as text cells.  Empty fields
+// and nil values are empty cells.
+//
+// Sheet names are 1 to 31 characters long, without any of []:*?/\, and
+// unique regardless of case; other names return an error wrapping
+// ErrSheetName.
+func (x *XLSXWriter) Sheet(name string) (*Writer, error) {
+	if name == "" || len([]rune(name)) > 31 || strings.ContainsAny(name, `[]:*?/\`) {
+		return nil, fmt.Errorf("%w %q", ErrSheetName, name)
+	}
+	for _, sheet := range x.sheets {
+		if strings.EqualFold(sheet, name) {
+			return nil, fmt.Errorf("%w %q", ErrSheetName, name)
+		}
+	}
+	if err := x.endSheet(); err != nil {
+		return nil, err
+	}
+	x.sheets = append(x.sheets, name)
+	fw, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+	if err != nil {
+		return nil, err
+	}
+	x.current = NewWriter(fw)
+	x.current.xlsx = new(xlsxSheet)
+	x.current.w.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x.current, nil
+}
+
+// endSheet ends the worksheet of the last sheet.
+func (x *XLSXWriter) endSheet() error {
+	if x.current == nil {
+		return nil
+	}
+	w := x.current
+	x.current = nil
+	w.w.WriteString("</sheetData></worksheet>")
+	w.Flush()
+	return w.Error()
+}
+
+// Close ends the last sheet and writes the workbook, with an empty sheet
+// named Sheet1 if no sheet was added.  It does not close the underlying
+// io.Writer.
+func (x *XLSXWriter) Close() error {
+	if x.sheets == nil {
+		if _, err := x.Sheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+
+	var types, workbook, rels strings.Builder
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, name := range x.sheets {
+		n := strconv.Itoa(i + 1)
+		types.WriteString(`<Override PartName="/xl/worksheets/sheet` + n + `.xml" ` +
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(name))
+		workbook.WriteString(`" sheetId="` + n + `" r:id="rId` + n + `"/>`)
+		rels.WriteString(`<Relationship Id="rId` + n + `" ` +
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" ` +
+			`Target="worksheets/sheet` + n + `.xml"/>`)
+	}
+	types.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", types.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+	}
+	for _, file := range files {
+		fw, err := x.zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, file.content); err != nil {
+			return err
+		}
+	}
+	return x.zw.Close()
+}
+
+// writeCells writes record as a row of the worksheet of w.  If nulls is not
+// nil, the fields for which it is true are empty cells.
+func (w *Writer) writeCells(record []string, nulls []bool) error {
+	w.started = true
+	w.xlsx.rows++
+	row := strconv.Itoa(w.xlsx.rows)
+	w.w.WriteString(`<row r="` + row + `">`)
+	for n, field := range record {
+		if field == "" || nulls != nil && nulls[n] {
+			continue
+		}
+		ref := columnName(n) + row
+		switch {
+		case isXLSXNumber(field):
+			w.w.WriteString(`<c r="` + ref + `"><v>` + field + `</v></c>`)
+		case field == "true", field == "false":
+			v := "0"
+			if field == "true" {
+				v = "1"
+			}
+			w.w.WriteString(`<c r="` + ref + `" t="b"><v>` + v + `</v></c>`)
+		default:
+			w.w.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(w.w, []byte(field))
+			w.w.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := w.w.WriteString("</row>")
+	return err
+}
+
+// isXLSXNumber reports whether field is a number that Excel stores and
+// displays unchanged: in its shortest decimal form, with at most the 15
+// significant digits of Excel.
+func isXLSXNumber(field string) bool {
+	f, err := strconv.ParseFloat(field, 64)
+	if err != nil || strconv.FormatFloat(f, 'f', -1, 64) != field {
+		return false
+	}
+	digits := strings.TrimLeft(strings.Replace(strings.TrimPrefix(field, "-"), ".", "", 1), "0")
+	return len(digits) <= 15
+}
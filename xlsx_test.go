@@ -0,0 +1,181 @@
+// Copyright 2014 John DeWyze. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bettercsv
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testWorkbook is a workbook as saved by Excel, with shared strings, rich
+// text, styles, formulas and gaps between cells.
+var testWorkbook = map[string]string{
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<workbookPr/><sheets><sheet name="Data" sheetId="1" r:id="rId1"/><sheet name="Notes &amp; more" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/notes.xml"/>
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`,
+	"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="5" uniqueCount="5">
+<si><t>name</t></si><si><t>joined</t></si><si><t>score</t></si><si><r><t>Ann </t></r><r><rPr><b/></rPr><t>Lee</t></r></si><si><t>active</t></si>
+</sst>`,
+	"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm"/><numFmt numFmtId="165" formatCode="&quot;day&quot;0.00"/></numFmts>
+<cellStyleXfs count="1"><xf numFmtId="14"/></cellStyleXfs>
+<cellXfs count="4"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/><xf numFmtId="165"/></cellXfs>
+</styleSheet>`,
+	"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>4</v></c></row>
+<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2" s="1"><v>41791</v></c><c r="C2" s="3"><v>1.5</v></c><c r="D2" t="b"><v>1</v></c></row>
+<row r="3"/>
+<row r="5"><c r="A5" t="inlineStr"><is><t>Bob, Jr.</t></is></c><c r="B5" s="2"><v>41791.75</v></c><c r="D5" t="b"><v>0</v></c></row>
+<row r="6"><c r="A6" t="str"><f>UPPER("cy")</f><v>CY</v></c><c r="C6"><f>C2*2</f><v>3</v></c></row>
+</sheetData></worksheet>`,
+	"xl/worksheets/notes.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="B1" t="inlineStr"><is><t>note</t></is></c></row>
+</sheetData></worksheet>`,
+}
+
+// zipFiles returns a zip archive of files.
+func zipFiles(files map[string]string) []byte {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, name := range sortedKeys(files) {
+		w, _ := zw.Create(name)
+		w.Write([]byte(files[name]))
+	}
+	zw.Close()
+	return b.Bytes()
+}
+
+func TestXLSXFile(t *testing.T) {
+	data := zipFiles(testWorkbook)
+	f, err := OpenXLSX(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := []string{"Data", "Notes & more"}; !reflect.DeepEqual(f.Sheets(), want) {
+		t.Errorf("sheets=%q want %q", f.Sheets(), want)
+	}
+
+	r, err := f.Reader("")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	out, err := r.ReadAllToMaps()
+	want := []map[string]string{
+		{"name": "name", "joined": "joined", "score": "score", "active": "active"},
+		{"name": "Ann Lee", "joined": "2014-06-01", "score": "1.5", "active": "true"},
+		{"name": "Bob, Jr.", "joined": "2014-06-01 18:00:00", "score": "", "active": "false"},
+		{"name": "CY", "joined": "", "score": "3", "active": ""},
+	}
+	if err != nil || !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%q err=%v want %q", out, err, want)
+	}
+
+	r, err = f.Reader("Notes & more")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if out, err := r.ReadAll(); err != nil || !reflect.DeepEqual(out, [][]string{{"", "note"}}) {
+		t.Errorf("notes=%q err=%v", out, err)
+	}
+
+	if _, err := f.Reader("Missing"); !errors.Is(err, ErrNoSheet) {
+		t.Errorf("error %v, want %v", err, ErrNoSheet)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var b bytes.Buffer
+	x := NewXLSXWriter(&b)
+	w, err := x.Sheet("People")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w.Headers = []string{"name", "age", "member", "note"}
+	w.AutoHeader = true
+	w.NullValue = "NULL"
+	w.WriteNullableMap(map[string]*string{"name": stringPtr("Ann <&> \"Lee\"\n"), "age": stringPtr("41"), "member": stringPtr("true"), "note": null})
+	w.WriteNullableMap(map[string]*string{"name": stringPtr("007"), "age": stringPtr("1.50"), "member": stringPtr("no"), "note": stringPtr("-2.5")})
+	if err := w.WriteComment("x"); err != ErrNoComment {
+		t.Errorf("WriteComment: error %v, want %v", err, ErrNoComment)
+	}
+	w, err = x.Sheet("Empty")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, name := range []string{"people", "", "a/b", "0123456789012345678901234567890123"} {
+		if _, err := x.Sheet(name); !errors.Is(err, ErrSheetName) {
+			t.Errorf("sheet %q: error %v, want %v", name, err, ErrSheetName)
+		}
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	f, err := OpenXLSX(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := []string{"People", "Empty"}; !reflect.DeepEqual(f.Sheets(), want) {
+		t.Errorf("sheets=%q want %q", f.Sheets(), want)
+	}
+	records, err := f.readSheet("People")
+	want := [][]string{
+		{"name", "age", "member", "note"},
+		{"Ann <&> \"Lee\"\n", "41", "true", ""},
+		{"007", "1.50", "no", "-2.5"},
+	}
+	if err != nil || !reflect.DeepEqual(records, want) {
+		t.Errorf("records=%q err=%v want %q", records, err, want)
+	}
+	var missing []string
+	sheet, _ := f.zr.Open("xl/worksheets/sheet1.xml")
+	content := new(bytes.Buffer)
+	content.ReadFrom(sheet)
+	for _, cell := range []string{`<c r="B2"><v>41</v></c>`, `<c r="C2" t="b"><v>1</v></c>`, `<c r="B3" t="inlineStr">`, `<c r="D3"><v>-2.5</v></c>`} {
+		if !bytes.Contains(content.Bytes(), []byte(cell)) {
+			missing = append(missing, cell)
+		}
+	}
+	if missing != nil {
+		t.Errorf("cells %q missing from %s", missing, content)
+	}
+	if records, err := f.readSheet("Empty"); err != nil || records != nil {
+		t.Errorf("empty sheet=%q err=%v", records, err)
+	}
+}
+
+func TestIsDateFormat(t *testing.T) {
+	tests := []struct {
+		ID   int
+		Code string
+		Date bool
+	}{
+		{0, "", false},
+		{14, "", true},
+		{2, "", false},
+		{164, "yyyy-mm-dd", true},
+		{164, "[h]:mm", false},
+		{164, `0.00" days"`, false},
+		{164, `[Red]#,##0`, false},
+		{164, `hh:mm AM/PM`, true},
+	}
+	for _, tt := range tests {
+		if got := isDateFormat(tt.ID, tt.Code); got != tt.Date {
+			t.Errorf("%d %q: %v, want %v", tt.ID, tt.Code, got, tt.Date)
+		}
+	}
+}